@@ -22,6 +22,7 @@ require (
 	github.com/tektoncd/pipeline v0.28.0
 	github.com/tektoncd/triggers v0.16.0
 	github.com/zalando/go-keyring v0.1.1
+	go.uber.org/zap v1.19.0
 	gopkg.in/AlecAivazis/survey.v1 v1.8.8
 	k8s.io/api v0.21.4
 	k8s.io/apiextensions-apiserver v0.21.4
@@ -30,6 +31,7 @@ require (
 	k8s.io/klog v1.0.0
 	k8s.io/kubectl v0.21.0
 	knative.dev/pkg v0.0.0-20210827184538-2bd91f75571c
+	sigs.k8s.io/kustomize/api v0.8.8
 	sigs.k8s.io/yaml v1.2.0
 )
 
@@ -3,14 +3,14 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/zalando/go-keyring"
 
-	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/openshift/odo/pkg/log"
 	"github.com/spf13/cobra"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -22,8 +22,11 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines"
 	"github.com/redhat-developer/kam/pkg/pipelines/accesstoken"
 	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/imagerepo"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 )
 
 const (
@@ -89,14 +92,6 @@ func (io *BootstrapParameters) Complete(name string, cmd *cobra.Command, args []
 		return err
 	}
 
-	if io.PrivateRepoDriver != "" {
-		host, err := accesstoken.HostFromURL(io.GitOpsRepoURL)
-		if err != nil {
-			return err
-		}
-		identifier := factory.NewDriverIdentifier(factory.Mapping(host, io.PrivateRepoDriver))
-		factory.DefaultIdentifier = identifier
-	}
 	if err := checkBootstrapDependencies(io, client, log.NewStatus(os.Stdout)); err != nil {
 		return err
 	}
@@ -156,12 +151,10 @@ func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client, cm
 	io.GitOpsRepoURL = utility.AddGitSuffixIfNecessary(io.GitOpsRepoURL)
 	if !isKnownDriver(io.GitOpsRepoURL) {
 		io.PrivateRepoDriver = ui.SelectPrivateRepoDriver()
-		host, err := accesstoken.HostFromURL(io.GitOpsRepoURL)
-		if err != nil {
-			return fmt.Errorf("failed to parse the gitops url: %w", err)
-		}
-		identifier := factory.NewDriverIdentifier(factory.Mapping(host, io.PrivateRepoDriver))
-		factory.DefaultIdentifier = identifier
+	}
+	drivers, err := io.DriverResolver()
+	if err != nil {
+		return fmt.Errorf("failed to parse the gitops url: %w", err)
 	}
 	if io.ImageRepo != "" {
 		isInternalRegistry, _, err := imagerepo.ValidateImageRepo(io.ImageRepo)
@@ -189,16 +182,34 @@ func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client, cm
 		io.ServiceRepoURL = ui.EnterServiceRepoURL()
 	}
 	io.ServiceRepoURL = utility.AddGitSuffixIfNecessary(io.ServiceRepoURL)
+	if host, err := accesstoken.HostFromURL(io.ServiceRepoURL); err == nil {
+		if _, err := drivers.Identify(host); err != nil {
+			if io.GitDrivers == nil {
+				io.GitDrivers = map[string]string{}
+			}
+			io.GitDrivers[host] = ui.SelectPrivateRepoDriver()
+			drivers, err = io.DriverResolver()
+			if err != nil {
+				return fmt.Errorf("failed to parse the service url: %w", err)
+			}
+		}
+	}
 	if promptForAll {
 		io.ServiceWebhookSecret = ui.EnterGitWebhookSecret(io.ServiceRepoURL)
 	}
+	if io.SSHPrivateKeyFilename == "" && io.hasSSHRepoURL() {
+		io.SSHPrivateKeyFilename = ui.EnterSSHPrivateKey()
+	}
+	if io.SSHKnownHostsFilename == "" && io.hasSSHRepoURL() {
+		io.SSHKnownHostsFilename = ui.EnterSSHKnownHosts()
+	}
 	secret, err := accesstoken.GetAccessToken(io.ServiceRepoURL)
 	if err != nil && err != keyring.ErrNotFound {
 		return err
 	}
 	if secret == "" { // We must prompt for the token
 		if io.GitHostAccessToken == "" {
-			io.GitHostAccessToken = ui.EnterGitHostAccessToken(io.ServiceRepoURL)
+			io.GitHostAccessToken = ui.EnterGitHostAccessToken(io.ServiceRepoURL, drivers)
 		}
 		if !cmd.Flag("save-token-keyring").Changed {
 			io.SaveTokenKeyRing = ui.UseKeyringRingSvc()
@@ -236,7 +247,7 @@ func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client, cm
 }
 
 func repoFromURL(raw string) (string, error) {
-	u, err := url.Parse(raw)
+	u, err := giturl.Parse(raw)
 	if err != nil {
 		return "", err
 	}
@@ -246,7 +257,11 @@ func repoFromURL(raw string) (string, error) {
 
 func setAccessToken(io *BootstrapParameters) error {
 	if io.GitHostAccessToken != "" {
-		err := ui.ValidateAccessToken(io.GitHostAccessToken, io.ServiceRepoURL)
+		drivers, err := io.DriverResolver()
+		if err != nil {
+			return err
+		}
+		err = ui.ValidateAccessToken(io.GitHostAccessToken, io.ServiceRepoURL, drivers)
 		if err != nil {
 			return fmt.Errorf("Access token validation failed: %v", err)
 		}
@@ -304,13 +319,15 @@ func warnIfNotFound(spinner utility.Status, warningMsg string, err error) {
 
 // Validate validates the parameters of the BootstrapParameters.
 func (io *BootstrapParameters) Validate() error {
-	gr, err := url.Parse(io.GitOpsRepoURL)
+	gr, err := giturl.Parse(io.GitOpsRepoURL)
 	if err != nil {
 		return fmt.Errorf("failed to parse url %s: %w", io.GitOpsRepoURL, err)
 	}
 
-	// TODO: this may not work with GitLab as the repo can have more path elements.
-	if len(utility.RemoveEmptyStrings(strings.Split(gr.Path, "/"))) != 2 {
+	// GitLab projects can be in nested subgroups e.g. group/subgroup/repo, so
+	// the org can only be checked if the repository is on GitHub.
+	components := utility.RemoveEmptyStrings(strings.Split(gr.Path, "/"))
+	if len(components) < 2 || (len(components) > 2 && io.isGitHub()) {
 		return fmt.Errorf("repo must be org/repo: %s", strings.Trim(gr.Path, ".git"))
 	}
 
@@ -319,6 +336,30 @@ func (io *BootstrapParameters) Validate() error {
 			return fmt.Errorf("invalid driver type: %q", io.PrivateRepoDriver)
 		}
 	}
+	hosts := []string{}
+	for k := range io.GitDrivers {
+		hosts = append(hosts, k)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		if !supportedDrivers.supported(io.GitDrivers[host]) {
+			return fmt.Errorf("invalid driver type for %s: %q", host, io.GitDrivers[host])
+		}
+	}
+	switch io.RBACMode {
+	case "", config.DefaultRBACMode, config.MinimalRBACMode:
+	default:
+		return fmt.Errorf("invalid RBAC mode: %q, must be one of %s or %s", io.RBACMode, config.DefaultRBACMode, config.MinimalRBACMode)
+	}
+	if io.SSHPrivateKeyFilename == "" && io.hasSSHRepoURL() {
+		return errors.New("--ssh-private-key is required to clone SSH repository URLs")
+	}
+	if io.SSHKnownHostsFilename == "" && io.hasSSHRepoURL() {
+		return errors.New("--ssh-known-hosts is required to verify the hosts of SSH repository URLs")
+	}
+	if io.SSHKnownHostsFilename != "" && io.SSHPrivateKeyFilename == "" {
+		return errors.New("--ssh-private-key is required if --ssh-known-hosts is provided")
+	}
 	if io.SaveTokenKeyRing && io.GitHostAccessToken == "" {
 		return errors.New("--git-host-access-token is required if --save-token-keyring is enabled")
 	}
@@ -326,6 +367,22 @@ func (io *BootstrapParameters) Validate() error {
 	return nil
 }
 
+// isGitHub returns true if the GitOps repository is on a GitHub host.
+func (io *BootstrapParameters) isGitHub() bool {
+	drivers, err := io.DriverResolver()
+	if err != nil {
+		return false
+	}
+	driver, err := scm.GetDriverName(io.GitOpsRepoURL, drivers)
+	return err == nil && driver == "github"
+}
+
+// hasSSHRepoURL returns true if the GitOps or service repository URL is an SSH
+// URL, e.g. git@github.com:org/repo.git
+func (io *BootstrapParameters) hasSSHRepoURL() bool {
+	return giturl.IsSSH(io.GitOpsRepoURL) || giturl.IsSSH(io.ServiceRepoURL)
+}
+
 // Run runs the project Bootstrap command.
 func (io *BootstrapParameters) Run() error {
 	log.Progressf("\nCompleting Bootstrap process\n")
@@ -334,8 +391,16 @@ func (io *BootstrapParameters) Run() error {
 	if err != nil {
 		return err
 	}
+	logBootstrapOptions(io.BootstrapOptions)
+	log.Success("OpenShift Pipelines resources created")
+	log.Success("Openshift Route for EventListener created")
+	log.Successf("Created dev, stage and CICD environments")
 	if io.PushToGit {
-		err = pipelines.BootstrapRepository(io.BootstrapOptions, factory.FromRepoURL, pipelines.NewCmdExecutor(), appFs)
+		drivers, err := io.DriverResolver()
+		if err != nil {
+			return err
+		}
+		err = pipelines.BootstrapRepository(io.BootstrapOptions, drivers.NewClient, pipelines.NewCmdExecutor(), appFs)
 		if err != nil {
 			return fmt.Errorf("failed to create the gitops repository: %q: %w", io.GitOpsRepoURL, err)
 		}
@@ -345,6 +410,25 @@ func (io *BootstrapParameters) Run() error {
 	return nil
 }
 
+// logBootstrapOptions logs the options that were used, after the image
+// repository was defaulted by the bootstrap.
+func logBootstrapOptions(o *pipelines.BootstrapOptions) {
+	log.Success("Options used:")
+	log.Progressf("  Service repository: %s", o.ServiceRepoURL)
+	log.Progressf("  GitOps repository: %s", o.GitOpsRepoURL)
+	isInternalRegistry, imageRepo, _ := imagerepo.ValidateImageRepo(o.ImageRepo)
+	log.Progressf("  Image repository: %s", imageRepo)
+	if !isInternalRegistry {
+		log.Progressf("  Path to config.json: %s", o.DockerConfigJSONFilename)
+	}
+	if o.SSHPrivateKeyFilename != "" {
+		log.Progressf("  Path to SSH private key: %s", o.SSHPrivateKeyFilename)
+	}
+	log.Progressf("  Output folder: %s", o.OutputPath)
+	log.Progressf("  Overwrite output folder: %s", strconv.FormatBool(o.Overwrite))
+	log.Progressf("")
+}
+
 // NewCmdBootstrap creates the project init command.
 func NewCmdBootstrap(name, fullName string) *cobra.Command {
 	o := NewBootstrapParameters()
@@ -358,7 +442,7 @@ func NewCmdBootstrap(name, fullName string) *cobra.Command {
 			genericclioptions.GenericRun(o, cmd, args)
 		},
 	}
-	bootstrapCmd.Flags().StringVar(&o.GitOpsRepoURL, "gitops-repo-url", "", "Provide the URL for your GitOps repository e.g. https://github.com/organisation/repository.git")
+	bootstrapCmd.Flags().StringVar(&o.GitOpsRepoURL, "gitops-repo-url", "", "Provide the URL for your GitOps repository e.g. https://github.com/organisation/repository.git or git@github.com:organisation/repository.git")
 	bootstrapCmd.Flags().StringVar(&o.GitOpsWebhookSecret, "gitops-webhook-secret", "", "Provide a secret that we can use to authenticate incoming hooks from your Git hosting service for the GitOps repository. (if not provided, it will be auto-generated)")
 	bootstrapCmd.Flags().StringVar(&o.OutputPath, "output", "./gitops", "Path to write GitOps resources")
 	bootstrapCmd.Flags().StringVarP(&o.Prefix, "prefix", "p", "", "Add a prefix to the environment names(Dev, stage,prod,cicd etc.) to distinguish and identify individual environments")
@@ -370,7 +454,11 @@ func NewCmdBootstrap(name, fullName string) *cobra.Command {
 	bootstrapCmd.Flags().StringVar(&o.ServiceWebhookSecret, "service-webhook-secret", "", "Provide a secret that we can use to authenticate incoming hooks from your Git hosting service for the Service repository. (if not provided, it will be auto-generated)")
 	bootstrapCmd.Flags().BoolVar(&o.SaveTokenKeyRing, "save-token-keyring", false, "Explicitly pass this flag to update the git-host-access-token in the keyring on your local machine")
 	bootstrapCmd.Flags().StringVar(&o.PrivateRepoDriver, "private-repo-driver", "", "If your Git repositories are on a custom domain, please indicate which driver to use github or gitlab")
+	bootstrapCmd.Flags().StringToStringVar(&o.GitDrivers, "git-drivers", nil, "If your service repositories are on other custom domains, indicate the driver to use for each host e.g. gitlab.example.com=gitlab")
 	bootstrapCmd.Flags().BoolVar(&o.PushToGit, "push-to-git", false, "If true, automatically creates and populates the gitops-repo-url with the generated resources")
+	bootstrapCmd.Flags().StringVar(&o.RBACMode, "rbac-mode", config.DefaultRBACMode, "Mode used to generate the roles for the pipelines service account, minimal generates namespace-scoped roles instead of a ClusterRole")
+	bootstrapCmd.Flags().StringVar(&o.SSHPrivateKeyFilename, "ssh-private-key", "", "Filepath to the SSH private key which authenticates the pipeline clones of SSH repository URLs e.g. git@github.com:organisation/repository.git")
+	bootstrapCmd.Flags().StringVar(&o.SSHKnownHostsFilename, "ssh-known-hosts", "", "Filepath to the known_hosts for the hosts of the SSH repository URLs, required with SSH repository URLs")
 	bootstrapCmd.Flags().BoolVar(&o.Interactive, "interactive", false, "If true, enable prompting for most options if not already specified on the command line")
 	return bootstrapCmd
 }
@@ -388,6 +476,6 @@ func isKnownDriver(repoURL string) bool {
 	if err != nil {
 		return false
 	}
-	_, err = factory.DefaultIdentifier.Identify(host)
+	_, err = scm.NewDriverResolver(nil).Identify(host)
 	return err == nil
 }
@@ -99,15 +99,23 @@ func TestAddSuffixWithBootstrap(t *testing.T) {
 
 func TestValidateBootstrapParameter(t *testing.T) {
 	optionTests := []struct {
-		name    string
-		gitRepo string
-		driver  string
-		errMsg  string
+		name       string
+		gitRepo    string
+		driver     string
+		gitDrivers map[string]string
+		rbacMode   string
+		errMsg     string
 	}{
-		{"invalid repo", "test", "", "repo must be org/repo"},
-		{"valid repo", "test/repo", "", ""},
-		{"invalid driver", "test/repo", "unknown", "invalid"},
-		{"valid driver gitlab", "test/repo", "gitlab", ""},
+		{"invalid repo", "test", "", nil, "", "repo must be org/repo"},
+		{"valid repo", "test/repo", "", nil, "", ""},
+		{"valid GitLab subgroup repo", "https://gitlab.com/group/subgroup/team/repo.git", "", nil, "", ""},
+		{"invalid GitHub nested repo", "https://github.com/org/team/repo.git", "", nil, "", "repo must be org/repo"},
+		{"invalid driver", "test/repo", "unknown", nil, "", "invalid"},
+		{"valid driver gitlab", "test/repo", "gitlab", nil, "", ""},
+		{"valid git drivers", "test/repo", "github", map[string]string{"gitlab.example.com": "gitlab"}, "", ""},
+		{"invalid git drivers", "test/repo", "", map[string]string{"git.example.com": "unknown"}, "", `invalid driver type for git.example.com: "unknown"`},
+		{"minimal RBAC mode", "test/repo", "", nil, "minimal", ""},
+		{"invalid RBAC mode", "test/repo", "", nil, "strict", "invalid RBAC mode"},
 	}
 
 	for _, tt := range optionTests {
@@ -115,6 +123,8 @@ func TestValidateBootstrapParameter(t *testing.T) {
 			BootstrapOptions: &pipelines.BootstrapOptions{
 				GitOpsRepoURL:     tt.gitRepo,
 				PrivateRepoDriver: tt.driver,
+				GitDrivers:        tt.gitDrivers,
+				RBACMode:          tt.rbacMode,
 				Prefix:            "test",
 			},
 		}
@@ -131,6 +141,47 @@ func TestValidateBootstrapParameter(t *testing.T) {
 	}
 }
 
+func TestValidateBootstrapParameterWithSSHRepoURLs(t *testing.T) {
+	optionTests := []struct {
+		name          string
+		gitRepo       string
+		serviceRepo   string
+		sshPrivateKey string
+		sshKnownHosts string
+		errMsg        string
+	}{
+		{"SSH GitOps repo", "git@github.com:org/gitops.git", "https://github.com/org/service.git", "~/.ssh/id_rsa", "~/.ssh/known_hosts", ""},
+		{"SSH service repo", "https://github.com/org/gitops.git", "ssh://git@gitlab.com/group/subgroup/service.git", "~/.ssh/id_rsa", "~/.ssh/known_hosts", ""},
+		{"SSH GitOps repo without key", "git@github.com:org/gitops.git", "https://github.com/org/service.git", "", "", "--ssh-private-key is required to clone SSH repository URLs"},
+		{"SSH service repo without key", "https://github.com/org/gitops.git", "git@github.com:org/service.git", "", "", "--ssh-private-key is required to clone SSH repository URLs"},
+		{"SSH GitOps repo without known hosts", "git@github.com:org/gitops.git", "https://github.com/org/service.git", "~/.ssh/id_rsa", "", "--ssh-known-hosts is required to verify the hosts of SSH repository URLs"},
+		{"invalid SSH GitOps repo", "git@github.com:gitops.git", "https://github.com/org/service.git", "~/.ssh/id_rsa", "~/.ssh/known_hosts", "repo must be org/repo"},
+		{"known hosts without key", "https://github.com/org/gitops.git", "https://github.com/org/service.git", "", "~/.ssh/known_hosts", "--ssh-private-key is required if --ssh-known-hosts is provided"},
+	}
+
+	for _, tt := range optionTests {
+		o := BootstrapParameters{
+			BootstrapOptions: &pipelines.BootstrapOptions{
+				GitOpsRepoURL:         tt.gitRepo,
+				ServiceRepoURL:        tt.serviceRepo,
+				SSHPrivateKeyFilename: tt.sshPrivateKey,
+				SSHKnownHostsFilename: tt.sshKnownHosts,
+				Prefix:                "test",
+			},
+		}
+		err := o.Validate()
+
+		if err != nil && tt.errMsg == "" {
+			t.Errorf("Validate() %#v got an unexpected error: %s", tt.name, err)
+			continue
+		}
+
+		if !matchError(t, tt.errMsg, err) {
+			t.Errorf("Validate() %#v failed to match error: got %s, want %s", tt.name, err, tt.errMsg)
+		}
+	}
+}
+
 func TestCheckSpinner(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -2,11 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/openshift/odo/pkg/log"
 	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
 	"github.com/redhat-developer/kam/pkg/pipelines"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 	"github.com/spf13/cobra"
 
 	ktemplates "k8s.io/kubectl/pkg/util/templates"
@@ -21,9 +23,20 @@ var (
 	buildExample = ktemplates.Examples(`
 	# Build files from pipelines
 	%[1]s 
+
+	# Apply the built resources without writing them
+	%[1]s --output - | oc apply -f -
+
+	# Write the built resources as a JSON List
+	%[1]s --output - -o json
 	`)
 
-	buildLongDesc  = ktemplates.LongDesc(`Build GitOps pipelines files, generating the ArgoCD applications and OpenShift Pipelines EventListener`)
+	buildLongDesc = ktemplates.LongDesc(`Build GitOps pipelines files, generating the ArgoCD applications and OpenShift Pipelines EventListener.
+
+	If the output is "-" the resources are written to stdout as a single stream,
+	sorted by path, with the path that each resource would be written to in the
+	kam.openshift.io/path annotation. The kustomizations are not objects that can
+	be applied, so they are not written to the stream.`)
 	buildShortDesc = `Build pipelines files`
 )
 
@@ -31,8 +44,12 @@ var (
 type BuildParameters struct {
 	pipelinesFolderPath string
 	output              string // path to add Gitops resources
+	format              string
 }
 
+// stdoutOutput is the output that writes the resources to stdout.
+const stdoutOutput = "-"
+
 // NewBuildParameters bootstraps a BuildParameters instance.
 func NewBuildParameters() *BuildParameters {
 	return &BuildParameters{}
@@ -45,7 +62,16 @@ func (io *BuildParameters) Complete(name string, cmd *cobra.Command, args []stri
 
 // Validate validates the parameters of the BuildParameters.
 func (io *BuildParameters) Validate() error {
-	return nil
+	switch io.format {
+	case yaml.YAMLFormat:
+		return nil
+	case yaml.JSONFormat:
+		if io.output != stdoutOutput {
+			return fmt.Errorf("the %s format can only be used with --output %s", io.format, stdoutOutput)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported format %q, must be one of %s or %s", io.format, yaml.YAMLFormat, yaml.JSONFormat)
 }
 
 // Run runs the project bootstrap command.
@@ -53,12 +79,19 @@ func (io *BuildParameters) Run() error {
 	options := pipelines.BuildParameters{
 		PipelinesFolderPath: io.pipelinesFolderPath,
 		OutputPath:          io.output,
+		Format:              io.format,
+	}
+	if io.output == stdoutOutput {
+		options.Out = os.Stdout
 	}
 	err := pipelines.BuildResources(&options, ioutils.NewFilesystem())
 	if err != nil {
 		return err
 	}
-	log.Success("Built successfully.")
+	// The success message would be part of the stream.
+	if options.Out == nil {
+		log.Success("Built successfully.")
+	}
 	return nil
 }
 
@@ -75,7 +108,8 @@ func NewCmdBuild(name, fullName string) *cobra.Command {
 		},
 	}
 
-	buildCmd.Flags().StringVar(&o.output, "output", ".", "Folder path to add GitOps resources")
+	buildCmd.Flags().StringVar(&o.output, "output", ".", "Folder path to add GitOps resources, or - to write the resources to stdout")
+	buildCmd.Flags().StringVarP(&o.format, "format", "o", yaml.YAMLFormat, "Format of the resources written to stdout, one of yaml or json")
 	buildCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
 	return buildCmd
 }
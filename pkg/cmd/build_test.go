@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestBuildParametersValidate(t *testing.T) {
+	formatTests := []struct {
+		output  string
+		format  string
+		wantErr string
+	}{
+		{".", "yaml", ""},
+		{"-", "yaml", ""},
+		{"-", "json", ""},
+		{".", "json", "the json format can only be used with --output -"},
+		{"-", "xml", `unsupported format "xml", must be one of yaml or json`},
+	}
+
+	for _, tt := range formatTests {
+		t.Run(tt.output+" "+tt.format, func(rt *testing.T) {
+			o := BuildParameters{output: tt.output, format: tt.format}
+			test.AssertErrorMatch(rt, tt.wantErr, o.Validate())
+		})
+	}
+}
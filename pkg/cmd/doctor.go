@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/redhat-developer/kam/pkg/pipelines/doctor"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// DoctorRecommendedCommandName the recommended command name
+	DoctorRecommendedCommandName = "doctor"
+)
+
+var (
+	doctorExample = ktemplates.Examples(`
+	# Check the health of the GitOps setup described in pipelines.yaml
+	%[1]s --pipelines-folder gitops
+	`)
+
+	doctorLongDesc = ktemplates.LongDesc(`Check the health of an installed GitOps setup.
+
+	Verifies that the CI/CD namespace, EventListener and its route are running,
+	that webhook secrets exist and webhooks deliver to the current route, that
+	the pipeline ServiceAccount has the expected secrets and that Argo CD can
+	read the GitOps repository.`)
+	doctorShortDesc = `Check the health of a GitOps setup`
+)
+
+// DoctorParameters encapsulates the parameters for the kam doctor command.
+type DoctorParameters struct {
+	pipelinesFolderPath string
+	gitHostAccessToken  string
+}
+
+// NewDoctorParameters bootstraps a DoctorParameters instance.
+func NewDoctorParameters() *DoctorParameters {
+	return &DoctorParameters{}
+}
+
+// Complete completes DoctorParameters after they've been created.
+func (io *DoctorParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the DoctorParameters.
+func (io *DoctorParameters) Validate() error {
+	return nil
+}
+
+// Run runs the checks and reports the results.
+func (io *DoctorParameters) Run() error {
+	results, err := doctor.Run(&doctor.Options{
+		PipelinesFolderPath: io.pipelinesFolderPath,
+		GitHostAccessToken:  io.gitHostAccessToken,
+	})
+	if err != nil {
+		return err
+	}
+	if log.IsJSON() {
+		utility.OutputSuccess(results)
+	} else if err := printDoctorResults(os.Stdout, results); err != nil {
+		return err
+	}
+	if doctor.Failed(results) {
+		return errors.New("one or more checks failed")
+	}
+	return nil
+}
+
+func printDoctorResults(out io.Writer, results []doctor.Result) error {
+	w := tabwriter.NewWriter(out, 5, 2, 3, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tCHECK\tMESSAGE")
+	fmt.Fprintln(w, "======\t=====\t=======")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", strings.ToUpper(string(r.Status)), r.Check, r.Message)
+		if r.Hint != "" {
+			fmt.Fprintf(w, "\t\t  hint: %s\n", r.Hint)
+		}
+	}
+	return w.Flush()
+}
+
+// NewCmdDoctor creates the doctor command.
+func NewCmdDoctor(name, fullName string) *cobra.Command {
+	o := NewDoctorParameters()
+	doctorCmd := &cobra.Command{
+		Use:     name,
+		Short:   doctorShortDesc,
+		Long:    doctorLongDesc,
+		Example: fmt.Sprintf(doctorExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	doctorCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	doctorCmd.Flags().StringVar(&o.gitHostAccessToken, "git-host-access-token", "", "Access token used to read the Git repository webhooks, if not provided the token stored in the keyring or environment is used")
+	return doctorCmd
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/doctor"
+)
+
+func TestPrintDoctorResults(t *testing.T) {
+	results := []doctor.Result{
+		{Check: "CI/CD namespace", Status: doctor.Pass, Message: "namespace cicd is active"},
+		{Check: "EventListener", Status: doctor.Fail, Message: "no EventListener pods are ready in cicd", Hint: "Look at the pod logs"},
+	}
+	var b bytes.Buffer
+
+	if err := printDoctorResults(&b, results); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `STATUS   CHECK             MESSAGE
+======   =====             =======
+PASS     CI/CD namespace   namespace cicd is active
+FAIL     EventListener     no EventListener pods are ready in cicd
+                             hint: Look at the pod logs
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("printDoctorResults() failed:\n%s", diff)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"github.com/openshift/odo/pkg/log"
 	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
 	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/roles"
 	"github.com/spf13/cobra"
 
 	ktemplates "k8s.io/kubectl/pkg/util/templates"
@@ -23,7 +25,11 @@ var (
 	%[1]s 
 	`)
 
-	addEnvLongDesc  = ktemplates.LongDesc(`Add a new environment to the GitOps repository`)
+	addEnvLongDesc = ktemplates.LongDesc(`Add a new environment to the GitOps repository
+
+	When the RBAC mode is minimal, the CI dry-run can't create the namespace of the new environment,
+	or grant itself access to it. A cluster admin must apply the namespace and the namespaces ClusterRole
+	of the CI/CD environment before the change is pushed.`)
 	addEnvShortDesc = `Add a new environment`
 )
 
@@ -32,6 +38,7 @@ type AddEnvParameters struct {
 	envName         string
 	pipelinesFolder string
 	cluster         string
+	namespace       string
 }
 
 // NewAddEnvParameters bootstraps a AddEnvParameters instance.
@@ -58,12 +65,20 @@ func (eo *AddEnvParameters) Run() error {
 		EnvName:             eo.envName,
 		PipelinesFolderPath: eo.pipelinesFolder,
 		Cluster:             eo.cluster,
+		Namespace:           eo.namespace,
 	}
 	err := pipelines.AddEnv(&options, ioutils.NewFilesystem())
 	if err != nil {
 		return err
 	}
 	log.Successf("Created Environment %s successfully.", eo.envName)
+	m, err := config.LoadManifest(ioutils.NewFilesystem(), eo.pipelinesFolder)
+	if err != nil {
+		return err
+	}
+	if m.GetPipelinesConfig().IsMinimalRBAC() {
+		log.Warningf("The RBAC mode is minimal, a cluster admin must apply the namespace of the environment and the ClusterRole %s before the change is pushed", roles.NamespacesClusterRoleName)
+	}
 	return nil
 }
 
@@ -85,5 +100,6 @@ func NewCmdAddEnv(name, fullName string) *cobra.Command {
 	_ = addEnvCmd.MarkFlagRequired("env-name")
 	addEnvCmd.Flags().StringVar(&o.pipelinesFolder, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
 	addEnvCmd.Flags().StringVar(&o.cluster, "cluster", "", "Deployment cluster e.g. https://kubernetes.local.svc")
+	addEnvCmd.Flags().StringVar(&o.namespace, "namespace", "", "Namespace that the environment deploys to, if not provided the environment name is used")
 	return addEnvCmd
 }
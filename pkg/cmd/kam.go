@@ -4,7 +4,10 @@ import (
 	"log"
 
 	"github.com/redhat-developer/kam/pkg/cmd/environment"
+	"github.com/redhat-developer/kam/pkg/cmd/manifest"
+	"github.com/redhat-developer/kam/pkg/cmd/pipelines"
 	"github.com/redhat-developer/kam/pkg/cmd/service"
+	"github.com/redhat-developer/kam/pkg/cmd/triggers"
 	"github.com/redhat-developer/kam/pkg/cmd/utility"
 	"github.com/redhat-developer/kam/pkg/cmd/version"
 	"github.com/redhat-developer/kam/pkg/cmd/webhook"
@@ -33,6 +36,12 @@ func MakeRootCmd() *cobra.Command {
 		version.NewCmd(version.RecommendedCommandName, utility.GetFullName(fullName, version.RecommendedCommandName)),
 		webhook.NewCmdWebhook(webhook.RecommendedCommandName, utility.GetFullName(fullName, webhook.RecommendedCommandName)),
 		NewCmdBuild(BuildRecommendedCommandName, utility.GetFullName(fullName, BuildRecommendedCommandName)),
+		NewCmdDoctor(DoctorRecommendedCommandName, utility.GetFullName(fullName, DoctorRecommendedCommandName)),
+		pipelines.NewCmd(pipelines.RecommendedCommandName, utility.GetFullName(fullName, pipelines.RecommendedCommandName)),
+		triggers.NewCmd(triggers.RecommendedCommandName, utility.GetFullName(fullName, triggers.RecommendedCommandName)),
+		manifest.NewCmd(manifest.RecommendedCommandName, utility.GetFullName(fullName, manifest.RecommendedCommandName)),
+		NewCmdUpgrade(UpgradeRecommendedCommandName, utility.GetFullName(fullName, UpgradeRecommendedCommandName)),
+		NewCmdValidate(ValidateRecommendedCommandName, utility.GetFullName(fullName, ValidateRecommendedCommandName)),
 		completionCmd,
 	)
 	return rootCmd
@@ -0,0 +1,31 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended manifest command name.
+const RecommendedCommandName = "manifest"
+
+// NewCmd creates a new manifest command
+func NewCmd(name, fullName string) *cobra.Command {
+	schemaCmd := newCmdSchema(schemaRecommendedCommandName, utility.GetFullName(fullName, schemaRecommendedCommandName))
+
+	var cmd = &cobra.Command{
+		Use:   name,
+		Short: "Work with the pipelines.yaml manifest",
+		Long:  "Work with the pipelines.yaml manifest that describes the GitOps environments, applications and services",
+		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, schemaRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	cmd.AddCommand(schemaCmd)
+
+	cmd.Annotations = map[string]string{"command": "main"}
+	return cmd
+}
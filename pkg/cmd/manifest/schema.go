@@ -0,0 +1,75 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+)
+
+const schemaRecommendedCommandName = "schema"
+
+var (
+	schemaExample = ktemplates.Examples(`	# Write the JSON Schema for pipelines.yaml to a file for an editor to use
+	%[1]s > pipelines.schema.json`)
+
+	schemaLongDesc = ktemplates.LongDesc(`Print the JSON Schema for pipelines.yaml.
+
+	The schema is generated from the manifest that this version of kam reads, and can be used by
+	editors to validate pipelines.yaml.`)
+)
+
+// SchemaParameters encapsulates the parameters for the kam manifest schema command.
+type SchemaParameters struct {
+	out io.Writer
+}
+
+// NewSchemaParameters bootstraps a SchemaParameters instance.
+func NewSchemaParameters() *SchemaParameters {
+	return &SchemaParameters{out: os.Stdout}
+}
+
+// Complete completes SchemaParameters after they've been created.
+func (o *SchemaParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the SchemaParameters.
+func (o *SchemaParameters) Validate() error {
+	return nil
+}
+
+// Run prints the manifest schema.
+func (o *SchemaParameters) Run() error {
+	return WriteSchema(o.out)
+}
+
+// WriteSchema writes the JSON Schema for pipelines.yaml to the writer.
+func WriteSchema(out io.Writer) error {
+	b, err := json.MarshalIndent(config.ManifestSchema(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the manifest schema: %v", err)
+	}
+	_, err = fmt.Fprintf(out, "%s\n", b)
+	return err
+}
+
+func newCmdSchema(name, fullName string) *cobra.Command {
+	o := NewSchemaParameters()
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   "Print the JSON Schema for pipelines.yaml",
+		Long:    schemaLongDesc,
+		Example: fmt.Sprintf(schemaExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+	return cmd
+}
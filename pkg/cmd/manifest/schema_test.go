@@ -0,0 +1,44 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunSchema(t *testing.T) {
+	var b bytes.Buffer
+	o := &SchemaParameters{out: &b}
+
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &schema); err != nil {
+		t.Fatalf("failed to parse the schema: %v", err)
+	}
+	if schema["title"] != "kam pipelines.yaml" {
+		t.Fatalf("got schema title %v", schema["title"])
+	}
+}
+
+// The published schema must be regenerated when the manifest changes.
+func TestPublishedSchemaIsCurrent(t *testing.T) {
+	published, err := ioutil.ReadFile("../../../docs/schema/pipelines.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+
+	if err := WriteSchema(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(string(published), b.String()); diff != "" {
+		t.Fatalf("docs/schema/pipelines.schema.json is out of date, run 'go run tools/manifest-schema/main.go':\n%s", diff)
+	}
+}
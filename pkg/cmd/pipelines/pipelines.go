@@ -0,0 +1,31 @@
+package pipelines
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended pipelines command name.
+const RecommendedCommandName = "pipelines"
+
+// NewCmd creates a new pipelines command
+func NewCmd(name, fullName string) *cobra.Command {
+	runsCmd := newCmdRuns(runsRecommendedCommandName, utility.GetFullName(fullName, runsRecommendedCommandName))
+
+	var cmd = &cobra.Command{
+		Use:   name,
+		Short: "Inspect CI pipeline runs",
+		Long:  "Inspect the CI pipeline runs that were triggered for services in a GitOps environment",
+		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, runsRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	cmd.AddCommand(runsCmd)
+
+	cmd.Annotations = map[string]string{"command": "main"}
+	return cmd
+}
@@ -0,0 +1,118 @@
+package pipelines
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	backend "github.com/redhat-developer/kam/pkg/pipelines/pipelineruns"
+)
+
+const runsRecommendedCommandName = "runs"
+
+var (
+	runsExample = ktemplates.Examples(`	# List the CI pipeline runs for a service
+	%[1]s --env-name dev --service-name taxi
+
+	# Show the logs of the most recent CI pipeline run for a commit
+	%[1]s --env-name dev --service-name taxi --commit 0a1b2c3d4e5f60718293a4b5c6d7e8f901234567 --logs`)
+)
+
+// RunsParameters encapsulates the parameters for the kam pipelines runs command.
+type RunsParameters struct {
+	envName             string
+	serviceName         string
+	pipelinesFolderPath string
+	commit              string
+	logs                bool
+}
+
+// NewRunsParameters bootstraps a RunsParameters instance.
+func NewRunsParameters() *RunsParameters {
+	return &RunsParameters{}
+}
+
+// Complete completes RunsParameters after they've been created.
+func (o *RunsParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the RunsParameters.
+func (o *RunsParameters) Validate() error {
+	if o.envName == "" || o.serviceName == "" {
+		return fmt.Errorf("both 'env-name' and 'service-name' must be specified")
+	}
+	return nil
+}
+
+// Run lists the pipeline runs, or shows the logs of the most recent one.
+func (o *RunsParameters) Run() error {
+	if o.logs {
+		return backend.Logs(o.pipelinesFolderPath, o.envName, o.serviceName, o.commit, os.Stdout)
+	}
+
+	runs, err := backend.List(o.pipelinesFolderPath, o.envName, o.serviceName, o.commit)
+	if err != nil {
+		return fmt.Errorf("unable to list pipeline runs: %v", err)
+	}
+
+	if log.IsJSON() {
+		utility.OutputSuccess(runs)
+		return nil
+	}
+	if len(runs) == 0 {
+		log.Infof("No pipeline runs found for service %s in environment %s", o.serviceName, o.envName)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
+	fmt.Fprintln(w, "NAME\tSTATUS\tSTARTED\tDURATION\tCOMMIT\tAUTHOR\tIMAGE")
+	fmt.Fprintln(w, "====\t======\t=======\t========\t======\t======\t=====")
+	for _, r := range runs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Name, r.Status, startedAt(r), r.Duration, shortCommit(r.Commit), r.Author, r.Image)
+	}
+	return w.Flush()
+}
+
+func newCmdRuns(name, fullName string) *cobra.Command {
+	o := NewRunsParameters()
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   "List the CI pipeline runs for a service",
+		Long:    "List the CI pipeline runs that were triggered by pushes to a service's source repository, with their status, duration, commit, author and image. Runs that were triggered before kam labelled them with their environment are listed for every environment of the service.",
+		Example: fmt.Sprintf(runsExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.envName, "env-name", "", "Name of the environment where the service exists")
+	cmd.Flags().StringVar(&o.serviceName, "service-name", "", "Name of the service to list pipeline runs for")
+	cmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	cmd.Flags().StringVar(&o.commit, "commit", "", "Only show pipeline runs for this full commit SHA")
+	cmd.Flags().BoolVar(&o.logs, "logs", false, "Show the task logs of the most recent pipeline run")
+	// required flags
+	_ = cmd.MarkFlagRequired("env-name")
+	_ = cmd.MarkFlagRequired("service-name")
+	return cmd
+}
+
+func startedAt(r backend.Run) string {
+	if r.StartTime == nil {
+		return "---"
+	}
+	return r.StartTime.Format(time.RFC3339)
+}
+
+func shortCommit(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -0,0 +1,45 @@
+package pipelines
+
+import (
+	"testing"
+
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestValidateRunsParameters(t *testing.T) {
+	optionTests := []struct {
+		name    string
+		options *RunsParameters
+		wantErr string
+	}{
+		{"missing environment", &RunsParameters{serviceName: "taxi"}, "both 'env-name' and 'service-name' must be specified"},
+		{"missing service", &RunsParameters{envName: "dev"}, "both 'env-name' and 'service-name' must be specified"},
+		{"environment and service", &RunsParameters{envName: "dev", serviceName: "taxi"}, ""},
+	}
+
+	for _, tt := range optionTests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			if !test.ErrorMatch(t, tt.wantErr, err) {
+				t.Errorf("Validate() got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShortCommit(t *testing.T) {
+	commitTests := []struct {
+		sha  string
+		want string
+	}{
+		{"", ""},
+		{"abc123", "abc123"},
+		{"0a1b2c3d4e5f60718293a4b5c6d7e8f901234567", "0a1b2c3d"},
+	}
+
+	for _, tt := range commitTests {
+		if got := shortCommit(tt.sha); got != tt.want {
+			t.Errorf("shortCommit(%q) got %q, want %q", tt.sha, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/openshift/odo/pkg/log"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/redhat-developer/kam/pkg/cmd/utility"
 	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/deployment"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/spf13/cobra"
 
@@ -21,7 +23,10 @@ const (
 
 var (
 	addExample = ktemplates.Examples(`	Add a Service to an environment in GitOps
-	%[1]s`)
+	%[1]s
+
+	# Add a Service with a Deployment, Service and Route
+	%[1]s --app-name app-web --env-name dev --service-name web --image quay.io/example/web:latest --port 8080 --expose`)
 
 	addLongDesc  = ktemplates.LongDesc(`Add a Service to an environment in GitOps`)
 	addShortDesc = `Add a new service`
@@ -40,7 +45,20 @@ func (o *AddServiceOptions) Complete(name string, cmd *cobra.Command, args []str
 
 // Validate validates the parameters of the EnvParameters.
 func (o *AddServiceOptions) Validate() error {
-	return nil
+	if o.Image == "" {
+		if o.Port != 0 || o.Replicas != 0 || len(o.Env) > 0 || o.CPU != "" || o.Memory != "" || o.ReadinessPath != "" || o.Expose {
+			return errors.New("--image is required to configure the deployment of the service")
+		}
+		return nil
+	}
+	if o.Port == 0 && o.Expose {
+		return errors.New("--port is required to expose the service")
+	}
+	if o.Port == 0 && o.ReadinessPath != "" {
+		return errors.New("--port is required for the readiness probe")
+	}
+	_, err := deployment.ParseEnvVars(o.Env)
+	return err
 }
 
 // Run runs the project bootstrap command.
@@ -75,6 +93,14 @@ func newCmdAdd(name, fullName string) *cobra.Command {
 	cmd.Flags().StringVar(&o.ServiceName, "service-name", "", "Name of the service to be added")
 	cmd.Flags().StringVar(&o.EnvName, "env-name", "", "Name of the environment where the service will be added")
 	cmd.Flags().StringVar(&o.ImageRepo, "image-repo", "", "Image registry of the form <registry>/<username>/<image name> or <project>/<app> which is used to push newly built images")
+	cmd.Flags().StringVar(&o.Image, "image", "", "Image to deploy for the service, a Deployment is generated in the config for the service if provided")
+	cmd.Flags().IntVar(&o.Port, "port", 0, "Container port of the service, a Service is generated for the port if provided")
+	cmd.Flags().IntVar(&o.Replicas, "replicas", 0, "Number of replicas of the service, if not provided there is one replica")
+	cmd.Flags().StringArrayVar(&o.Env, "env", nil, "Environment variable for the service of the form KEY=VAL, can be repeated")
+	cmd.Flags().StringVar(&o.CPU, "cpu", "", "CPU request and limit for the service e.g. 500m")
+	cmd.Flags().StringVar(&o.Memory, "memory", "", "Memory request and limit for the service e.g. 512Mi")
+	cmd.Flags().StringVar(&o.ReadinessPath, "readiness-path", "", "HTTP path for the readiness probe of the service e.g. /healthz")
+	cmd.Flags().BoolVar(&o.Expose, "expose", false, "If true, a Route is generated to expose the service")
 	cmd.Flags().StringVar(&o.PipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
 
 	// required flags
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/test"
 	"github.com/spf13/cobra"
 )
 
@@ -39,6 +40,28 @@ func TestCompleteAddOptions(t *testing.T) {
 	}
 }
 
+func TestValidateAddOptions(t *testing.T) {
+	validateTests := []struct {
+		name    string
+		options *pipelines.AddServiceOptions
+		wantErr string
+	}{
+		{"no deployment", &pipelines.AddServiceOptions{}, ""},
+		{"deployment", &pipelines.AddServiceOptions{Image: "quay.io/example/web", Port: 8080, Env: []string{"A=b"}, Expose: true, ReadinessPath: "/"}, ""},
+		{"port without image", &pipelines.AddServiceOptions{Port: 8080}, "--image is required"},
+		{"expose without port", &pipelines.AddServiceOptions{Image: "quay.io/example/web", Expose: true}, "--port is required to expose the service"},
+		{"readiness path without port", &pipelines.AddServiceOptions{Image: "quay.io/example/web", ReadinessPath: "/"}, "--port is required for the readiness probe"},
+		{"invalid env", &pipelines.AddServiceOptions{Image: "quay.io/example/web", Env: []string{"A"}}, `invalid environment variable "A"`},
+	}
+
+	for _, tt := range validateTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			o := AddServiceOptions{AddServiceOptions: tt.options}
+			test.AssertErrorMatch(rt, tt.wantErr, o.Validate())
+		})
+	}
+}
+
 func TestAddCommandWithMissingParams(t *testing.T) {
 	cmdTests := []struct {
 		desc    string
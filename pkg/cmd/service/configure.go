@@ -0,0 +1,93 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/deployment"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	configureRecommendedCommandName = "configure"
+)
+
+var (
+	configureExample = ktemplates.Examples(`	Configure a Service for an environment in GitOps
+	%[1]s --env-name stage --service-name web --replicas 3 --image-tag v2 --set-env LOG_LEVEL=info --config-map-file app.properties`)
+
+	configureLongDesc = ktemplates.LongDesc(`Configure a Service for an environment in GitOps.
+
+	The configuration is written to the overlay for the service in the
+	environment, as a patch for the Deployment of the service, an override of
+	the image tag, and a ConfigMap, named after the service, generated from the
+	files.`)
+	configureShortDesc = `Configure a service for an environment`
+)
+
+// ConfigureServiceOptions encapsulates the parameters for service configure
+// command.
+type ConfigureServiceOptions struct {
+	*pipelines.ConfigureServiceOptions
+}
+
+// Complete is called when the command is completed
+func (o *ConfigureServiceOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the ConfigureServiceOptions.
+func (o *ConfigureServiceOptions) Validate() error {
+	if o.Replicas == 0 && o.ImageTag == "" && len(o.Env) == 0 && len(o.ConfigMapFiles) == 0 {
+		return errors.New("at least one of --replicas, --image-tag, --set-env or --config-map-file is required")
+	}
+	if o.Replicas < 0 {
+		return fmt.Errorf("invalid number of replicas %d", o.Replicas)
+	}
+	_, err := deployment.ParseEnvVars(o.Env)
+	return err
+}
+
+// Run runs the service configure command.
+func (o *ConfigureServiceOptions) Run() error {
+	err := pipelines.ConfigureService(o.ConfigureServiceOptions, ioutils.NewFilesystem())
+	if err != nil {
+		return err
+	}
+	log.Successf("Configured Service %s successfully at environment %s.\n", o.ServiceName, o.EnvName)
+	return nil
+}
+
+func newCmdConfigure(name, fullName string) *cobra.Command {
+	o := &ConfigureServiceOptions{ConfigureServiceOptions: &pipelines.ConfigureServiceOptions{}}
+
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   configureShortDesc,
+		Long:    configureLongDesc,
+		Example: fmt.Sprintf(configureExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.ServiceName, "service-name", "", "Name of the service to be configured")
+	cmd.Flags().StringVar(&o.EnvName, "env-name", "", "Name of the environment where the service is configured")
+	cmd.Flags().IntVar(&o.Replicas, "replicas", 0, "Number of replicas of the service in the environment")
+	cmd.Flags().StringVar(&o.ImageTag, "image-tag", "", "Tag of the image of the service in the environment")
+	cmd.Flags().StringArrayVar(&o.Env, "set-env", nil, "Environment variable for the service of the form KEY=VAL, can be repeated")
+	cmd.Flags().StringArrayVar(&o.ConfigMapFiles, "config-map-file", nil, "File to add to the ConfigMap for the service, can be repeated")
+	cmd.Flags().StringVar(&o.PipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+
+	// required flags
+	_ = cmd.MarkFlagRequired("service-name")
+	_ = cmd.MarkFlagRequired("env-name")
+	return cmd
+}
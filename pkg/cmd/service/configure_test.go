@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestValidateConfigureOptions(t *testing.T) {
+	validateTests := []struct {
+		name    string
+		options *pipelines.ConfigureServiceOptions
+		wantErr string
+	}{
+		{"replicas", &pipelines.ConfigureServiceOptions{Replicas: 2}, ""},
+		{"config map file", &pipelines.ConfigureServiceOptions{ConfigMapFiles: []string{"app.properties"}}, ""},
+		{"no configuration", &pipelines.ConfigureServiceOptions{}, "at least one of --replicas"},
+		{"invalid replicas", &pipelines.ConfigureServiceOptions{Replicas: -1}, "invalid number of replicas -1"},
+		{"invalid env", &pipelines.ConfigureServiceOptions{Env: []string{"=b"}}, `invalid environment variable "=b"`},
+	}
+
+	for _, tt := range validateTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			o := ConfigureServiceOptions{ConfigureServiceOptions: tt.options}
+			test.AssertErrorMatch(rt, tt.wantErr, o.Validate())
+		})
+	}
+}
+
+func TestConfigureCommandWithMissingParams(t *testing.T) {
+	_, _, err := executeCommand(newCmdConfigure("configure", "kam service"), flag("env-name", "dev"), flag("replicas", "2"))
+	test.AssertErrorMatch(t, `required flag\(s\) "service-name" not set`, err)
+}
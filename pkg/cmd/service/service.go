@@ -14,19 +14,21 @@ const RecommendedCommandName = "service"
 func NewCmd(name, fullName string) *cobra.Command {
 
 	addCmd := newCmdAdd(addRecommendedCommandName, utility.GetFullName(fullName, addRecommendedCommandName))
+	configureCmd := newCmdConfigure(configureRecommendedCommandName, utility.GetFullName(fullName, configureRecommendedCommandName))
 
 	var cmd = &cobra.Command{
 		Use:   name,
 		Short: "Manage services in an environment",
 		Long:  "Manage services in a GitOps environment where service source repositories are synchronized",
-		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
-			fullName, addRecommendedCommandName),
+		Example: fmt.Sprintf("%s\n%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, addRecommendedCommandName, configureRecommendedCommandName),
 		Run: func(cmd *cobra.Command, args []string) {
 		},
 	}
 
 	cmd.Flags().AddFlagSet(addCmd.Flags())
 	cmd.AddCommand(addCmd)
+	cmd.AddCommand(configureCmd)
 
 	cmd.Annotations = map[string]string{"command": "main"}
 	return cmd
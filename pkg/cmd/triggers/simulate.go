@@ -0,0 +1,175 @@
+package triggers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	backend "github.com/redhat-developer/kam/pkg/pipelines/simulator"
+)
+
+const simulateRecommendedCommandName = "simulate"
+
+var (
+	simulateExample = ktemplates.Examples(`	# Simulate a webhook event with the payload and headers that were delivered by the Git host
+	%[1]s --payload event.json --headers headers.txt`)
+
+	simulateLongDesc = ktemplates.LongDesc(`Process a webhook event with the generated EventListener, without a cluster.
+
+	Each trigger's CEL filter and overlays are evaluated against the event, and for the triggers that match,
+	the TriggerBinding params are resolved and the resources from the TriggerTemplate are rendered.
+
+	The headers file has one "Name: value" header per line. Interceptors that validate the webhook
+	signature are not evaluated.`)
+)
+
+// SimulateParameters encapsulates the parameters for the kam triggers simulate command.
+type SimulateParameters struct {
+	pipelinesFolderPath string
+	payloadPath         string
+	headersPath         string
+}
+
+// NewSimulateParameters bootstraps a SimulateParameters instance.
+func NewSimulateParameters() *SimulateParameters {
+	return &SimulateParameters{}
+}
+
+// Complete completes SimulateParameters after they've been created.
+func (o *SimulateParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the SimulateParameters.
+func (o *SimulateParameters) Validate() error {
+	if o.payloadPath == "" {
+		return errors.New("'payload' must be specified")
+	}
+	return nil
+}
+
+// Run simulates the event and reports the triggers that matched.
+func (o *SimulateParameters) Run() error {
+	fs := ioutils.NewFilesystem()
+	payload, err := afero.ReadFile(fs, o.payloadPath)
+	if err != nil {
+		return fmt.Errorf("failed to read payload: %v", err)
+	}
+	headers := http.Header{}
+	if o.headersPath != "" {
+		f, err := fs.Open(o.headersPath)
+		if err != nil {
+			return fmt.Errorf("failed to read headers: %v", err)
+		}
+		defer f.Close()
+		if headers, err = parseHeaders(f); err != nil {
+			return fmt.Errorf("failed to read headers from %s: %v", o.headersPath, err)
+		}
+	}
+
+	results, err := backend.Simulate(fs, &backend.Options{
+		PipelinesFolderPath: o.pipelinesFolderPath,
+		Payload:             payload,
+		Headers:             headers,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to simulate event: %v", err)
+	}
+
+	if log.IsJSON() {
+		utility.OutputSuccess(results)
+	} else {
+		printResults(results)
+	}
+	for _, r := range results {
+		if r.Matched {
+			return nil
+		}
+	}
+	return errors.New("no trigger matched the event")
+}
+
+func printResults(results []backend.TriggerResult) {
+	w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', 0)
+	fmt.Fprintln(w, "TRIGGER\tMATCHED\tREASON")
+	fmt.Fprintln(w, "=======\t=======\t======")
+	for _, r := range results {
+		matched := "no"
+		if r.Matched {
+			matched = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Name, matched, r.Reason)
+	}
+	w.Flush()
+
+	for _, r := range results {
+		if !r.Matched {
+			continue
+		}
+		fmt.Printf("\nTrigger %s\n", r.Name)
+		if len(r.Skipped) > 0 {
+			fmt.Printf("Not evaluated: %s\n", strings.Join(r.Skipped, ", "))
+		}
+		w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', 0)
+		fmt.Fprintln(w, "PARAM\tVALUE")
+		for _, p := range r.Params {
+			fmt.Fprintf(w, "%s\t%s\n", p.Name, p.Value)
+		}
+		w.Flush()
+		for _, res := range r.Resources {
+			fmt.Printf("---\n%s", res)
+		}
+	}
+}
+
+// parseHeaders reads headers in the form "Name: value", one per line, blank
+// lines and lines starting with # are ignored.
+func parseHeaders(r io.Reader) (http.Header, error) {
+	headers := http.Header{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid header %q, want Name: value", line)
+		}
+		headers.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+	return headers, scanner.Err()
+}
+
+func newCmdSimulate(name, fullName string) *cobra.Command {
+	o := NewSimulateParameters()
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   "Simulate a webhook event",
+		Long:    simulateLongDesc,
+		Example: fmt.Sprintf(simulateExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	cmd.Flags().StringVar(&o.payloadPath, "payload", "", "Path to the JSON payload of the event")
+	cmd.Flags().StringVar(&o.headersPath, "headers", "", "Path to the headers of the event")
+	// required flags
+	_ = cmd.MarkFlagRequired("payload")
+	return cmd
+}
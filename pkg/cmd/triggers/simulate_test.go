@@ -0,0 +1,60 @@
+package triggers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestValidateSimulateParameters(t *testing.T) {
+	optionTests := []struct {
+		name    string
+		options *SimulateParameters
+		wantErr string
+	}{
+		{"missing payload", &SimulateParameters{headersPath: "headers.txt"}, "'payload' must be specified"},
+		{"payload", &SimulateParameters{payloadPath: "event.json"}, ""},
+	}
+
+	for _, tt := range optionTests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			if !test.ErrorMatch(t, tt.wantErr, err) {
+				t.Errorf("Validate() got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := parseHeaders(strings.NewReader(`# delivered by GitHub
+X-GitHub-Event: push
+
+content-type: application/json
+X-Hub-Signature-256: sha256=abc:def
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := http.Header{
+		"X-Github-Event":      []string{"push"},
+		"Content-Type":        []string{"application/json"},
+		"X-Hub-Signature-256": []string{"sha256=abc:def"},
+	}
+	if diff := cmp.Diff(want, headers); diff != "" {
+		t.Fatalf("parseHeaders() failed:\n%s", diff)
+	}
+}
+
+func TestParseHeadersWithInvalidHeader(t *testing.T) {
+	_, err := parseHeaders(strings.NewReader("X-GitHub-Event push\n"))
+
+	if !test.ErrorMatch(t, `invalid header "X-GitHub-Event push"`, err) {
+		t.Fatalf("parseHeaders() got error %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package triggers
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended triggers command name.
+const RecommendedCommandName = "triggers"
+
+// NewCmd creates a new triggers command
+func NewCmd(name, fullName string) *cobra.Command {
+	simulateCmd := newCmdSimulate(simulateRecommendedCommandName, utility.GetFullName(fullName, simulateRecommendedCommandName))
+
+	var cmd = &cobra.Command{
+		Use:   name,
+		Short: "Test the EventListener triggers",
+		Long:  "Test the generated EventListener triggers, bindings and templates without a cluster",
+		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, simulateRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	cmd.AddCommand(simulateCmd)
+
+	cmd.Annotations = map[string]string{"command": "main"}
+	return cmd
+}
@@ -8,6 +8,7 @@ import (
 	"text/tabwriter"
 
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"github.com/spf13/afero"
 	"gopkg.in/AlecAivazis/survey.v1"
 )
@@ -66,6 +67,36 @@ func EnterDockercfg() string {
 	return strings.TrimSpace(dockerCfg)
 }
 
+// EnterSSHPrivateKey allows the user to specify the path to the SSH private
+// key that is used to clone the SSH repository URLs in a UI prompt.
+func EnterSSHPrivateKey() string {
+	var sshPrivateKey string
+	prompt := &survey.Input{
+		Message: "Provide the path to the SSH private key which authenticates the clones of the SSH repository URLs",
+		Help:    "The private key present in the file path generates a secret that is used by the pipelines to clone the GitOps and service repositories over SSH.",
+		Default: "~/.ssh/id_rsa",
+	}
+
+	err := survey.AskOne(prompt, &sshPrivateKey, nil)
+	handleError(err)
+	return strings.TrimSpace(sshPrivateKey)
+}
+
+// EnterSSHKnownHosts allows the user to specify the path to the known_hosts
+// that verifies the hosts of the SSH repository URLs in a UI prompt.
+func EnterSSHKnownHosts() string {
+	var sshKnownHosts string
+	prompt := &survey.Input{
+		Message: "Provide the path to the known_hosts which verifies the hosts of the SSH repository URLs",
+		Help:    "The known_hosts present in the file path is added to the SSH secret, the pipelines refuse to clone over SSH from hosts with keys that are not in it.",
+		Default: "~/.ssh/known_hosts",
+	}
+
+	err := survey.AskOne(prompt, &sshKnownHosts, nil)
+	handleError(err)
+	return strings.TrimSpace(sshKnownHosts)
+}
+
 // EnterImageRepoExternalRepository allows the user to specify the type of image
 // registry they wish to use in a UI prompt.
 func EnterImageRepoExternalRepository() string {
@@ -131,13 +162,13 @@ func EnterGitWebhookSecret(repoURL string) string {
 
 // EnterGitHostAccessToken , it becomes necessary to add the personal access
 // token to access upstream git hosts.
-func EnterGitHostAccessToken(serviceRepo string) string {
+func EnterGitHostAccessToken(serviceRepo string, drivers *scm.DriverResolver) string {
 	var accessToken string
 	prompt := &survey.Password{
 		Message: fmt.Sprintf("Please provide a token used to authenticate requests to %q", serviceRepo),
 		Help:    "Tokens are required to authenticate to git provider various operations on git repository (e.g. enable automated creation/push to git-repo).",
 	}
-	err := survey.AskOne(prompt, &accessToken, makeAccessTokenCheck(serviceRepo))
+	err := survey.AskOne(prompt, &accessToken, makeAccessTokenCheck(serviceRepo, drivers))
 	handleError(err)
 	return accessToken
 }
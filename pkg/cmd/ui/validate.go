@@ -3,12 +3,13 @@ package ui
 import (
 	"context"
 	"fmt"
-	"net/url"
 	"os"
 	"strings"
 
 	"github.com/redhat-developer/kam/pkg/cmd/utility"
 	"github.com/redhat-developer/kam/pkg/pipelines/git"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"gopkg.in/AlecAivazis/survey.v1"
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -29,9 +30,9 @@ func makeSecretValidator() survey.Validator {
 	}
 }
 
-func makeAccessTokenCheck(serviceRepo string) survey.Validator {
+func makeAccessTokenCheck(serviceRepo string, drivers *scm.DriverResolver) survey.Validator {
 	return func(input interface{}) error {
-		return ValidateAccessToken(input, serviceRepo)
+		return ValidateAccessToken(input, serviceRepo, drivers)
 	}
 }
 
@@ -83,14 +84,15 @@ func validateSecretLength(input interface{}) error {
 	return nil
 }
 
-// ValidateAccessToken validates if the access token is correct for a particular service repo
-func ValidateAccessToken(input interface{}, serviceRepo string) error {
+// ValidateAccessToken validates if the access token is correct for a particular service repo,
+// the driver for the host of the repo is resolved by the drivers.
+func ValidateAccessToken(input interface{}, serviceRepo string, drivers *scm.DriverResolver) error {
 	if s, ok := input.(string); ok {
-		repo, err := git.NewRepository(serviceRepo, s)
+		repo, err := git.NewRepository(serviceRepo, s, drivers)
 		if err != nil {
 			return fmt.Errorf("%w. %s", err, "Check that the --private-repo-driver option is provided.")
 		}
-		parsedURL, err := url.Parse(serviceRepo)
+		parsedURL, err := giturl.Parse(serviceRepo)
 		if err != nil {
 			return fmt.Errorf("failed to parse the provided URL %q: %w", serviceRepo, err)
 		}
@@ -109,7 +111,7 @@ func ValidateAccessToken(input interface{}, serviceRepo string) error {
 
 func validateURL(input interface{}) error {
 	if u, ok := input.(string); ok {
-		p, err := url.Parse(u)
+		p, err := giturl.Parse(u)
 		if err != nil {
 			return fmt.Errorf("invalid URL, err: %v", err)
 		}
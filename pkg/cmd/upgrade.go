@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// UpgradeRecommendedCommandName the recommended command name
+	UpgradeRecommendedCommandName = "upgrade"
+)
+
+var (
+	upgradeExample = ktemplates.Examples(`
+	# Upgrade the GitOps repository to the manifest version of this kam
+	%[1]s --pipelines-folder gitops
+	`)
+
+	upgradeLongDesc = ktemplates.LongDesc(`Upgrade a GitOps repository that was generated by an earlier version of kam.
+
+	The migrations for each manifest version after the version in pipelines.yaml
+	are applied in order, updating the generated files that have changed, and the
+	resources are rebuilt from the upgraded manifest.`)
+	upgradeShortDesc = `Upgrade the GitOps repository to the latest manifest version`
+)
+
+// UpgradeParameters encapsulates the parameters for the kam upgrade command.
+type UpgradeParameters struct {
+	pipelinesFolderPath string
+}
+
+// NewUpgradeParameters bootstraps a UpgradeParameters instance.
+func NewUpgradeParameters() *UpgradeParameters {
+	return &UpgradeParameters{}
+}
+
+// Complete completes UpgradeParameters after they've been created.
+func (io *UpgradeParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the UpgradeParameters.
+func (io *UpgradeParameters) Validate() error {
+	return nil
+}
+
+// Run upgrades the manifest and reports the migrations that were applied.
+func (io *UpgradeParameters) Run() error {
+	summary, err := pipelines.UpgradeManifest(&pipelines.UpgradeOptions{
+		PipelinesFolderPath: io.pipelinesFolderPath,
+	}, ioutils.NewFilesystem())
+	if err != nil {
+		return err
+	}
+	if log.IsJSON() {
+		utility.OutputSuccess(summary)
+		return nil
+	}
+	printUpgradeSummary(os.Stdout, summary)
+	return nil
+}
+
+func printUpgradeSummary(out io.Writer, summary *pipelines.UpgradeSummary) {
+	if len(summary.Migrations) == 0 {
+		fmt.Fprintf(out, "The manifest is already at version %d, nothing to upgrade.\n", summary.ToVersion)
+		return
+	}
+	fmt.Fprintf(out, "Upgraded the manifest from version %d to %d.\n", summary.FromVersion, summary.ToVersion)
+	for _, m := range summary.Migrations {
+		fmt.Fprintf(out, "\nVersion %d: %s\n", m.Version, m.Description)
+		for _, f := range m.Files {
+			fmt.Fprintf(out, "  updated %s\n", f)
+		}
+	}
+	if len(summary.Regenerated) > 0 {
+		fmt.Fprintln(out, "\nRegenerated:")
+		for _, f := range summary.Regenerated {
+			fmt.Fprintf(out, "  %s\n", f)
+		}
+	}
+}
+
+// NewCmdUpgrade creates the upgrade command.
+func NewCmdUpgrade(name, fullName string) *cobra.Command {
+	o := NewUpgradeParameters()
+	upgradeCmd := &cobra.Command{
+		Use:     name,
+		Short:   upgradeShortDesc,
+		Long:    upgradeLongDesc,
+		Example: fmt.Sprintf(upgradeExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	upgradeCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	return upgradeCmd
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhat-developer/kam/pkg/pipelines"
+)
+
+func TestPrintUpgradeSummary(t *testing.T) {
+	summary := &pipelines.UpgradeSummary{
+		FromVersion: 1,
+		ToVersion:   2,
+		Migrations: []pipelines.MigrationResult{
+			{Version: 2, Description: "Regenerate the template", Files: []string{"config/cicd/base/template.yaml"}},
+		},
+		Regenerated: []string{"pipelines.yaml"},
+	}
+	var b bytes.Buffer
+
+	printUpgradeSummary(&b, summary)
+
+	want := `Upgraded the manifest from version 1 to 2.
+
+Version 2: Regenerate the template
+  updated config/cicd/base/template.yaml
+
+Regenerated:
+  pipelines.yaml
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("printUpgradeSummary() failed:\n%s", diff)
+	}
+}
+
+func TestPrintUpgradeSummaryWithNoMigrations(t *testing.T) {
+	var b bytes.Buffer
+
+	printUpgradeSummary(&b, &pipelines.UpgradeSummary{FromVersion: 2, ToVersion: 2})
+
+	want := "The manifest is already at version 2, nothing to upgrade.\n"
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("printUpgradeSummary() failed:\n%s", diff)
+	}
+}
@@ -2,6 +2,8 @@ package utility
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/openshift/odo/pkg/log"
@@ -107,3 +109,15 @@ func (c *Client) CheckIfPipelinesExists(ns string) error {
 func GetFullName(parentName, name string) string {
 	return parentName + " " + name
 }
+
+// OutputSuccess outputs a "successful" machine-readable output format in json
+func OutputSuccess(machineOutput interface{}) {
+	printableOutput, err := json.MarshalIndent(machineOutput, "", "	")
+
+	// If we error out... there's no way to output it (since we disable logging when using -o json)
+	if err != nil {
+		fmt.Fprintf(log.GetStderr(), "unable to marshal JSON: %s\n", err.Error())
+	} else {
+		fmt.Fprintf(log.GetStdout(), "%s\n", string(printableOutput))
+	}
+}
@@ -26,6 +26,8 @@ func TestAddGitSuffix(t *testing.T) {
 		{"trailing slash present[github]", "https://github.com/test/org/", "https://github.com/test/org.git"},
 		{"trailing slash absent[gitlab]", "https://gitlab.com/test/org.git", "https://gitlab.com/test/org.git"},
 		{"trailing slash present[gitlab]", "https://gitlab.com/test/org/", "https://gitlab.com/test/org.git"},
+		{"missing git suffix[ssh]", "git@github.com:test/org", "git@github.com:test/org.git"},
+		{"suffix already present[ssh]", "ssh://git@gitlab.com/test/org.git", "ssh://git@gitlab.com/test/org.git"},
 	}
 
 	for _, tt := range addSuffixTests {
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/validator"
+	"github.com/spf13/cobra"
+
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// ValidateRecommendedCommandName the recommended command name
+	ValidateRecommendedCommandName = "validate"
+
+	textFormat  = "text"
+	jsonFormat  = "json"
+	junitFormat = "junit"
+)
+
+var (
+	validateExample = ktemplates.Examples(`
+	# Validate the GitOps repository
+	%[1]s --pipelines-folder gitops
+
+	# Write the issues as JUnit XML for a CI system
+	%[1]s --pipelines-folder gitops --format junit > kam-validate.xml
+	`)
+
+	validateLongDesc = ktemplates.LongDesc(`Validate the GitOps repository, without a cluster.
+
+	The manifest is validated, and the kustomizations for each environment,
+	app and service, and for the configuration, are built. The objects that
+	they generate must parse as known Kubernetes, OpenShift, Tekton or Argo CD
+	types, and the namespaced objects for an environment must be in the
+	namespace of the environment.`)
+	validateShortDesc = `Validate the GitOps repository`
+)
+
+// ValidateParameters encapsulates the parameters for the kam validate command.
+type ValidateParameters struct {
+	pipelinesFolderPath string
+	format              string
+}
+
+// NewValidateParameters bootstraps a ValidateParameters instance.
+func NewValidateParameters() *ValidateParameters {
+	return &ValidateParameters{}
+}
+
+// Complete completes ValidateParameters after they've been created.
+func (io *ValidateParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the ValidateParameters.
+func (io *ValidateParameters) Validate() error {
+	switch io.format {
+	case textFormat, jsonFormat, junitFormat:
+		return nil
+	}
+	return fmt.Errorf("unsupported format %q, must be one of %s, %s or %s", io.format, textFormat, jsonFormat, junitFormat)
+}
+
+// Run validates the GitOps repository and reports the issues.
+func (io *ValidateParameters) Run() error {
+	report, err := validator.Validate(ioutils.NewFilesystem(), &validator.Options{
+		PipelinesFolderPath: io.pipelinesFolderPath,
+	})
+	if err != nil {
+		return err
+	}
+	if err := writeReport(os.Stdout, report, io.format); err != nil {
+		return err
+	}
+	if l := len(report.Issues); l > 0 {
+		return fmt.Errorf("found %d issue(s) in the GitOps repository", l)
+	}
+	return nil
+}
+
+func writeReport(out io.Writer, report *validator.Report, format string) error {
+	switch format {
+	case jsonFormat:
+		b, err := json.MarshalIndent(report, "", "	")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", b)
+		return err
+	case junitFormat:
+		return report.WriteJUnit(out)
+	}
+	if len(report.Issues) == 0 {
+		_, err := fmt.Fprintf(out, "No issues found in %d checked file(s).\n", len(report.Checked))
+		return err
+	}
+	w := tabwriter.NewWriter(out, 5, 2, 3, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tPATH\tOBJECT\tMESSAGE")
+	fmt.Fprintln(w, "=====\t====\t======\t=======")
+	for _, i := range report.Issues {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", i.Check, i.Path, i.Object, i.Message)
+	}
+	return w.Flush()
+}
+
+// NewCmdValidate creates the validate command.
+func NewCmdValidate(name, fullName string) *cobra.Command {
+	o := NewValidateParameters()
+	validateCmd := &cobra.Command{
+		Use:     name,
+		Short:   validateShortDesc,
+		Long:    validateLongDesc,
+		Example: fmt.Sprintf(validateExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	validateCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	validateCmd.Flags().StringVar(&o.format, "format", textFormat, "Format of the report, one of text, json or junit")
+	return validateCmd
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/validator"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestValidateParametersValidate(t *testing.T) {
+	formatTests := []struct {
+		format  string
+		wantErr string
+	}{
+		{"text", ""},
+		{"json", ""},
+		{"junit", ""},
+		{"yaml", `unsupported format "yaml", must be one of text, json or junit`},
+	}
+
+	for _, tt := range formatTests {
+		t.Run(tt.format, func(rt *testing.T) {
+			o := ValidateParameters{format: tt.format}
+			test.AssertErrorMatch(rt, tt.wantErr, o.Validate())
+		})
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	report := &validator.Report{
+		Checked: []string{"pipelines.yaml", "environments/dev/env/overlays"},
+		Issues: []validator.Issue{
+			{Check: validator.NamespaceCheck, Path: "environments/dev/env/overlays", Object: "Deployment/web", Message: `namespace "stage" is not the namespace "dev" of the environment`},
+		},
+	}
+	var b bytes.Buffer
+
+	if err := writeReport(&b, report, textFormat); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `CHECK       PATH                            OBJECT           MESSAGE
+=====       ====                            ======           =======
+namespace   environments/dev/env/overlays   Deployment/web   namespace "stage" is not the namespace "dev" of the environment
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("writeReport() failed:\n%s", diff)
+	}
+}
+
+func TestWriteReportWithNoIssues(t *testing.T) {
+	var b bytes.Buffer
+
+	if err := writeReport(&b, &validator.Report{Checked: []string{"pipelines.yaml"}}, textFormat); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "No issues found in 1 checked file(s).\n"
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("writeReport() failed:\n%s", diff)
+	}
+}
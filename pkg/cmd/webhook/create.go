@@ -1,7 +1,6 @@
 package webhook
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"text/tabwriter"
@@ -10,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
 	backend "github.com/redhat-developer/kam/pkg/pipelines/webhook"
 	ktemplates "k8s.io/kubectl/pkg/util/templates"
 )
@@ -35,7 +35,7 @@ func (o *createOptions) Run() error {
 
 	if id != "" {
 		if log.IsJSON() {
-			outputSuccess(id)
+			utility.OutputSuccess(id)
 		} else {
 			w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
 			fmt.Fprintln(w, "CREATED ID")
@@ -63,20 +63,3 @@ func newCmdCreate(name, fullName string) *cobra.Command {
 	o.setFlags(command)
 	return command
 }
-
-// outputSuccess outputs a "successful" machine-readable output format in json
-func outputSuccess(machineOutput interface{}) {
-	printableOutput, err := marshalJSONIndented(machineOutput)
-
-	// If we error out... there's no way to output it (since we disable logging when using -o json)
-	if err != nil {
-		fmt.Fprintf(log.GetStderr(), "unable to unmarshal JSON: %s\n", err.Error())
-	} else {
-		fmt.Fprintf(log.GetStdout(), "%s\n", string(printableOutput))
-	}
-}
-
-// marshalJSONIndented returns indented json representation of obj
-func marshalJSONIndented(obj interface{}) ([]byte, error) {
-	return json.MarshalIndent(obj, "", "	")
-}
@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
 	backend "github.com/redhat-developer/kam/pkg/pipelines/webhook"
 	ktemplates "k8s.io/kubectl/pkg/util/templates"
 )
@@ -33,7 +34,7 @@ func (o *listOptions) Run() error {
 
 	if ids != nil {
 		if log.IsJSON() {
-			outputSuccess(ids)
+			utility.OutputSuccess(ids)
 		} else {
 			w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
 			fmt.Fprintln(w, "ID")
@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	backend "github.com/redhat-developer/kam/pkg/pipelines/webhook"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const rotateSecretRecommendedCommandName = "rotate-secret"
+
+var (
+	rotateSecretExample = ktemplates.Examples(`	# Rotate the webhook secret for a service
+	%[1]s --env-name dev --service-name taxi
+
+	# Rotate the webhook secret for the GitOps repository, accepting the previous secret until it's dropped
+	%[1]s --cicd --keep-previous
+
+	# Update the webhook for a service to use the secret on the cluster
+	%[1]s --env-name dev --service-name taxi --update-hook
+
+	# Update the webhook to use the secret on the cluster, and stop accepting the previous secret
+	%[1]s --cicd --drop-previous`)
+
+	rotateSecretLongDesc = ktemplates.LongDesc(`Rotate the secret used to sign the webhooks for the GitOps repository or a service.
+
+	A new secret is written to the secrets folder, the webhook is left unchanged until the secret is on the
+	cluster. Once the new secrets and configuration are applied, run again with --update-hook to update only
+	the webhook of the GitOps repository or service to the new secret.
+
+	With --keep-previous the EventListener also accepts webhooks signed with the previous secret, and
+	the webhook is left unchanged. Once the new secrets and configuration are applied, run again with
+	--drop-previous to update the webhook to the secret on the cluster and stop accepting the previous one.`)
+)
+
+type rotateSecretOptions struct {
+	options
+	keepPrevious bool
+	dropPrevious bool
+	updateHook   bool
+}
+
+// Validate validates the rotateSecretOptions based on completed values
+func (o *rotateSecretOptions) Validate() error {
+	flags := 0
+	for _, set := range []bool{o.keepPrevious, o.dropPrevious, o.updateHook} {
+		if set {
+			flags++
+		}
+	}
+	if flags > 1 {
+		return errors.New("Only one of 'keep-previous', 'drop-previous' or 'update-hook' can be specified")
+	}
+	return o.options.Validate()
+}
+
+// Run contains the logic for the kam command
+func (o *rotateSecretOptions) Run() error {
+	if o.dropPrevious {
+		return o.dropPreviousSecret()
+	}
+	if o.updateHook {
+		ids, err := o.updateWebhook()
+		if err != nil {
+			return err
+		}
+		o.outputUpdated(ids)
+		return nil
+	}
+
+	rotateOptions := o.rotateOptions()
+	if o.keepPrevious {
+		previous, err := backend.CurrentSecret(o.pipelinesFolderPath, o.getAppServiceNames(), o.isCICD)
+		if err != nil {
+			return fmt.Errorf("unable to get the current webhook secret: %v", err)
+		}
+		rotateOptions.PreviousSecret = previous
+	}
+	if _, err := pipelines.RotateWebhookSecret(rotateOptions, ioutils.NewFilesystem()); err != nil {
+		return fmt.Errorf("unable to rotate webhook secret: %v", err)
+	}
+	log.Success("Generated a new webhook secret in the secrets folder")
+	log.Info(" WARNING: Generated secrets are not encrypted. Deploying the GitOps configuration without encrypting secrets is insecure and is not recommended.\n For more information on secret management see: https://github.com/redhat-developer/kam/tree/master/docs/journey/day1#secrets\n")
+
+	if o.keepPrevious {
+		log.Infof("The previous secret is accepted until it is dropped, apply the secrets and configuration and then run '%s --drop-previous'", o.commandLine())
+		return nil
+	}
+	log.Infof("The webhook is not updated until the new secret is on the cluster, apply the secrets and configuration and then run '%s --update-hook'", o.commandLine())
+	return nil
+}
+
+// updateWebhook updates only the webhook of the GitOps repository or the
+// service to the secret that is on the cluster.
+func (o *rotateSecretOptions) updateWebhook() ([]string, error) {
+	secret, err := backend.CurrentSecret(o.pipelinesFolderPath, o.getAppServiceNames(), o.isCICD)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the current webhook secret: %v", err)
+	}
+	ids, err := backend.UpdateSecret(o.accessToken, o.pipelinesFolderPath, o.getAppServiceNames(), o.isCICD, secret)
+	if err != nil {
+		return nil, fmt.Errorf("unable to update webhook: %v", err)
+	}
+	return ids, nil
+}
+
+func (o *rotateSecretOptions) dropPreviousSecret() error {
+	ids, err := o.updateWebhook()
+	if err != nil {
+		return err
+	}
+	if err := pipelines.DropPreviousWebhookSecret(o.rotateOptions(), ioutils.NewFilesystem()); err != nil {
+		return fmt.Errorf("unable to drop the previous webhook secret: %v", err)
+	}
+	o.outputUpdated(ids)
+	return nil
+}
+
+func (o *rotateSecretOptions) rotateOptions() *pipelines.RotateWebhookSecretOptions {
+	return &pipelines.RotateWebhookSecretOptions{
+		PipelinesFolderPath: o.pipelinesFolderPath,
+		EnvName:             o.envName,
+		ServiceName:         o.serviceName,
+		IsCICD:              o.isCICD,
+	}
+}
+
+func (o *rotateSecretOptions) commandLine() string {
+	if o.isCICD {
+		return "kam webhook rotate-secret --cicd"
+	}
+	return fmt.Sprintf("kam webhook rotate-secret --env-name %s --service-name %s", o.envName, o.serviceName)
+}
+
+func (o *rotateSecretOptions) outputUpdated(ids []string) {
+	if log.IsJSON() {
+		utility.OutputSuccess(ids)
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
+	fmt.Fprintln(w, "UPDATED ID")
+	fmt.Fprintln(w, "==========")
+	for _, id := range ids {
+		fmt.Fprintln(w, id)
+	}
+	w.Flush()
+}
+
+func newCmdRotateSecret(name, fullName string) *cobra.Command {
+	o := &rotateSecretOptions{}
+	command := &cobra.Command{
+		Use:     name,
+		Short:   "Rotate a webhook secret.",
+		Long:    rotateSecretLongDesc,
+		Example: fmt.Sprintf(rotateSecretExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	o.setFlags(command)
+	command.Flags().BoolVar(&o.keepPrevious, "keep-previous", false, "Accept webhooks signed with the previous secret until it's dropped, the webhook is not updated")
+	command.Flags().BoolVar(&o.dropPrevious, "drop-previous", false, "Update the webhook to the secret on the cluster and stop accepting the previous secret")
+	command.Flags().BoolVar(&o.updateHook, "update-hook", false, "Update only the webhook of the GitOps repository or service to the secret on the cluster, once the rotated secret is applied")
+	return command
+}
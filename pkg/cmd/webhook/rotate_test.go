@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValidateForRotateSecret(t *testing.T) {
+	testcases := []struct {
+		options *rotateSecretOptions
+		errMsg  string
+	}{
+		{
+			&rotateSecretOptions{options: options{isCICD: true}, keepPrevious: true, dropPrevious: true},
+			"Only one of 'keep-previous', 'drop-previous' or 'update-hook' can be specified",
+		},
+		{
+			&rotateSecretOptions{options: options{isCICD: true}, keepPrevious: true, updateHook: true},
+			"Only one of 'keep-previous', 'drop-previous' or 'update-hook' can be specified",
+		},
+		{
+			&rotateSecretOptions{options: options{isCICD: true}, dropPrevious: true, updateHook: true},
+			"Only one of 'keep-previous', 'drop-previous' or 'update-hook' can be specified",
+		},
+		{
+			&rotateSecretOptions{options: options{serviceName: "foo", envName: "gau"}, updateHook: true},
+			"",
+		},
+		{
+			&rotateSecretOptions{options: options{isCICD: true, serviceName: "foo"}},
+			"Only one of 'cicd' or 'env-name/service-name' can be specified",
+		},
+		{
+			&rotateSecretOptions{options: options{serviceName: "foo"}, keepPrevious: true},
+			"One of 'cicd' or 'env-name/service-name' must be specified",
+		},
+		{
+			&rotateSecretOptions{options: options{serviceName: "foo", envName: "gau"}, keepPrevious: true},
+			"",
+		},
+		{
+			&rotateSecretOptions{options: options{isCICD: true}, dropPrevious: true},
+			"",
+		},
+	}
+
+	for i, tt := range testcases {
+		t.Run(fmt.Sprintf("Test %d", i), func(t *testing.T) {
+			err := tt.options.Validate()
+
+			if err != nil && tt.errMsg == "" {
+				t.Errorf("Validate() got an unexpected error: %s", err)
+			} else {
+				if !matchError(t, tt.errMsg, err) {
+					t.Errorf("Validate() failed to match error: got %s, want %s", err, tt.errMsg)
+				}
+			}
+		})
+	}
+}
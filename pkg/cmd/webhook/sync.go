@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	backend "github.com/redhat-developer/kam/pkg/pipelines/webhook"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const syncRecommendedCommandName = "sync"
+
+var (
+	syncExample = ktemplates.Examples(`	# Show the changes needed to make the webhooks match the manifest
+	%[1]s
+
+	# Make the changes, and remove the webhooks from a repository that is no longer used
+	%[1]s --apply --remove-from https://github.com/example/old-service.git`)
+)
+
+type syncOptions struct {
+	accessToken         string
+	pipelinesFolderPath string
+	removeFrom          []string
+	updateSecrets       bool
+	apply               bool
+}
+
+// Complete completes syncOptions after they've been created
+func (o *syncOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the syncOptions based on completed values
+func (o *syncOptions) Validate() error {
+	return nil
+}
+
+// Run contains the logic for the kam command
+func (o *syncOptions) Run() error {
+	plan, err := backend.PlanSync(&backend.SyncOptions{
+		AccessToken:         o.accessToken,
+		PipelinesFolderPath: o.pipelinesFolderPath,
+		RemoveFrom:          o.removeFrom,
+		UpdateSecrets:       o.updateSecrets,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to plan webhook changes: %v", err)
+	}
+
+	if !log.IsJSON() {
+		printSyncPlan(os.Stdout, plan)
+	}
+	if o.apply {
+		if err := plan.Apply(); err != nil {
+			return err
+		}
+		if len(plan.Changes) > 0 {
+			log.Successf("Applied %d webhook changes", len(plan.Changes))
+		}
+	} else if len(plan.Changes) > 0 {
+		log.Info("Run again with --apply to make these changes")
+	}
+	if log.IsJSON() {
+		utility.OutputSuccess(plan)
+	}
+	return nil
+}
+
+func printSyncPlan(out io.Writer, plan *backend.SyncPlan) {
+	if len(plan.Changes) == 0 {
+		fmt.Fprintf(out, "All webhooks deliver to %s\n", plan.ListenerURL)
+		return
+	}
+	w := tabwriter.NewWriter(out, 5, 2, 3, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tREPOSITORY\tID\tOWNER\tREASON")
+	fmt.Fprintln(w, "======\t==========\t==\t=====\t======")
+	for _, c := range plan.Changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.Action, c.RepoURL, orNone(c.ID), orNone(c.Owner), c.Reason)
+	}
+	w.Flush()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func newCmdSync(name, fullName string) *cobra.Command {
+	o := &syncOptions{}
+	command := &cobra.Command{
+		Use:   name,
+		Short: "Synchronise webhooks with the manifest.",
+		Long: ktemplates.LongDesc(`Synchronise the webhooks in the GitOps repository and every service source repository with the manifest.
+
+		Creates missing webhooks, updates webhooks that deliver to an old listener route or with the wrong events,
+		and deletes webhooks that are no longer needed. The changes are printed, and only made if --apply is provided.
+
+		The repositories, and the webhook of each service, are recorded in webhooks.yaml in the CI/CD configuration when
+		the changes are made, so that webhooks are deleted from repositories that are later removed from the manifest,
+		and each service keeps its webhook when services share a repository.`),
+		Example: fmt.Sprintf(syncExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	command.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	command.Flags().StringVar(&o.accessToken, "git-host-access-token", "", "Access token to be used to manage Git repository webhooks on the host of the GitOps repository, the tokens for other hosts are read from the keyring or environment. Access token is encrypted and stored on local file system by keyring, will be updated/reused.")
+	command.Flags().StringArrayVar(&o.removeFrom, "remove-from", nil, "URL of a repository that is no longer in the manifest and wasn't previously synchronised, webhooks created by kam are deleted from it (can be repeated)")
+	command.Flags().BoolVar(&o.updateSecrets, "update-secrets", false, "Update every webhook with the current secret, Git hosts don't return secrets so changes can't be detected")
+	command.Flags().BoolVar(&o.apply, "apply", false, "Make the planned changes")
+	return command
+}
@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	backend "github.com/redhat-developer/kam/pkg/pipelines/webhook"
+)
+
+func TestPrintSyncPlan(t *testing.T) {
+	plan := &backend.SyncPlan{
+		ListenerURL: "https://listener.example.com",
+		Changes: []backend.SyncChange{
+			{Action: backend.ActionCreate, RepoURL: "https://github.com/example/gitops.git", Owner: "cicd", Reason: "no webhook delivers to the listener"},
+			{Action: backend.ActionDelete, RepoURL: "https://github.com/example/old.git", ID: "12", Reason: "repository is no longer in the manifest"},
+		},
+	}
+	var b bytes.Buffer
+
+	printSyncPlan(&b, plan)
+
+	want := `ACTION   REPOSITORY                              ID   OWNER   REASON
+======   ==========                              ==   =====   ======
+create   https://github.com/example/gitops.git   -    cicd    no webhook delivers to the listener
+delete   https://github.com/example/old.git      12   -       repository is no longer in the manifest
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("printSyncPlan() failed:\n%s", diff)
+	}
+}
+
+func TestPrintSyncPlanWithNoChanges(t *testing.T) {
+	var b bytes.Buffer
+
+	printSyncPlan(&b, &backend.SyncPlan{ListenerURL: "https://listener.example.com"})
+
+	want := "All webhooks deliver to https://listener.example.com\n"
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("printSyncPlan() failed:\n%s", diff)
+	}
+}
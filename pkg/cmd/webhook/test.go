@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	backend "github.com/redhat-developer/kam/pkg/pipelines/webhook"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+)
+
+const testRecommendedCommandName = "test"
+
+var (
+	testExample = ktemplates.Examples(`	# Send a test push event for the main branch of a service's source repository
+	%[1]s --env-name dev --service-name taxi
+
+	# Send a test push event for a branch of the GitOps repository
+	%[1]s --cicd --ref my-branch`)
+
+	testLongDesc = ktemplates.LongDesc(`Send a signed push event to the EventListener and report whether a PipelineRun was created.
+
+	The event is for the latest commit on the ref, and is signed with the webhook secret from the cluster
+	in the same way as the Git host would sign it.`)
+)
+
+type testOptions struct {
+	options
+	ref                   string
+	wait                  time.Duration
+	insecureSkipTLSVerify bool
+}
+
+// Validate validates the testOptions based on completed values
+func (o *testOptions) Validate() error {
+	if o.ref == "" {
+		return errors.New("'ref' must not be empty")
+	}
+	if o.wait < 0 {
+		return errors.New("'wait' must not be negative")
+	}
+	return o.options.Validate()
+}
+
+// Run contains the logic for the kam command
+func (o *testOptions) Run() error {
+	result, err := backend.SendTestEvent(&backend.TestEventOptions{
+		AccessToken:           o.accessToken,
+		PipelinesFolderPath:   o.pipelinesFolderPath,
+		ServiceName:           o.getAppServiceNames(),
+		IsCICD:                o.isCICD,
+		Ref:                   o.ref,
+		InsecureSkipTLSVerify: o.insecureSkipTLSVerify,
+		Wait:                  o.wait,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to send test event: %v", err)
+	}
+
+	if log.IsJSON() {
+		utility.OutputSuccess(result)
+	} else {
+		printDeliveryResult(result)
+	}
+
+	switch {
+	case !result.Accepted():
+		return fmt.Errorf("the event listener rejected the event with status %d", result.StatusCode)
+	case o.wait > 0 && len(result.PipelineRuns) == 0:
+		return fmt.Errorf("no PipelineRun was created for the event within %s, check the event listener logs", o.wait)
+	}
+	return nil
+}
+
+func printDeliveryResult(result *backend.DeliveryResult) {
+	w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', 0)
+	fmt.Fprintf(w, "LISTENER\t%s\n", result.ListenerURL)
+	fmt.Fprintf(w, "COMMIT\t%s\n", result.Commit)
+	fmt.Fprintf(w, "STATUS\t%d %s\n", result.StatusCode, result.Response)
+	fmt.Fprintf(w, "EVENT ID\t%s\n", orNone(result.EventID))
+	fmt.Fprintf(w, "PIPELINERUNS\t%s\n", orNone(strings.Join(result.PipelineRuns, ", ")))
+	w.Flush()
+}
+
+func newCmdTest(name, fullName string) *cobra.Command {
+	o := &testOptions{}
+	command := &cobra.Command{
+		Use:     name,
+		Short:   "Send a test event to the event listener.",
+		Long:    testLongDesc,
+		Example: fmt.Sprintf(testExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	o.setFlags(command)
+	command.Flags().StringVar(&o.ref, "ref", "main", "Branch to send the push event for")
+	command.Flags().DurationVar(&o.wait, "wait", 30*time.Second, "How long to wait for a PipelineRun to be created, 0 skips the check")
+	command.Flags().BoolVar(&o.insecureSkipTLSVerify, "insecure-skip-tls-verify", false, "Don't verify the certificate of the event listener route")
+	return command
+}
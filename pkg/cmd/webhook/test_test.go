@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestValidateForTest(t *testing.T) {
+	testcases := []struct {
+		options *testOptions
+		errMsg  string
+	}{
+		{
+			&testOptions{options: options{isCICD: true}},
+			"'ref' must not be empty",
+		},
+		{
+			&testOptions{options: options{isCICD: true}, ref: "main", wait: -time.Second},
+			"'wait' must not be negative",
+		},
+		{
+			&testOptions{options: options{serviceName: "foo"}, ref: "main"},
+			"One of 'cicd' or 'env-name/service-name' must be specified",
+		},
+		{
+			&testOptions{options: options{serviceName: "foo", envName: "gau"}, ref: "main", wait: 30 * time.Second},
+			"",
+		},
+		{
+			&testOptions{options: options{isCICD: true}, ref: "main"},
+			"",
+		},
+	}
+
+	for i, tt := range testcases {
+		t.Run(fmt.Sprintf("Test %d", i), func(t *testing.T) {
+			err := tt.options.Validate()
+
+			if err != nil && tt.errMsg == "" {
+				t.Errorf("Validate() got an unexpected error: %s", err)
+			} else {
+				if !matchError(t, tt.errMsg, err) {
+					t.Errorf("Validate() failed to match error: got %s, want %s", err, tt.errMsg)
+				}
+			}
+		})
+	}
+}
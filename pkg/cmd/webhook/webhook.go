@@ -15,16 +15,22 @@ func NewCmdWebhook(name, fullName string) *cobra.Command {
 	createCmd := newCmdCreate(createRecommendedCommandName, utility.GetFullName(fullName, createRecommendedCommandName))
 	deleteCmd := newCmdDelete(deleteRecommendedCommandName, utility.GetFullName(fullName, deleteRecommendedCommandName))
 	listCmd := newCmdList(listRecommendedCommandName, utility.GetFullName(fullName, listRecommendedCommandName))
+	syncCmd := newCmdSync(syncRecommendedCommandName, utility.GetFullName(fullName, syncRecommendedCommandName))
+	rotateSecretCmd := newCmdRotateSecret(rotateSecretRecommendedCommandName, utility.GetFullName(fullName, rotateSecretRecommendedCommandName))
+	testCmd := newCmdTest(testRecommendedCommandName, utility.GetFullName(fullName, testRecommendedCommandName))
 
 	var webhookCmd = &cobra.Command{
 		Use:   name,
 		Short: "Manage Git repository webhooks",
-		Long:  "Add/Delete/list Git repository webhooks that trigger CI/CD pipeline runs.",
-		Example: fmt.Sprintf("%s\n%s\n%s\n%s\n\n  See sub-commands individually for more examples",
+		Long:  "Add/Delete/list/sync Git repository webhooks that trigger CI/CD pipeline runs, rotate their secrets and send test events.",
+		Example: fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n%s\n%s\n\n  See sub-commands individually for more examples",
 			fullName,
 			createRecommendedCommandName,
 			deleteRecommendedCommandName,
-			listRecommendedCommandName),
+			listRecommendedCommandName,
+			syncRecommendedCommandName,
+			rotateSecretRecommendedCommandName,
+			testRecommendedCommandName),
 		Run: func(cmd *cobra.Command, args []string) {
 		},
 	}
@@ -32,6 +38,9 @@ func NewCmdWebhook(name, fullName string) *cobra.Command {
 	webhookCmd.AddCommand(createCmd)
 	webhookCmd.AddCommand(deleteCmd)
 	webhookCmd.AddCommand(listCmd)
+	webhookCmd.AddCommand(syncCmd)
+	webhookCmd.AddCommand(rotateSecretCmd)
+	webhookCmd.AddCommand(testCmd)
 
 	webhookCmd.Annotations = map[string]string{"command": "main"}
 	return webhookCmd
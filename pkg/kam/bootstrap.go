@@ -0,0 +1,72 @@
+package kam
+
+import (
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines"
+)
+
+// BootstrapOptions configures the GitOps repository that is bootstrapped.
+type BootstrapOptions struct {
+	// GitOpsRepoURL is the URL of the GitOps repository.
+	GitOpsRepoURL string
+	// GitOpsWebhookSecret authenticates the hooks from the GitOps repository,
+	// it is generated if not provided.
+	GitOpsWebhookSecret string
+	// ServiceRepoURL is the URL of the repository of the bootstrapped service.
+	ServiceRepoURL string
+	// ServiceWebhookSecret authenticates the hooks from the service repository,
+	// it is generated if not provided.
+	ServiceWebhookSecret string
+	// Prefix is added to the names of the environments.
+	Prefix string
+	// ImageRepo is where the images that are built are pushed to, it defaults
+	// to the internal registry.
+	ImageRepo string
+	// DockerConfigJSONFilename is the path to a Docker config.json, in the
+	// filesystem, that authenticates pushes to the ImageRepo.
+	DockerConfigJSONFilename string
+	// GitHostAccessToken authenticates the pipelines with the Git host.
+	GitHostAccessToken string
+	// PrivateRepoDriver is the driver, github or gitlab, of the GitOps
+	// repository if it isn't on a well-known host.
+	PrivateRepoDriver string
+	// GitDrivers are the drivers of the other Git hosts that are not
+	// well-known, e.g. of the ServiceRepoURL, keyed by hostname.
+	GitDrivers map[string]string
+	// RBACMode is the mode used to generate the roles for the pipelines
+	// service account.
+	RBACMode string
+	// SSHPrivateKeyFilename is the path to an SSH private key, in the
+	// filesystem, that the pipelines use to clone SSH repository URLs.
+	SSHPrivateKeyFilename string
+	// SSHKnownHostsFilename is the path to the known_hosts for the hosts of
+	// the SSH repository URLs, any host key is accepted if not provided.
+	SSHKnownHostsFilename string
+}
+
+// Bootstrap generates the configuration for a new GitOps repository.
+//
+// The filesystem is only read, e.g. for the DockerConfigJSONFilename and the
+// SSHPrivateKeyFilename.
+func Bootstrap(o *BootstrapOptions, fs afero.Fs) (*Result, error) {
+	out, err := pipelines.GenerateBootstrap(&pipelines.BootstrapOptions{
+		GitOpsRepoURL:            o.GitOpsRepoURL,
+		GitOpsWebhookSecret:      o.GitOpsWebhookSecret,
+		ServiceRepoURL:           o.ServiceRepoURL,
+		ServiceWebhookSecret:     o.ServiceWebhookSecret,
+		Prefix:                   o.Prefix,
+		ImageRepo:                o.ImageRepo,
+		DockerConfigJSONFilename: o.DockerConfigJSONFilename,
+		GitHostAccessToken:       o.GitHostAccessToken,
+		PrivateRepoDriver:        o.PrivateRepoDriver,
+		GitDrivers:               o.GitDrivers,
+		RBACMode:                 o.RBACMode,
+		SSHPrivateKeyFilename:    o.SSHPrivateKeyFilename,
+		SSHKnownHostsFilename:    o.SSHKnownHostsFilename,
+	}, fs)
+	if err != nil {
+		return nil, err
+	}
+	return newResult(out), nil
+}
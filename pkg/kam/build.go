@@ -0,0 +1,26 @@
+package kam
+
+import (
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines"
+)
+
+// BuildOptions configures the GitOps repository that is built.
+type BuildOptions struct {
+	// PipelinesFolderPath is the path of the GitOps repository, that contains
+	// the pipelines.yaml, in the filesystem.
+	PipelinesFolderPath string
+}
+
+// Build generates the resources from the manifest in the GitOps repository.
+//
+// The kustomizations are merged with the kustomizations that exist in the
+// GitOps repository, so that the fields that kam doesn't generate are kept.
+func Build(o *BuildOptions, fs afero.Fs) (*Result, error) {
+	out, err := pipelines.GenerateBuild(o.PipelinesFolderPath, fs)
+	if err != nil {
+		return nil, err
+	}
+	return newResult(out), nil
+}
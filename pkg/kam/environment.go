@@ -0,0 +1,37 @@
+package kam
+
+import (
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines"
+)
+
+// AddEnvironmentOptions configures the environment that is added.
+type AddEnvironmentOptions struct {
+	// PipelinesFolderPath is the path of the GitOps repository, that contains
+	// the pipelines.yaml, in the filesystem.
+	PipelinesFolderPath string
+	// EnvName is the name of the new environment.
+	EnvName string
+	// Cluster is the API URL of the cluster that the environment is deployed
+	// to, it defaults to the cluster that ArgoCD is running in.
+	Cluster string
+	// Namespace is the namespace of the environment, it defaults to the
+	// EnvName.
+	Namespace string
+}
+
+// AddEnvironment generates the manifest with the new environment, and the
+// resources built from it.
+func AddEnvironment(o *AddEnvironmentOptions, fs afero.Fs) (*Result, error) {
+	out, err := pipelines.GenerateEnvironment(&pipelines.EnvParameters{
+		PipelinesFolderPath: o.PipelinesFolderPath,
+		EnvName:             o.EnvName,
+		Cluster:             o.Cluster,
+		Namespace:           o.Namespace,
+	}, fs)
+	if err != nil {
+		return nil, err
+	}
+	return newResult(out), nil
+}
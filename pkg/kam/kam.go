@@ -0,0 +1,55 @@
+// Package kam generates the GitOps configuration that the kam CLI generates,
+// for use as a library.
+//
+// The functions read the existing configuration from the filesystem that they
+// are given, which can be an in-memory filesystem, and return the generated
+// configuration as a Result rather than writing it. They don't log, prompt or
+// read access tokens from the keyring or the environment.
+package kam
+
+import (
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+)
+
+// UnencryptedSecretsWarning is the code of the Warning that is reported when
+// the Result includes secrets that are not encrypted.
+const UnencryptedSecretsWarning = pipelines.UnencryptedSecretsWarning
+
+// Result is the generated configuration for a GitOps repository.
+//
+// The Resources are keyed by their path relative to the GitOps repository.
+// The Secrets are not encrypted, and are keyed by their path relative to the
+// folder that contains the GitOps repository, so that they are not committed
+// to it.
+type Result struct {
+	Resources res.Resources
+	Secrets   res.Resources
+	Warnings  []Warning
+}
+
+// Warning is a problem with the generated configuration that doesn't prevent
+// it from being generated, e.g. that the secrets are not encrypted.
+type Warning struct {
+	Code    string
+	Message string
+}
+
+// Write writes the Resources of the result to the path of the GitOps
+// repository, and the Secrets to the parent folder of the path.
+func Write(fs afero.Fs, path string, r *Result) error {
+	return pipelines.WriteOutput(fs, path, &pipelines.Output{Resources: r.Resources, Secrets: r.Secrets})
+}
+
+func newResult(o *pipelines.Output) *Result {
+	r := &Result{Resources: o.Resources, Secrets: o.Secrets, Warnings: []Warning{}}
+	if r.Secrets == nil {
+		r.Secrets = res.Resources{}
+	}
+	for _, w := range o.Warnings {
+		r.Warnings = append(r.Warnings, Warning{Code: w.Code, Message: w.Message})
+	}
+	return r
+}
@@ -0,0 +1,243 @@
+package kam
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+)
+
+const (
+	testGitOpsRepo = "https://github.com/my-org/gitops.git"
+	testSvcRepo    = "https://github.com/my-org/http-api.git"
+	testGitOpsPath = "/gitops"
+)
+
+func TestBootstrap(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+
+	r, err := Bootstrap(&BootstrapOptions{
+		GitOpsRepoURL:  testGitOpsRepo,
+		ServiceRepoURL: testSvcRepo,
+	}, fs)
+	assertNoError(t, err)
+
+	m := r.Resources["pipelines.yaml"].(*config.Manifest)
+	if diff := cmp.Diff([]string{"dev", "stage"}, environmentNames(m)); diff != "" {
+		t.Fatalf("bootstrapped environments:\n%s", diff)
+	}
+	if _, ok := r.Secrets["secrets/gitops-webhook-secret.yaml"]; !ok {
+		t.Fatalf("no GitOps webhook secret in the secrets: %v", keys(r.Secrets))
+	}
+	if diff := cmp.Diff([]string{UnencryptedSecretsWarning}, warningCodes(r)); diff != "" {
+		t.Fatalf("bootstrap warnings:\n%s", diff)
+	}
+	assertEmptyFilesystem(t, fs)
+}
+
+func TestBootstrapWithMissingDockerConfig(t *testing.T) {
+	_, err := Bootstrap(&BootstrapOptions{
+		GitOpsRepoURL:            testGitOpsRepo,
+		ServiceRepoURL:           testSvcRepo,
+		ImageRepo:                "quay.io/my-org/http-api",
+		DockerConfigJSONFilename: "/config.json",
+	}, ioutils.NewMemoryFilesystem())
+
+	if err == nil {
+		t.Fatal("Bootstrap() did not fail with a missing Docker config")
+	}
+}
+
+func TestBootstrapWithSSHRepoURLAndNoPrivateKey(t *testing.T) {
+	_, err := Bootstrap(&BootstrapOptions{
+		GitOpsRepoURL:  "git@github.com:my-org/gitops.git",
+		ServiceRepoURL: testSvcRepo,
+	}, ioutils.NewMemoryFilesystem())
+
+	if err == nil {
+		t.Fatal("Bootstrap() did not fail with an SSH repository URL and no private key")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	fs := bootstrapRepository(t)
+
+	r, err := Build(&BuildOptions{PipelinesFolderPath: testGitOpsPath}, fs)
+	assertNoError(t, err)
+
+	if _, ok := r.Resources["environments/dev/env/overlays/kustomization.yaml"]; !ok {
+		t.Fatalf("no environment kustomization in the resources: %v", keys(r.Resources))
+	}
+	if diff := cmp.Diff(res.Resources{}, r.Secrets); diff != "" {
+		t.Fatalf("built secrets:\n%s", diff)
+	}
+	if diff := cmp.Diff([]Warning{}, r.Warnings); diff != "" {
+		t.Fatalf("build warnings:\n%s", diff)
+	}
+}
+
+func TestAddEnvironment(t *testing.T) {
+	fs := bootstrapRepository(t)
+	before := readManifest(t, fs)
+
+	r, err := AddEnvironment(&AddEnvironmentOptions{
+		PipelinesFolderPath: testGitOpsPath,
+		EnvName:             "prod",
+		Namespace:           "shop-prod",
+	}, fs)
+	assertNoError(t, err)
+
+	m := r.Resources["pipelines.yaml"].(*config.Manifest)
+	if diff := cmp.Diff([]string{"dev", "stage", "prod"}, environmentNames(m)); diff != "" {
+		t.Fatalf("environments:\n%s", diff)
+	}
+	if _, ok := r.Resources["environments/prod/env/base/prod-environment.yaml"]; !ok {
+		t.Fatalf("no namespace for the new environment in the resources: %v", keys(r.Resources))
+	}
+	if diff := cmp.Diff(before, readManifest(t, fs)); diff != "" {
+		t.Fatalf("AddEnvironment() changed the manifest in the filesystem:\n%s", diff)
+	}
+}
+
+func TestAddEnvironmentWithExistingEnvironment(t *testing.T) {
+	fs := bootstrapRepository(t)
+
+	_, err := AddEnvironment(&AddEnvironmentOptions{
+		PipelinesFolderPath: testGitOpsPath,
+		EnvName:             "dev",
+	}, fs)
+
+	if err == nil || err.Error() != "environment dev already exists" {
+		t.Fatalf("AddEnvironment() got error %v", err)
+	}
+}
+
+func TestAddService(t *testing.T) {
+	fs := bootstrapRepository(t)
+	before := readManifest(t, fs)
+
+	r, err := AddService(&AddServiceOptions{
+		PipelinesFolderPath: testGitOpsPath,
+		EnvName:             "stage",
+		AppName:             "app-shop",
+		ServiceName:         "web",
+		GitRepoURL:          "https://github.com/my-org/web.git",
+		Image:               "quay.io/my-org/web:latest",
+		Port:                8080,
+	}, fs)
+	assertNoError(t, err)
+
+	m := r.Resources["pipelines.yaml"].(*config.Manifest)
+	if svc := m.GetEnvironment("stage").Apps[0].Services[0]; svc.Name != "web" || svc.SourceURL != "https://github.com/my-org/web.git" {
+		t.Fatalf("service was not added to the manifest, got %#v", svc)
+	}
+	for _, path := range []string{
+		"environments/stage/apps/app-shop/services/web/base/config/100-deployment.yaml",
+		"config/cicd/base/05-bindings/stage-app-shop-web-binding.yaml",
+	} {
+		if _, ok := r.Resources[path]; !ok {
+			t.Errorf("no %s in the resources: %v", path, keys(r.Resources))
+		}
+	}
+	k := r.Resources["config/cicd/base/kustomization.yaml"].(*res.Kustomization)
+	if !contains(k.Resources, "05-bindings/stage-app-shop-web-binding.yaml") {
+		t.Fatalf("the new binding is not in the pipelines kustomization: %v", k.Resources)
+	}
+	if _, ok := r.Secrets["secrets/webhook-secret-stage-web.yaml"]; !ok {
+		t.Fatalf("no webhook secret in the secrets: %v", keys(r.Secrets))
+	}
+	if diff := cmp.Diff(before, readManifest(t, fs)); diff != "" {
+		t.Fatalf("AddService() changed the manifest in the filesystem:\n%s", diff)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	r, err := Bootstrap(&BootstrapOptions{
+		GitOpsRepoURL:  testGitOpsRepo,
+		ServiceRepoURL: testSvcRepo,
+	}, fs)
+	assertNoError(t, err)
+
+	assertNoError(t, Write(fs, testGitOpsPath, r))
+
+	for _, path := range []string{"/gitops/pipelines.yaml", "/secrets/gitops-webhook-secret.yaml"} {
+		if exists, _ := afero.Exists(fs, path); !exists {
+			t.Errorf("%s was not written", path)
+		}
+	}
+}
+
+func bootstrapRepository(t *testing.T) afero.Fs {
+	t.Helper()
+	fs := ioutils.NewMemoryFilesystem()
+	r, err := Bootstrap(&BootstrapOptions{
+		GitOpsRepoURL:  testGitOpsRepo,
+		ServiceRepoURL: "https://github.com/my-org/shop.git",
+	}, fs)
+	assertNoError(t, err)
+	assertNoError(t, Write(fs, testGitOpsPath, r))
+	return fs
+}
+
+func readManifest(t *testing.T, fs afero.Fs) string {
+	t.Helper()
+	b, err := afero.ReadFile(fs, "/gitops/pipelines.yaml")
+	assertNoError(t, err)
+	return string(b)
+}
+
+func assertEmptyFilesystem(t *testing.T, fs afero.Fs) {
+	t.Helper()
+	files, err := afero.ReadDir(fs, "/")
+	assertNoError(t, err)
+	if len(files) != 0 {
+		t.Fatalf("files were written to the filesystem: %v", files)
+	}
+}
+
+func environmentNames(m *config.Manifest) []string {
+	names := []string{}
+	for _, env := range m.Environments {
+		names = append(names, env.Name)
+	}
+	return names
+}
+
+func warningCodes(r *Result) []string {
+	codes := []string{}
+	for _, w := range r.Warnings {
+		codes = append(codes, w.Code)
+	}
+	return codes
+}
+
+func keys(r res.Resources) []string {
+	names := []string{}
+	for k := range r {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contains(items []string, s string) bool {
+	for _, v := range items {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
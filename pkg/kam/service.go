@@ -0,0 +1,63 @@
+package kam
+
+import (
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines"
+)
+
+// AddServiceOptions configures the service that is added.
+type AddServiceOptions struct {
+	// PipelinesFolderPath is the path of the GitOps repository, that contains
+	// the pipelines.yaml, in the filesystem.
+	PipelinesFolderPath string
+	EnvName             string
+	AppName             string
+	ServiceName         string
+	// GitRepoURL is the URL of the repository of the service source, the CI
+	// pipelines are only configured if it's provided.
+	GitRepoURL string
+	// WebhookSecret authenticates the hooks from the GitRepoURL, it is
+	// generated if not provided.
+	WebhookSecret string
+	// ImageRepo is where the images that are built are pushed to, it defaults
+	// to the internal registry.
+	ImageRepo string
+
+	// These configure the Deployment, Service and Route that are generated for
+	// the service, nothing is generated if the Image is not provided.
+	Image         string
+	Port          int
+	Replicas      int
+	Env           []string // Environment variables of the form KEY=VAL.
+	CPU           string
+	Memory        string
+	ReadinessPath string
+	Expose        bool
+}
+
+// AddService generates the manifest with the new service, and the resources
+// built from it.
+func AddService(o *AddServiceOptions, fs afero.Fs) (*Result, error) {
+	out, err := pipelines.GenerateService(&pipelines.AddServiceOptions{
+		PipelinesFolderPath: o.PipelinesFolderPath,
+		EnvName:             o.EnvName,
+		AppName:             o.AppName,
+		ServiceName:         o.ServiceName,
+		GitRepoURL:          o.GitRepoURL,
+		WebhookSecret:       o.WebhookSecret,
+		ImageRepo:           o.ImageRepo,
+		Image:               o.Image,
+		Port:                o.Port,
+		Replicas:            o.Replicas,
+		Env:                 o.Env,
+		CPU:                 o.CPU,
+		Memory:              o.Memory,
+		ReadinessPath:       o.ReadinessPath,
+		Expose:              o.Expose,
+	}, fs)
+	if err != nil {
+		return nil, err
+	}
+	return newResult(out), nil
+}
@@ -2,11 +2,12 @@ package accesstoken
 
 import (
 	"fmt"
-	"net/url"
 	"os"
 	"strings"
 
 	"github.com/zalando/go-keyring"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 )
 
 // KeyringServiceName refers to service name used to set the accesstoken in the keyring
@@ -29,13 +30,14 @@ func GetAccessToken(gitRepoURL string) (string, error) {
 	return accessToken, nil
 }
 
-// HostFromURL extracts the hostname from the url passed
+// HostFromURL extracts the hostname from the url passed, SSH URLs e.g.
+// git@github.com:org/repo.git use the token for the host.
 func HostFromURL(s string) (string, error) {
-	p, err := url.Parse(s)
+	host, err := giturl.Host(s)
 	if err != nil {
 		return "", err
 	}
-	return strings.ToLower(p.Host), nil
+	return strings.ToLower(host), nil
 }
 
 //SetSecret sets the secret in the keyring
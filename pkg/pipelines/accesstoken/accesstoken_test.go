@@ -24,6 +24,8 @@ func TestSetAccessToken(t *testing.T) {
 		{"set the gitlab access Token in keyring", "https://gitlab.com/example/service.git", "registry/username/repo", "test123", "gitlab.com", "test123"},
 		{"overwrite gitlab access token in keyring with same secret", "https://gitlab.com/example/service.git", "registry/username/repo", "test345", "gitlab.com", "test345"},
 		{"overwrite gitlab access token in keyring with same secret", "https://gitlab.com/example/service.git", "registry/username/repo", "abc123", "gitlab.com", "abc123"},
+		{"set the github access token in keyring with SSH URL", "git@github.com:example/service.git", "registry/username/repo", "ssh123", "github.com", "ssh123"},
+		{"set the gitlab access token in keyring with SSH URL", "ssh://git@gitlab.com:2222/example/service.git", "registry/username/repo", "ssh345", "gitlab.com", "ssh345"},
 	}
 
 	for _, tt := range optionTests {
@@ -63,9 +63,12 @@ const (
 	ArgoCDNamespace = "openshift-gitops"
 	// ArgoCDManagedByLabel is needed to identify the namespace managed by Argo CD
 	ArgoCDManagedByLabel = "argocd.argoproj.io/managed-by"
-	defaultServer        = "https://kubernetes.default.svc"
-	defaultProject       = "default"
-	argoCDSAName         = "openshift-gitops-argocd-application-controller"
+	// RootApplicationName is the name of the Application that manages the
+	// Argo CD configuration.
+	RootApplicationName = "argo-app"
+	defaultServer       = "https://kubernetes.default.svc"
+	defaultProject      = "default"
+	argoCDSAName        = "openshift-gitops-argocd-application-controller"
 )
 
 // Build creates and returns a set of resources to be used for the ArgoCD
@@ -81,12 +84,12 @@ func Build(argoNS, repoURL string, m *config.Manifest) (res.Resources, error) {
 	}
 
 	files := make(res.Resources)
-	eb := &argocdBuilder{repoURL: repoURL, files: files, argoCDConfig: argoCDConfig, argoNS: argoNS}
+	eb := &argocdBuilder{repoURL: repoURL, files: files, argoCDConfig: argoCDConfig, argoNS: argoNS, layout: m.GetLayout()}
 	err := m.Walk(eb)
 	if err != nil {
 		return nil, err
 	}
-	err = argoCDConfigResources(m.Config, m.GitOpsURL, eb.files)
+	err = argoCDConfigResources(m.GetLayout(), m.Config, m.GitOpsURL, eb.files)
 	if err != nil {
 		return nil, err
 	}
@@ -98,24 +101,25 @@ type argocdBuilder struct {
 	argoCDConfig *config.ArgoCDConfig
 	files        res.Resources
 	argoNS       string
+	layout       *config.Layout
 }
 
 func (b *argocdBuilder) Application(env *config.Environment, app *config.Application) error {
-	basePath := filepath.ToSlash(filepath.Join(filepath.Join(config.PathForArgoCD())))
+	basePath := filepath.ToSlash(b.layout.PathForArgoCD())
 	argoFiles := res.Resources{}
 	filename := filepath.ToSlash(filepath.Join(basePath, env.Name+"-"+app.Name+"-app.yaml"))
 
 	argoFiles[filename] = makeApplication(app, env.Name+"-"+app.Name, b.argoNS,
 		defaultProject,
-		env.Name,
+		env.GetNamespace(),
 		clusterForEnv(env),
-		makeAppSource(env, app, b.repoURL))
+		makeAppSource(b.layout, env, app, b.repoURL))
 	b.files = res.Merge(argoFiles, b.files)
 	return nil
 }
 
 func (b *argocdBuilder) Environment(env *config.Environment) error {
-	basePath := filepath.ToSlash(filepath.Join(filepath.Join(config.PathForArgoCD())))
+	basePath := filepath.ToSlash(b.layout.PathForArgoCD())
 	argoFiles := res.Resources{}
 	filename := filepath.ToSlash(filepath.Join(basePath, env.Name+"-env-app.yaml"))
 
@@ -123,27 +127,27 @@ func (b *argocdBuilder) Environment(env *config.Environment) error {
 		nil,
 		env.Name+"-env", b.argoNS,
 		defaultProject,
-		env.Name,
+		env.GetNamespace(),
 		clusterForEnv(env),
-		makeEnvSource(env, b.repoURL))
+		makeEnvSource(b.layout, env, b.repoURL))
 	b.files = res.Merge(argoFiles, b.files)
 	return nil
 }
 
-func argoCDConfigResources(cfg *config.Config, repoURL string, files res.Resources) error {
+func argoCDConfigResources(layout *config.Layout, cfg *config.Config, repoURL string, files res.Resources) error {
 	if cfg.ArgoCD.Namespace == "" {
 		return nil
 	}
-	basePath := filepath.ToSlash(filepath.Join(config.PathForArgoCD()))
+	basePath := filepath.ToSlash(layout.PathForArgoCD())
 	filename := filepath.ToSlash(filepath.Join(basePath, "kustomization.yaml"))
 	files[filepath.ToSlash(filepath.Join(basePath, "argo-app.yaml"))] =
-		ignoreDifferences(makeApplication(nil, "argo-app", cfg.ArgoCD.Namespace,
+		ignoreDifferences(makeApplication(nil, RootApplicationName, cfg.ArgoCD.Namespace,
 			defaultProject, cfg.ArgoCD.Namespace, defaultServer,
 			&argoappv1.ApplicationSource{RepoURL: repoURL, Path: basePath}))
 	if cfg.Pipelines != nil {
 		files[filepath.ToSlash(filepath.Join(basePath, "cicd-app.yaml"))] = ignoreDifferences(
 			makeApplication(nil, "cicd-app", cfg.ArgoCD.Namespace, defaultProject, cfg.Pipelines.Name, defaultServer,
-				&argoappv1.ApplicationSource{RepoURL: repoURL, Path: filepath.ToSlash(filepath.Join(config.PathForPipelines(cfg.Pipelines), "overlays"))}))
+				&argoappv1.ApplicationSource{RepoURL: repoURL, Path: filepath.ToSlash(filepath.Join(layout.PathForPipelines(cfg.Pipelines), "overlays"))}))
 	}
 	resourceNames := []string{}
 	for k := range files {
@@ -154,11 +158,11 @@ func argoCDConfigResources(cfg *config.Config, repoURL string, files res.Resourc
 	return nil
 }
 
-func makeAppSource(env *config.Environment, app *config.Application, repoURL string) *argoappv1.ApplicationSource {
+func makeAppSource(layout *config.Layout, env *config.Environment, app *config.Application, repoURL string) *argoappv1.ApplicationSource {
 	if app.ConfigRepo == nil {
 		return &argoappv1.ApplicationSource{
 			RepoURL: repoURL,
-			Path:    filepath.ToSlash(filepath.Join(config.PathForApplication(env, app), "overlays")),
+			Path:    filepath.ToSlash(filepath.Join(layout.PathForApplication(env, app), "overlays")),
 		}
 	}
 	return &argoappv1.ApplicationSource{
@@ -168,8 +172,8 @@ func makeAppSource(env *config.Environment, app *config.Application, repoURL str
 	}
 }
 
-func makeEnvSource(env *config.Environment, repoURL string) *argoappv1.ApplicationSource {
-	envPath := filepath.ToSlash(filepath.Join(config.PathForEnvironment(env), "env"))
+func makeEnvSource(layout *config.Layout, env *config.Environment, repoURL string) *argoappv1.ApplicationSource {
+	envPath := filepath.ToSlash(filepath.Join(layout.PathForEnvironment(env), "env"))
 	envBasePath := filepath.ToSlash(filepath.Join(envPath, "overlays"))
 	return &argoappv1.ApplicationSource{
 		RepoURL: repoURL,
@@ -213,7 +213,7 @@ func TestBuildWithRepoConfig(t *testing.T) {
 			TypeMeta:   applicationTypeMeta,
 			ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ArgoCDNamespace, "test-production-env")),
 			Spec: argoappv1.ApplicationSpec{
-				Source: *makeEnvSource(prodEnv, testRepoURL),
+				Source: *makeEnvSource(nil, prodEnv, testRepoURL),
 				Destination: argoappv1.ApplicationDestination{
 					Server:    defaultServer,
 					Namespace: "test-production",
@@ -231,7 +231,7 @@ func TestBuildWithRepoConfig(t *testing.T) {
 				}),
 			),
 			Spec: argoappv1.ApplicationSpec{
-				Source: *makeAppSource(prodEnv, prodEnv.Apps[0], testRepoURL),
+				Source: *makeAppSource(nil, prodEnv, prodEnv.Apps[0], testRepoURL),
 				Destination: argoappv1.ApplicationDestination{
 					Server:    defaultServer,
 					Namespace: "test-production",
@@ -285,7 +285,7 @@ func TestBuildAddsClusterToApp(t *testing.T) {
 				meta.NamespacedName(ArgoCDNamespace, "test-dev-env"),
 			),
 			Spec: argoappv1.ApplicationSpec{
-				Source: *makeEnvSource(testEnv, testRepoURL),
+				Source: *makeEnvSource(nil, testEnv, testRepoURL),
 				Destination: argoappv1.ApplicationDestination{
 					Server:    "not.real.cluster",
 					Namespace: "test-dev",
@@ -303,7 +303,7 @@ func TestBuildAddsClusterToApp(t *testing.T) {
 				}),
 			),
 			Spec: argoappv1.ApplicationSpec{
-				Source: *makeAppSource(testEnv, testEnv.Apps[0], testRepoURL),
+				Source: *makeAppSource(nil, testEnv, testEnv.Apps[0], testRepoURL),
 				Destination: argoappv1.ApplicationDestination{
 					Server:    "not.real.cluster",
 					Namespace: "test-dev",
@@ -327,6 +327,70 @@ func TestBuildAddsClusterToApp(t *testing.T) {
 	}
 }
 
+func TestBuildUsesEnvironmentNamespace(t *testing.T) {
+	env := &config.Environment{
+		Name:      "prod-eu",
+		Namespace: "shop",
+		Apps: []*config.Application{
+			testApp,
+		},
+	}
+	m := &config.Manifest{
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: ArgoCDNamespace},
+		},
+		Environments: []*config.Environment{env},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, filename := range []string{"config/argocd/prod-eu-env-app.yaml", "config/argocd/prod-eu-http-api-app.yaml"} {
+		app := files[filename].(*argoappv1.Application)
+		if ns := app.Spec.Destination.Namespace; ns != "shop" {
+			t.Errorf("%s destination namespace got %q, want %q", filename, ns, "shop")
+		}
+	}
+}
+
+func TestBuildUsesLayout(t *testing.T) {
+	env := &config.Environment{
+		Name:    "prod-eu",
+		Cluster: "eu",
+		Apps: []*config.Application{
+			testApp,
+		},
+	}
+	m := &config.Manifest{
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: ArgoCDNamespace},
+			Layout: &config.Layout{
+				Environment: "clusters/{{ .Cluster }}/{{ .Environment }}",
+				Config:      "clusters/config/{{ .Name }}",
+			},
+		},
+		Environments: []*config.Environment{env},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantPaths := map[string]string{
+		"clusters/config/argocd/prod-eu-env-app.yaml":      "clusters/eu/prod-eu/env/overlays",
+		"clusters/config/argocd/prod-eu-http-api-app.yaml": "clusters/eu/prod-eu/apps/http-api/overlays",
+	}
+	for filename, want := range wantPaths {
+		app := files[filename].(*argoappv1.Application)
+		if path := app.Spec.Source.Path; path != want {
+			t.Errorf("%s source path got %q, want %q", filename, path, want)
+		}
+	}
+}
+
 func TestIgnoreDifferences(t *testing.T) {
 	want := &argoappv1.Application{
 		TypeMeta:   applicationTypeMeta,
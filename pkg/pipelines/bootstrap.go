@@ -1,16 +1,14 @@
 package pipelines
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
-	"net/url"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 
 	"github.com/mitchellh/go-homedir"
-	"github.com/openshift/odo/pkg/log"
 	"github.com/spf13/afero"
 	corev1 "k8s.io/api/core/v1"
 	v1rbac "k8s.io/api/rbac/v1"
@@ -19,8 +17,8 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/deployment"
-	"github.com/redhat-developer/kam/pkg/pipelines/dryrun"
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/imagerepo"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
@@ -33,31 +31,39 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
 	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
-	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 )
 
 const (
 	// Kustomize constants for kustomization.yaml
 	Kustomize = "kustomization.yaml"
-
-	namespacesPath        = "01-namespaces/cicd-environment.yaml"
-	rolesPath             = "02-rolebindings/pipeline-service-role.yaml"
-	rolebindingsPath      = "02-rolebindings/pipeline-service-rolebinding.yaml"
-	serviceAccountPath    = "02-rolebindings/pipeline-service-account.yaml"
-	gitopsTasksPath       = "03-tasks/deploy-from-source-task.yaml"
-	commitStatusTaskPath  = "03-tasks/set-commit-status-task.yaml"
-	ciPipelinesPath       = "04-pipelines/ci-dryrun-from-push-pipeline.yaml"
-	appCiPipelinesPath    = "04-pipelines/app-ci-pipeline.yaml"
-	pushTemplatePath      = "06-templates/ci-dryrun-from-push-template.yaml"
-	appCIPushTemplatePath = "06-templates/app-ci-build-from-push-template.yaml"
-	eventListenerPath     = "07-eventlisteners/cicd-event-listener.yaml"
-	routePath             = "08-routes/gitops-webhook-event-listener.yaml"
+	// ServiceAccountPath is where the pipeline ServiceAccount is written,
+	// relative to the CI/CD base directory.
+	ServiceAccountPath = "02-rolebindings/pipeline-service-account.yaml"
+
+	namespacesPath                   = "01-namespaces/cicd-environment.yaml"
+	rolesPath                        = "02-rolebindings/pipeline-service-role.yaml"
+	rolebindingsPath                 = "02-rolebindings/pipeline-service-rolebinding.yaml"
+	argoCDRolePath                   = "02-rolebindings/argocd-role.yaml"
+	argoCDRolebindingPath            = "02-rolebindings/argocd-rolebinding.yaml"
+	namespacesClusterRolePath        = "02-rolebindings/namespaces-clusterrole.yaml"
+	namespacesClusterRoleBindingPath = "02-rolebindings/namespaces-clusterrolebinding.yaml"
+	gitopsTasksPath                  = "03-tasks/deploy-from-source-task.yaml"
+	commitStatusTaskPath             = "03-tasks/set-commit-status-task.yaml"
+	ciPipelinesPath                  = "04-pipelines/ci-dryrun-from-push-pipeline.yaml"
+	appCiPipelinesPath               = "04-pipelines/app-ci-pipeline.yaml"
+	pushTemplatePath                 = "06-templates/ci-dryrun-from-push-template.yaml"
+	appCIPushTemplatePath            = "06-templates/app-ci-build-from-push-template.yaml"
+	eventListenerPath                = "07-eventlisteners/cicd-event-listener.yaml"
+	routePath                        = "08-routes/gitops-webhook-event-listener.yaml"
 
 	dockerSecretName = "regcred"
 
 	authTokenSecretName = "git-host-access-token"
 	basicAuthTokenName  = "git-host-basic-auth-token"
+	sshAuthSecretName   = "git-host-ssh-auth"
+
+	sshHostAnnotationPrefix = "tekton.dev/git-"
 
 	saName              = "pipeline"
 	roleBindingName     = "pipelines-service-role-binding"
@@ -66,7 +72,6 @@ const (
 	pipelinesFile     = "pipelines.yaml"
 	bootstrapImage    = "nginxinc/nginx-unprivileged:latest"
 	appCITemplateName = "app-ci-template"
-	version           = 1
 )
 
 // BootstrapOptions is a struct that provides the optional flags
@@ -75,15 +80,19 @@ type BootstrapOptions struct {
 	GitOpsWebhookSecret      string // This is the secret for authenticating hooks from your GitOps repo.
 	Prefix                   string
 	DockerConfigJSONFilename string
-	ImageRepo                string // This is where built images are pushed to.
-	OutputPath               string // Where to write the bootstrapped files to?
-	GitHostAccessToken       string // The auth token to use to access repositories.
-	Overwrite                bool   // This allows to overwrite if there is an existing gitops repository
-	ServiceRepoURL           string // This is the full URL to your GitHub repository for your app source.
-	SaveTokenKeyRing         bool   // If true, the access-token will be saved in the keyring
-	ServiceWebhookSecret     string // This is the secret for authenticating hooks from your app source.
-	PrivateRepoDriver        string // Records the type of the GitOpsRepoURL driver if not a well-known host.
-	PushToGit                bool   // If true, gitops repository is pushed to remote git repository.
+	ImageRepo                string            // This is where built images are pushed to.
+	OutputPath               string            // Where to write the bootstrapped files to?
+	GitHostAccessToken       string            // The auth token to use to access repositories.
+	Overwrite                bool              // This allows to overwrite if there is an existing gitops repository
+	ServiceRepoURL           string            // This is the full URL to your GitHub repository for your app source.
+	SaveTokenKeyRing         bool              // If true, the access-token will be saved in the keyring
+	ServiceWebhookSecret     string            // This is the secret for authenticating hooks from your app source.
+	PrivateRepoDriver        string            // Records the type of the GitOpsRepoURL driver if not a well-known host.
+	GitDrivers               map[string]string // Records the drivers for other Git hosts that are not well-known, keyed by hostname.
+	PushToGit                bool              // If true, gitops repository is pushed to remote git repository.
+	RBACMode                 string            // The mode used to generate the roles for the pipelines service account.
+	SSHPrivateKeyFilename    string            // The SSH private key used by the pipelines to clone SSH repository URLs.
+	SSHKnownHostsFilename    string            // The known_hosts for the hosts of the SSH repository URLs, required with SSH repository URLs.
 }
 
 // PolicyRules to be bound to service account
@@ -124,34 +133,76 @@ func Bootstrap(o *BootstrapOptions, appFs afero.Fs) error {
 	if err != nil {
 		return err
 	}
-	err = maybeMakeHookSecrets(o)
+	out, err := GenerateBootstrap(o, appFs)
 	if err != nil {
 		return err
 	}
+	return WriteOutput(appFs, o.OutputPath, out)
+}
 
-	bootstrapped, otherResources, err := bootstrapResources(o, appFs)
+// GenerateBootstrap generates the GitOps configuration for the options,
+// without writing it.
+//
+// Webhook secrets that are not provided are generated, and recorded in the
+// options, as is the default image repository.
+func GenerateBootstrap(o *BootstrapOptions, appFs afero.Fs) (*Output, error) {
+	// The pipelines can't clone SSH repository URLs without the key, and
+	// without the known hosts any host key would be accepted.
+	if giturl.IsSSH(o.GitOpsRepoURL) || giturl.IsSSH(o.ServiceRepoURL) {
+		if o.SSHPrivateKeyFilename == "" {
+			return nil, errors.New("an SSH private key is required to clone SSH repository URLs")
+		}
+		if o.SSHKnownHostsFilename == "" {
+			return nil, errors.New("SSH known hosts are required to verify the hosts of SSH repository URLs")
+		}
+	}
+	err := maybeMakeHookSecrets(o)
 	if err != nil {
-		return fmt.Errorf("failed to bootstrap resources: %v", err)
+		return nil, err
 	}
 
-	m := bootstrapped[pipelinesFile].(*config.Manifest)
-	built, err := buildResources(appFs, m)
+	bootstrapped, otherResources, err := bootstrapResources(o, appFs)
 	if err != nil {
-		return fmt.Errorf("failed to build resources: %v", err)
+		return nil, fmt.Errorf("failed to bootstrap resources: %v", err)
 	}
 
-	bootstrapped = res.Merge(built, bootstrapped)
-	log.Successf("Created dev, stage and CICD environments")
-	_, err = yaml.WriteResources(appFs, o.OutputPath, bootstrapped)
+	m := bootstrapped[pipelinesFile].(*config.Manifest)
+	built, err := buildResources(appFs, m, o.OutputPath)
 	if err != nil {
-		return fmt.Errorf("failed to write resources: %w", err)
+		return nil, fmt.Errorf("failed to build resources: %v", err)
 	}
-	_, err = yaml.WriteResources(appFs, filepath.Join(o.OutputPath, ".."), otherResources)
+	// The namespaces that are bootstrapped, e.g. the namespace of the internal
+	// registry, are not in the output path yet.
+	namespacesRBAC, err := buildNamespacesRBAC(appFs, m, o.OutputPath, bootstrapped)
 	if err != nil {
-		return fmt.Errorf("failed to write resources: %w", err)
+		return nil, fmt.Errorf("failed to build resources: %v", err)
 	}
+	built = res.Merge(namespacesRBAC, built)
+	resources := res.Merge(built, bootstrapped)
+	resources[bootstrapKustomizationPath(m)] = bootstrapKustomization(m, bootstrapped, built)
+	return newOutput(resources, otherResources), nil
+}
 
-	return nil
+// bootstrapKustomization returns the kustomization for the base of the
+// pipelines, with the files that are bootstrapped and the files that are
+// built, e.g. the push bindings for the Git providers of the services.
+//
+// The files that already exist in the output path are not included, as they
+// are replaced.
+func bootstrapKustomization(m *config.Manifest, bootstrapped, built res.Resources) res.Kustomization {
+	path := bootstrapKustomizationPath(m)
+	base := strings.TrimSuffix(path, Kustomize)
+	k := bootstrapped[path].(res.Kustomization)
+	for filename := range built {
+		if strings.HasPrefix(filename, base) && filename != path {
+			k.AddResources(strings.TrimPrefix(filename, base))
+		}
+	}
+	return k
+}
+
+func bootstrapKustomizationPath(m *config.Manifest) string {
+	return filepath.ToSlash(filepath.Join(config.PathForPipelines(m.GetPipelinesConfig()), "base", Kustomize))
 }
 
 func maybeMakeHookSecrets(o *BootstrapOptions) error {
@@ -172,9 +223,41 @@ func maybeMakeHookSecrets(o *BootstrapOptions) error {
 	return nil
 }
 
+// DriverResolver returns the resolver for the drivers of the Git hosts, with
+// the GitDrivers, and the PrivateRepoDriver for the host of the
+// GitOpsRepoURL, if it's provided.
+func (o *BootstrapOptions) DriverResolver() (*scm.DriverResolver, error) {
+	drivers, err := o.gitDrivers()
+	if err != nil {
+		return nil, err
+	}
+	return scm.NewDriverResolver(drivers), nil
+}
+
+// gitDrivers returns the drivers for the Git hosts that are not well-known,
+// keyed by hostname.
+func (o *BootstrapOptions) gitDrivers() (map[string]string, error) {
+	drivers := map[string]string{}
+	for k, v := range o.GitDrivers {
+		drivers[k] = v
+	}
+	if o.PrivateRepoDriver != "" {
+		host, err := scm.HostnameFromURL(o.GitOpsRepoURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname from URL %q: %w", o.GitOpsRepoURL, err)
+		}
+		drivers[host] = o.PrivateRepoDriver
+	}
+	return drivers, nil
+}
+
 func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res.Resources, error) {
 	ns := namespaces.NamesWithPrefix(o.Prefix)
-	appRepo, err := scm.NewRepository(o.ServiceRepoURL)
+	drivers, err := o.DriverResolver()
+	if err != nil {
+		return nil, nil, err
+	}
+	appRepo, err := scm.NewRepository(o.ServiceRepoURL, drivers)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -191,18 +274,7 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 		return nil, nil, err
 	}
 
-	log.Success("Options used:")
-	log.Progressf("  Service repository: %s", o.ServiceRepoURL)
-	log.Progressf("  GitOps repository: %s", o.GitOpsRepoURL)
-	log.Progressf("  Image repository: %s", imageRepo)
-	if !isInternalRegistry {
-		log.Progressf("  Path to config.json: %s", o.DockerConfigJSONFilename)
-	}
-	log.Progressf("  Output folder: %s", o.OutputPath)
-	log.Progressf("  Overwrite output folder: %s", strconv.FormatBool(o.Overwrite))
-	log.Progressf("")
-
-	gitOpsRepo, err := scm.NewRepository(o.GitOpsRepoURL)
+	gitOpsRepo, err := scm.NewRepository(o.GitOpsRepoURL, drivers)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -218,13 +290,14 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 	if err != nil {
 		return nil, nil, err
 	}
-	if o.PrivateRepoDriver != "" {
-		host, err := scm.HostnameFromURL(o.GitOpsRepoURL)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to get hostname from URL %q: %w", o.GitOpsRepoURL, err)
-		}
-		configEnv.Git = &config.GitConfig{Drivers: map[string]string{host: o.PrivateRepoDriver}}
+	gitDrivers, err := o.gitDrivers()
+	if err != nil {
+		return nil, nil, err
 	}
+	if len(gitDrivers) > 0 {
+		configEnv.Git = &config.GitConfig{Drivers: gitDrivers}
+	}
+	configEnv.Pipelines.RBACMode = manifestRBACMode(o.RBACMode)
 	m := createManifest(gitOpsRepo.URL(), configEnv, envs...)
 
 	devEnv := m.GetEnvironment(ns["dev"])
@@ -254,7 +327,7 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 	}
 	secretFilename := filepath.ToSlash(filepath.Join("secrets", secretName+".yaml"))
 	otherResources[secretFilename] = opaqueSecret
-	bindingName, imageRepoBindingFilename, svcImageBinding := createSvcImageBinding(cfg, devEnv, appName, serviceName, imageRepo, !isInternalRegistry)
+	bindingName, imageRepoBindingFilename, svcImageBinding := createSvcImageBinding(m.GetLayout(), cfg, devEnv, appName, serviceName, imageRepo, !isInternalRegistry)
 	bootstrapped = res.Merge(svcImageBinding, bootstrapped)
 
 	kustomizePath := filepath.Join(config.PathForPipelines(cfg), "base", "kustomization.yaml")
@@ -264,7 +337,7 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 	}
 	if isInternalRegistry {
 		filenames, resources, err := imagerepo.CreateInternalRegistryResources(
-			cfg, roles.CreateServiceAccount(meta.NamespacedName(cfg.Name, saName)),
+			m.GetLayout(), cfg, roles.CreateServiceAccount(meta.NamespacedName(cfg.Name, saName)),
 			imageRepo, o.GitOpsRepoURL)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get resources for internal image repository: %v", err)
@@ -292,10 +365,8 @@ func bootstrapServiceDeployment(dev *config.Environment, app *config.Application
 	svc := dev.Apps[0].Services[0]
 	svcBase := filepath.Join(config.PathForService(app, dev, svc.Name), "base", "config")
 	resources := res.Resources{}
-	// TODO: This should change if we add Namespace to Environment.
-	// We'd need to create the resources in the namespace _of_ the Environment.
-	resources[filepath.Join(svcBase, "100-deployment.yaml")] = deployment.Create(app.Name, dev.Name, svc.Name, bootstrapImage, deployment.ContainerPort(8080))
-	containerSvc := createBootstrapService(app.Name, dev.Name, svc.Name)
+	resources[filepath.Join(svcBase, "100-deployment.yaml")] = deployment.Create(app.Name, dev.GetNamespace(), svc.Name, bootstrapImage, deployment.ContainerPort(8080))
+	containerSvc := createBootstrapService(app.Name, dev.GetNamespace(), svc.Name)
 	resources[filepath.Join(svcBase, "200-service.yaml")] = containerSvc
 	r, err := routes.NewFromService(containerSvc)
 	if err != nil {
@@ -369,7 +440,7 @@ func applicationFromRepo(repoURL string, service *config.Service) (*config.Appli
 }
 
 func repoFromURL(raw string) (string, error) {
-	u, err := url.Parse(raw)
+	u, err := giturl.Parse(raw)
 	if err != nil {
 		return "", err
 	}
@@ -378,7 +449,7 @@ func repoFromURL(raw string) (string, error) {
 }
 
 func orgRepoFromURL(raw string) (string, error) {
-	u, err := url.Parse(raw)
+	u, err := giturl.Parse(raw)
 	if err != nil {
 		return "", err
 	}
@@ -388,6 +459,10 @@ func orgRepoFromURL(raw string) (string, error) {
 }
 
 func createBootstrapService(appName, ns, name string) *corev1.Service {
+	return createServiceForPort(appName, ns, name, 8080)
+}
+
+func createServiceForPort(appName, ns, name string, port int32) *corev1.Service {
 	svc := &corev1.Service{
 		TypeMeta:   meta.TypeMeta("Service", "v1"),
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, name)),
@@ -396,8 +471,8 @@ func createBootstrapService(appName, ns, name string) *corev1.Service {
 				{
 					Name:       "http",
 					Protocol:   corev1.ProtocolTCP,
-					Port:       8080,
-					TargetPort: intstr.FromInt(8080)},
+					Port:       port,
+					TargetPort: intstr.FromInt(int(port))},
 			},
 		},
 	}
@@ -457,7 +532,7 @@ func errorIfFileExists(appFs afero.Fs, outputPath string, files ...string) error
 }
 
 func createInitialFiles(fs afero.Fs, repo scm.Repository, o *BootstrapOptions) (res.Resources, res.Resources, error) {
-	cicd := &config.PipelinesConfig{Name: o.Prefix + "cicd"}
+	cicd := &config.PipelinesConfig{Name: o.Prefix + "cicd", RBACMode: manifestRBACMode(o.RBACMode)}
 	pipelineConfig := &config.Config{Pipelines: cicd}
 	manifest := createManifest(repo.URL(), pipelineConfig)
 	initialFiles := res.Resources{
@@ -502,6 +577,71 @@ func createDockerSecret(fs afero.Fs, dockerConfigJSONFilename, secretNS string)
 	return dockerSecret, nil
 }
 
+// createSSHAuthSecret creates a secret that allows the pipelines to clone the
+// SSH repository URLs, it's annotated with the hosts of the URLs so that
+// Tekton uses it for those hosts.
+func createSSHAuthSecret(fs afero.Fs, o *BootstrapOptions, secretNS string) (*corev1.Secret, error) {
+	hosts, err := sshHosts(o.GitOpsRepoURL, o.ServiceRepoURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, errors.New("failed to generate SSH auth secret: the SSH private key is only used with SSH repository URLs")
+	}
+
+	privateKey, err := readSSHFile(fs, o.SSHPrivateKeyFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH private key: %w", err)
+	}
+	knownHosts, err := readSSHFile(fs, o.SSHKnownHostsFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH known hosts: %w", err)
+	}
+	return secrets.CreateUnsealedSSHAuthSecret(meta.NamespacedName(secretNS, sshAuthSecretName),
+		bytes.NewReader(privateKey), bytes.NewReader(knownHosts), meta.AddAnnotations(sshHostAnnotations(hosts)))
+}
+
+// sshHostAnnotations returns the annotations that tell Tekton to use the
+// secret for the hosts.
+func sshHostAnnotations(hosts []string) map[string]string {
+	annotations := map[string]string{}
+	for i, host := range hosts {
+		annotations[fmt.Sprintf("%s%d", sshHostAnnotationPrefix, i)] = host
+	}
+	return annotations
+}
+
+func readSSHFile(fs afero.Fs, filename string) ([]byte, error) {
+	path, err := homedir.Expand(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate path to file: %v", err)
+	}
+	return afero.ReadFile(fs, path)
+}
+
+// sshHosts returns the sorted, distinct hosts of the SSH repository URLs,
+// including the SSH port if it's not the default.
+func sshHosts(repoURLs ...string) ([]string, error) {
+	seen := map[string]bool{}
+	hosts := []string{}
+	for _, v := range repoURLs {
+		if !giturl.IsSSH(v) {
+			continue
+		}
+		u, err := giturl.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q: %w", v, err)
+		}
+		host := strings.ToLower(u.Host)
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts, nil
+}
+
 // createCICDResources creates resources for OpenShift pipelines.
 func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *config.PipelinesConfig, o *BootstrapOptions) (res.Resources, res.Resources, error) {
 	cicdNamespace := pipelineConfig.Name
@@ -516,7 +656,6 @@ func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *confi
 	unEncSecretPath := filepath.Join("secrets", "gitops-webhook-secret.yaml")
 	otherOutputs[unEncSecretPath] = githubSecret
 	outputs[namespacesPath] = namespaces.Create(cicdNamespace, o.GitOpsRepoURL)
-	outputs[rolesPath] = roles.CreateClusterRole(meta.NamespacedName("", roles.ClusterRoleName), Rules)
 
 	sa := roles.CreateServiceAccount(meta.NamespacedName(cicdNamespace, saName))
 
@@ -527,9 +666,8 @@ func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *confi
 		}
 		if dockerUnencryptedSecret != nil {
 			otherOutputs[filepath.Join("secrets", "docker-config.yaml")] = dockerUnencryptedSecret
-			log.Success("Authentication tokens for docker config not sealed in secrets")
 		}
-		outputs[serviceAccountPath] = roles.AddSecretToSA(sa, dockerSecretName)
+		outputs[ServiceAccountPath] = roles.AddSecretToSA(sa, dockerSecretName)
 	}
 
 	if o.GitHostAccessToken != "" {
@@ -539,19 +677,44 @@ func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *confi
 		}
 	}
 
-	outputs[rolebindingsPath] = roles.CreateClusterRoleBinding(meta.NamespacedName("", roleBindingName), sa, "ClusterRole", roles.ClusterRoleName)
-	script, err := dryrun.MakeScript("kubectl", cicdNamespace)
+	if o.SSHPrivateKeyFilename != "" {
+		sshSecret, err := createSSHAuthSecret(fs, o, cicdNamespace)
+		if err != nil {
+			return nil, nil, err
+		}
+		otherOutputs[filepath.Join("secrets", sshAuthSecretName+".yaml")] = sshSecret
+		outputs[ServiceAccountPath] = roles.AddSecretToSA(sa, sshSecret.Name)
+	}
+
+	if pipelineConfig.IsMinimalRBAC() {
+		outputs[rolesPath] = roles.CreateRole(meta.NamespacedName(cicdNamespace, roles.RoleName), roles.CICDRules)
+		outputs[rolebindingsPath] = roles.CreateRoleBinding(meta.NamespacedName(cicdNamespace, roleBindingName), sa, "Role", roles.RoleName)
+		outputs[argoCDRolePath] = roles.CreateRole(meta.NamespacedName(argocd.ArgoCDNamespace, roles.RoleName), roles.ArgoCDRules)
+		outputs[argoCDRolebindingPath] = roles.CreateRoleBinding(meta.NamespacedName(argocd.ArgoCDNamespace, roleBindingName), sa, "Role", roles.RoleName)
+	} else {
+		outputs[rolesPath] = roles.CreateClusterRole(meta.NamespacedName("", roles.ClusterRoleName), Rules)
+		outputs[rolebindingsPath] = roles.CreateClusterRoleBinding(meta.NamespacedName("", roleBindingName), sa, "ClusterRole", roles.ClusterRoleName)
+	}
+	// The commit status task identifies the provider, github or gitlab, from
+	// the host of the repository, so it doesn't support the hosts that are
+	// not well-known, e.g. enterprise repositories.
+	// Enable it by default once the status task supports these hosts.
+	gitOpsStatus, err := scm.IsWellKnownHost(o.GitOpsRepoURL)
 	if err != nil {
-		return nil, otherOutputs, err
+		return nil, nil, err
+	}
+	serviceStatus := gitOpsStatus
+	if o.ServiceRepoURL != "" {
+		serviceStatus, err = scm.IsWellKnownHost(o.ServiceRepoURL)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
-	outputs[gitopsTasksPath] = tasks.CreateDeployFromSourceTask(cicdNamespace, script)
-	// currently, the commit status task doesn't support enterprise repository
-	// enable it by default once the status task supports enterprise repository
-	if o.PrivateRepoDriver == "" {
+	if gitOpsStatus || serviceStatus {
 		outputs[commitStatusTaskPath] = tasks.CreateCommitStatusTask(cicdNamespace)
 	}
-	outputs[ciPipelinesPath] = removeCommitStatus(pipelines.CreateCIPipeline(meta.NamespacedName(cicdNamespace, "ci-dryrun-from-push-pipeline"), cicdNamespace), o.PrivateRepoDriver)
-	outputs[appCiPipelinesPath] = removeCommitStatus(pipelines.CreateAppCIPipeline(meta.NamespacedName(cicdNamespace, "app-ci-pipeline")), o.PrivateRepoDriver)
+	outputs[ciPipelinesPath] = removeCommitStatus(pipelines.CreateCIPipeline(meta.NamespacedName(cicdNamespace, "ci-dryrun-from-push-pipeline"), cicdNamespace), !gitOpsStatus)
+	outputs[appCiPipelinesPath] = removeCommitStatus(pipelines.CreateAppCIPipeline(meta.NamespacedName(cicdNamespace, "app-ci-pipeline")), !serviceStatus)
 	pushBinding, pushBindingName := repo.CreatePushBinding(cicdNamespace)
 	outputs[filepath.ToSlash(filepath.Join("05-bindings", pushBindingName+".yaml"))] = pushBinding
 	outputs[pushTemplatePath] = triggers.CreateCIDryRunTemplate(cicdNamespace, saName)
@@ -560,29 +723,36 @@ func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *confi
 	if err != nil {
 		return nil, nil, err
 	}
-	log.Success("OpenShift Pipelines resources created")
 	route, err := eventlisteners.GenerateRoute(cicdNamespace)
 	if err != nil {
 		return nil, nil, err
 	}
 	outputs[routePath] = route
-	log.Success("Openshift Route for EventListener created")
 	return outputs, otherOutputs, nil
 }
 
+// manifestRBACMode returns the RBAC mode to record in the manifest, the
+// default mode is not recorded.
+func manifestRBACMode(mode string) string {
+	if mode == config.DefaultRBACMode {
+		return ""
+	}
+	return mode
+}
+
 func createManifest(gitOpsRepoURL string, configEnv *config.Config, envs ...*config.Environment) *config.Manifest {
 	return &config.Manifest{
 		GitOpsURL:    gitOpsRepoURL,
 		Environments: envs,
 		Config:       configEnv,
-		Version:      version,
+		Version:      config.LatestVersion,
 	}
 }
 
 func getCICDKustomization(files []string) res.Resources {
 	return res.Resources{
 		"overlays/kustomization.yaml": res.Kustomization{
-			Bases: []string{"../base"},
+			Resources: []string{"../base"},
 		},
 		"base/kustomization.yaml": res.Kustomization{
 			Resources: files,
@@ -618,7 +788,7 @@ func generateSecrets(outputs res.Resources, otherOutputs res.Resources, sa *core
 		return fmt.Errorf("failed to generate Secret: %w", err)
 	}
 	otherOutputs[filepath.Join("secrets", "git-host-access-token.yaml")] = tokenSecret
-	outputs[serviceAccountPath] = roles.AddSecretToSA(sa, tokenSecret.Name)
+	outputs[ServiceAccountPath] = roles.AddSecretToSA(sa, tokenSecret.Name)
 
 	// basic auth token is used by Tekton pipelines to access private repositories
 	secretTargetHost, err := repoURL(o.ServiceRepoURL)
@@ -630,13 +800,13 @@ func generateSecrets(outputs res.Resources, otherOutputs res.Resources, sa *core
 		"tekton.dev/git-0": secretTargetHost,
 	}))
 	otherOutputs[filepath.Join("secrets", basicAuthTokenName+".yaml")] = basicAuthSecret
-	outputs[serviceAccountPath] = roles.AddSecretToSA(sa, basicAuthSecret.Name)
+	outputs[ServiceAccountPath] = roles.AddSecretToSA(sa, basicAuthSecret.Name)
 	return nil
 }
 
 // remove the commit status task and it's dependency
-func removeCommitStatus(pipeline *pipelinev1.Pipeline, driver string) *pipelinev1.Pipeline {
-	if driver == "" {
+func removeCommitStatus(pipeline *pipelinev1.Pipeline, remove bool) *pipelinev1.Pipeline {
+	if !remove {
 		return pipeline
 	}
 	pipeline.Spec.Finally = nil
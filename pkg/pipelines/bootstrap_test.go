@@ -12,12 +12,17 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/pipelines"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/roles"
 	"github.com/redhat-developer/kam/pkg/pipelines/routes"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+	"github.com/redhat-developer/kam/test"
+	"github.com/spf13/afero"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	v1rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -78,7 +83,7 @@ func TestBootstrapManifest(t *testing.T) {
 		"environments/tst-dev/apps/app-http-api/services/http-api/base/config/kustomization.yaml": &res.Kustomization{
 			Resources: []string{"100-deployment.yaml", "200-service.yaml", "300-route.yaml"}},
 		pipelinesFile: &config.Manifest{
-			Version:   version,
+			Version:   config.LatestVersion,
 			GitOpsURL: "https://github.com/my-org/gitops.git",
 			Environments: []*config.Environment{
 				{
@@ -134,7 +139,6 @@ func TestBootstrapManifest(t *testing.T) {
 		"02-rolebindings/pipeline-service-account.yaml",
 		"02-rolebindings/pipeline-service-role.yaml",
 		"02-rolebindings/pipeline-service-rolebinding.yaml",
-		"03-tasks/deploy-from-source-task.yaml",
 		"03-tasks/set-commit-status-task.yaml",
 		"04-pipelines/app-ci-pipeline.yaml",
 		"04-pipelines/ci-dryrun-from-push-pipeline.yaml",
@@ -151,6 +155,257 @@ func TestBootstrapManifest(t *testing.T) {
 	}
 }
 
+func TestBootstrapManifestWithMinimalRBAC(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		RBACMode:             config.MinimalRBACMode,
+	}
+	r, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	m := r[pipelinesFile].(*config.Manifest)
+	if mode := m.GetPipelinesConfig().RBACMode; mode != config.MinimalRBACMode {
+		t.Fatalf("manifest RBAC mode got %q, want %q", mode, config.MinimalRBACMode)
+	}
+	sa := roles.CreateServiceAccount(meta.NamespacedName("tst-cicd", saName))
+	want := res.Resources{
+		"config/tst-cicd/base/02-rolebindings/pipeline-service-role.yaml":        roles.CreateRole(meta.NamespacedName("tst-cicd", roles.RoleName), roles.CICDRules),
+		"config/tst-cicd/base/02-rolebindings/pipeline-service-rolebinding.yaml": roles.CreateRoleBinding(meta.NamespacedName("tst-cicd", roleBindingName), sa, "Role", roles.RoleName),
+		"config/tst-cicd/base/02-rolebindings/argocd-role.yaml":                  roles.CreateRole(meta.NamespacedName(argocd.ArgoCDNamespace, roles.RoleName), roles.ArgoCDRules),
+		"config/tst-cicd/base/02-rolebindings/argocd-rolebinding.yaml":           roles.CreateRoleBinding(meta.NamespacedName(argocd.ArgoCDNamespace, roleBindingName), sa, "Role", roles.RoleName),
+	}
+	if diff := cmp.Diff(want, r, cmpopts.IgnoreMapEntries(func(k string, v interface{}) bool {
+		_, ok := want[k]
+		return !ok
+	})); diff != "" {
+		t.Fatalf("bootstrapped resources:\n%s", diff)
+	}
+	for k, v := range r {
+		switch v.(type) {
+		case *v1rbac.ClusterRole, *v1rbac.ClusterRoleBinding:
+			t.Errorf("bootstrapped cluster-scoped RBAC resource %s", k)
+		}
+	}
+}
+
+func TestGenerateBootstrapWithMinimalRBACLimitsNamespaces(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		RBACMode:             config.MinimalRBACMode,
+		OutputPath:           "/gitops",
+	}
+	out, err := GenerateBootstrap(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	sa := roles.CreateServiceAccount(meta.NamespacedName("tst-cicd", saName))
+	want := res.Resources{
+		"config/tst-cicd/base/02-rolebindings/namespaces-clusterrole.yaml": roles.CreateClusterRole(meta.NamespacedName("", roles.NamespacesClusterRoleName),
+			roles.NamespacesRules([]string{"image", "tst-cicd", "tst-dev", "tst-stage"})),
+		"config/tst-cicd/base/02-rolebindings/namespaces-clusterrolebinding.yaml": roles.CreateClusterRoleBinding(meta.NamespacedName("", roles.NamespacesClusterRoleBindingName),
+			sa, "ClusterRole", roles.NamespacesClusterRoleName),
+	}
+	if diff := cmp.Diff(want, out.Resources, cmpopts.IgnoreMapEntries(func(k string, v interface{}) bool {
+		_, ok := want[k]
+		return !ok
+	})); diff != "" {
+		t.Fatalf("bootstrapped resources:\n%s", diff)
+	}
+	k := out.Resources["config/tst-cicd/base/kustomization.yaml"].(res.Kustomization)
+	resources := map[string]bool{}
+	for _, r := range k.Resources {
+		resources[r] = true
+	}
+	for _, filename := range []string{"02-rolebindings/namespaces-clusterrole.yaml", "02-rolebindings/namespaces-clusterrolebinding.yaml"} {
+		if !resources[filename] {
+			t.Errorf("kustomization does not include %s", filename)
+		}
+	}
+}
+
+func TestGenerateBootstrapWithMixedGitProviders(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        "https://github.example.com/my-org/gitops.git",
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       "https://gitlab.example.com/my-org/http-api.git",
+		ServiceWebhookSecret: "456",
+		PrivateRepoDriver:    "github",
+		GitDrivers:           map[string]string{"gitlab.example.com": "gitlab"},
+	}
+	out, err := GenerateBootstrap(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	m := out.Resources[pipelinesFile].(*config.Manifest)
+	want := map[string]string{"github.example.com": "github", "gitlab.example.com": "gitlab"}
+	if diff := cmp.Diff(want, m.Config.Git.Drivers); diff != "" {
+		t.Fatalf("manifest drivers:\n%s", diff)
+	}
+	if _, ok := out.Resources["config/tst-cicd/base/05-bindings/gitlab-push-binding.yaml"]; !ok {
+		t.Fatal("no push binding for the GitLab service was generated")
+	}
+	k := out.Resources["config/tst-cicd/base/kustomization.yaml"].(res.Kustomization)
+	for _, v := range []string{"05-bindings/github-push-binding.yaml", "05-bindings/gitlab-push-binding.yaml"} {
+		if !containsString(k.Resources, v) {
+			t.Errorf("%s is not in the pipelines kustomization: %v", v, k.Resources)
+		}
+	}
+	if _, ok := out.Resources["config/tst-cicd/base/"+commitStatusTaskPath]; ok {
+		t.Fatal("the commit status task was generated for hosts that are not well-known")
+	}
+}
+
+func TestGenerateBootstrapWithCommitStatusForWellKnownHosts(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       "https://gitlab.example.com/my-org/http-api.git",
+		ServiceWebhookSecret: "456",
+		GitDrivers:           map[string]string{"gitlab.example.com": "gitlab"},
+	}
+	out, err := GenerateBootstrap(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	if _, ok := out.Resources["config/tst-cicd/base/"+commitStatusTaskPath]; !ok {
+		t.Fatal("the commit status task was not generated for the well-known GitOps host")
+	}
+	statusTests := []struct {
+		path string
+		want bool
+	}{
+		{ciPipelinesPath, true},
+		{appCiPipelinesPath, false},
+	}
+	for _, tt := range statusTests {
+		pipeline := out.Resources["config/tst-cicd/base/"+tt.path].(*pipelinev1.Pipeline)
+		if got := hasPipelineTask(pipeline, pipelines.PendingCommitStatusTask); got != tt.want {
+			t.Errorf("%s has the commit status task: got %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func hasPipelineTask(p *pipelinev1.Pipeline, name string) bool {
+	for _, task := range p.Spec.Tasks {
+		if task.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGenerateBootstrapWithSSHRepoURLs(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, afero.WriteFile(fakeFs, "/home/user/.ssh/id_rsa", []byte("test-private-key"), 0600))
+	fatalIfError(t, afero.WriteFile(fakeFs, "/home/user/.ssh/known_hosts", []byte("github.com ssh-ed25519 AAAA"), 0600))
+	params := &BootstrapOptions{
+		Prefix:                "tst-",
+		GitOpsRepoURL:         "git@github.com:my-org/gitops.git",
+		ImageRepo:             "image/repo",
+		GitOpsWebhookSecret:   "123",
+		GitHostAccessToken:    "test-token",
+		ServiceRepoURL:        "ssh://git@gitlab.example.com:2222/my-org/http-api.git",
+		ServiceWebhookSecret:  "456",
+		GitDrivers:            map[string]string{"gitlab.example.com": "gitlab"},
+		SSHPrivateKeyFilename: "/home/user/.ssh/id_rsa",
+		SSHKnownHostsFilename: "/home/user/.ssh/known_hosts",
+	}
+	out, err := GenerateBootstrap(params, fakeFs)
+	fatalIfError(t, err)
+
+	m := out.Resources[pipelinesFile].(*config.Manifest)
+	if m.GitOpsURL != params.GitOpsRepoURL {
+		t.Fatalf("manifest GitOps URL got %s, want %s", m.GitOpsURL, params.GitOpsRepoURL)
+	}
+	svc := m.GetEnvironment("tst-dev").Apps[0].Services[0]
+	if svc.Name != "http-api" || svc.SourceURL != params.ServiceRepoURL {
+		t.Fatalf("bootstrapped service got %s from %s", svc.Name, svc.SourceURL)
+	}
+
+	wantSecret := &corev1.Secret{
+		TypeMeta: meta.TypeMeta("Secret", "v1"),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("tst-cicd", sshAuthSecretName), meta.AddAnnotations(map[string]string{
+			"tekton.dev/git-0": "github.com",
+			"tekton.dev/git-1": "gitlab.example.com:2222",
+		})),
+		Type: corev1.SecretTypeSSHAuth,
+		Data: map[string][]byte{
+			"ssh-privatekey": []byte("test-private-key"),
+			"known_hosts":    []byte("github.com ssh-ed25519 AAAA"),
+		},
+	}
+	if diff := cmp.Diff(wantSecret, out.Secrets[filepath.Join("secrets", sshAuthSecretName+".yaml")]); diff != "" {
+		t.Fatalf("SSH auth secret:\n%s", diff)
+	}
+	sa := out.Resources["config/tst-cicd/base/"+ServiceAccountPath].(*corev1.ServiceAccount)
+	wantSecrets := []corev1.ObjectReference{{Name: authTokenSecretName}, {Name: basicAuthTokenName}, {Name: sshAuthSecretName}}
+	if diff := cmp.Diff(wantSecrets, sa.Secrets); diff != "" {
+		t.Fatalf("service account secrets:\n%s", diff)
+	}
+	k := out.Resources["config/tst-cicd/base/kustomization.yaml"].(res.Kustomization)
+	for _, v := range []string{"05-bindings/github-ssh-push-binding.yaml", "05-bindings/gitlab-ssh-push-binding.yaml"} {
+		if !containsString(k.Resources, v) {
+			t.Errorf("%s is not in the pipelines kustomization: %v", v, k.Resources)
+		}
+	}
+}
+
+func TestGenerateBootstrapWithSSHPrivateKeyAndHTTPSRepoURLs(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	fatalIfError(t, afero.WriteFile(fakeFs, "/home/user/.ssh/id_rsa", []byte("test-private-key"), 0600))
+	params := &BootstrapOptions{
+		Prefix:                "tst-",
+		GitOpsRepoURL:         testGitOpsRepo,
+		ImageRepo:             "image/repo",
+		GitOpsWebhookSecret:   "123",
+		ServiceRepoURL:        testSvcRepo,
+		ServiceWebhookSecret:  "456",
+		SSHPrivateKeyFilename: "/home/user/.ssh/id_rsa",
+	}
+	_, err := GenerateBootstrap(params, fakeFs)
+	test.AssertErrorMatch(t, "only used with SSH repository URLs", err)
+}
+
+func TestGenerateBootstrapWithSSHRepoURLsAndNoPrivateKey(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       "git@github.com:my-org/http-api.git",
+		ServiceWebhookSecret: "456",
+	}
+	_, err := GenerateBootstrap(params, ioutils.NewMemoryFilesystem())
+	test.AssertErrorMatch(t, "an SSH private key is required to clone SSH repository URLs", err)
+}
+
+func TestGenerateBootstrapWithSSHRepoURLsAndNoKnownHosts(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:                "tst-",
+		GitOpsRepoURL:         "git@github.com:my-org/gitops.git",
+		ImageRepo:             "image/repo",
+		GitOpsWebhookSecret:   "123",
+		ServiceRepoURL:        testSvcRepo,
+		ServiceWebhookSecret:  "456",
+		SSHPrivateKeyFilename: "/home/user/.ssh/id_rsa",
+	}
+	_, err := GenerateBootstrap(params, ioutils.NewMemoryFilesystem())
+	test.AssertErrorMatch(t, "SSH known hosts are required to verify the hosts of SSH repository URLs", err)
+}
+
 func TestBootstrapCreatesRepository(t *testing.T) {
 	params := &BootstrapOptions{
 		Prefix:               "tst-",
@@ -167,10 +422,12 @@ func TestBootstrapCreatesRepository(t *testing.T) {
 
 func TestOrgRepoFromURL(t *testing.T) {
 	want := "my-org/gitops"
-	got, err := orgRepoFromURL(testGitOpsRepo)
-	fatalIfError(t, err)
-	if got != want {
-		t.Fatalf("orgRepFromURL(%s) got %s, want %s", testGitOpsRepo, got, want)
+	for _, v := range []string{testGitOpsRepo, "git@github.com:my-org/gitops.git", "ssh://git@github.com/my-org/gitops.git"} {
+		got, err := orgRepoFromURL(v)
+		fatalIfError(t, err)
+		if got != want {
+			t.Fatalf("orgRepFromURL(%s) got %s, want %s", v, got, want)
+		}
 	}
 }
 
@@ -249,7 +506,7 @@ func TestCreateManifest(t *testing.T) {
 	want := &config.Manifest{
 		GitOpsURL: repoURL,
 		Config:    Config,
-		Version:   version,
+		Version:   config.LatestVersion,
 	}
 	got := createManifest(repoURL, Config)
 	if diff := cmp.Diff(want, got); diff != "" {
@@ -264,7 +521,7 @@ func TestInitialFiles(t *testing.T) {
 	o := BootstrapOptions{Prefix: prefix, GitOpsWebhookSecret: gitOpsWebhook, DockerConfigJSONFilename: ""}
 
 	fakeFs := ioutils.NewMemoryFilesystem()
-	repo, err := scm.NewRepository(gitOpsURL)
+	repo, err := scm.NewRepository(gitOpsURL, nil)
 	assertNoError(t, err)
 	got, _, err := createInitialFiles(fakeFs, repo, &o)
 	assertNoError(t, err)
@@ -289,7 +546,7 @@ func TestInitialFiles(t *testing.T) {
 func TestGetCICDKustomization(t *testing.T) {
 	want := res.Resources{
 		"overlays/kustomization.yaml": res.Kustomization{
-			Bases: []string{"../base"},
+			Resources: []string{"../base"},
 		},
 		"base/kustomization.yaml": res.Kustomization{
 			Resources: []string{"resource1", "resource2"},
@@ -321,7 +578,7 @@ func TestGenerateSecrets(t *testing.T) {
 		),
 		Secrets: []corev1.ObjectReference{{Name: authTokenSecretName}, {Name: basicAuthTokenName}},
 	}
-	if diff := cmp.Diff(wantSA, outputs[serviceAccountPath]); diff != "" {
+	if diff := cmp.Diff(wantSA, outputs[ServiceAccountPath]); diff != "" {
 		t.Fatalf("generatedSecrets failed to update the ServiceAccount:\n%s", diff)
 	}
 }
@@ -1,28 +1,52 @@
 package pipelines
 
 import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
 	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/dryrun"
 	"github.com/redhat-developer/kam/pkg/pipelines/environments"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/roles"
+	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
 	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 // BuildParameters is a struct that provides flags for the BuildResources
 // command.
+//
+// If Out is provided, the resources are written to it as a single stream in
+// the Format, instead of to the OutputPath, without the kustomizations.
 type BuildParameters struct {
 	PipelinesFolderPath string
 	OutputPath          string
+	Out                 io.Writer
+	Format              string
 }
 
 // BuildResources builds all resources from a pipelines.
 func BuildResources(o *BuildParameters, appFs afero.Fs) error {
+	if o.Out != nil {
+		out, err := GenerateBuild(o.PipelinesFolderPath, appFs)
+		if err != nil {
+			return err
+		}
+		return yaml.WriteResourceStream(o.Out, withoutKustomizations(out.Resources), o.Format)
+	}
 	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
 	if err != nil {
 		return err
 	}
-	resources, err := buildResources(appFs, m)
+	resources, err := buildResources(appFs, m, o.OutputPath)
 	if err != nil {
 		return err
 	}
@@ -30,7 +54,40 @@ func BuildResources(o *BuildParameters, appFs afero.Fs) error {
 	return err
 }
 
-func buildResources(fs afero.Fs, m *config.Manifest) (res.Resources, error) {
+// withoutKustomizations returns the resources that are not kustomizations, the
+// kustomizations have no apiVersion or kind, so they can't be applied.
+func withoutKustomizations(resources res.Resources) res.Resources {
+	objects := res.Resources{}
+	for k, v := range resources {
+		switch v.(type) {
+		case res.Kustomization, *res.Kustomization:
+			continue
+		}
+		objects[k] = v
+	}
+	return objects
+}
+
+// GenerateBuild builds the resources from the manifest in the pipelines
+// folder, without writing them.
+//
+// The kustomizations are merged with the kustomizations that already exist in
+// the pipelines folder.
+func GenerateBuild(pipelinesFolderPath string, appFs afero.Fs) (*Output, error) {
+	m, err := config.LoadManifest(appFs, pipelinesFolderPath)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := buildResources(appFs, m, pipelinesFolderPath)
+	if err != nil {
+		return nil, err
+	}
+	return newOutput(resources, nil), nil
+}
+
+// buildResources builds the resources from the manifest, the kustomizations
+// are merged with any existing kustomizations in the path.
+func buildResources(fs afero.Fs, m *config.Manifest, path string) (res.Resources, error) {
 	resources := res.Resources{}
 
 	argoCD := m.GetArgoCDConfig()
@@ -49,6 +106,20 @@ func buildResources(fs afero.Fs, m *config.Manifest) (res.Resources, error) {
 	if err != nil {
 		return nil, err
 	}
+	namespacesRBAC, err := buildNamespacesRBAC(fs, m, path, envs)
+	if err != nil {
+		return nil, err
+	}
+	elFiles = res.Merge(namespacesRBAC, elFiles)
+	dryRunTask, err := buildDryRunTask(m)
+	if err != nil {
+		return nil, err
+	}
+	elFiles = res.Merge(dryRunTask, elFiles)
+	err = addPipelinesKustomization(fs, m, path, elFiles)
+	if err != nil {
+		return nil, err
+	}
 
 	resources = res.Merge(elFiles, resources)
 	argoApps, err := argocd.Build(argocd.ArgoCDNamespace, m.GitOpsURL, m)
@@ -56,5 +127,148 @@ func buildResources(fs afero.Fs, m *config.Manifest) (res.Resources, error) {
 		return nil, err
 	}
 	resources = res.Merge(argoApps, resources)
+	return preserveKustomizations(fs, path, resources)
+}
+
+// buildDryRunTask returns the Task that the CI dry-run executes, the script
+// applies the paths in the layout, so it's rebuilt with the environments and
+// applications.
+func buildDryRunTask(m *config.Manifest) (res.Resources, error) {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return res.Resources{}, nil
+	}
+	script, err := dryrun.MakeScript("kubectl", m)
+	if err != nil {
+		return nil, err
+	}
+	return res.Resources{
+		filepath.ToSlash(filepath.Join(m.GetLayout().PathForPipelines(cfg), "base", gitopsTasksPath)): tasks.CreateDeployFromSourceTask(cfg.Name, script),
+	}, nil
+}
+
+// buildNamespacesRBAC returns the ClusterRole, and the binding to the pipeline
+// ServiceAccount, that allows the CI dry-run to apply the namespaces when the
+// RBAC mode is minimal.
+//
+// The ClusterRole is limited to the namespaces of the CI/CD environment and
+// the environments, and the namespaces in the resources or in the CI/CD
+// configuration in the path, e.g. the namespace of the internal registry.
+func buildNamespacesRBAC(fs afero.Fs, m *config.Manifest, path string, resources res.Resources) (res.Resources, error) {
+	cfg := m.GetPipelinesConfig()
+	if !cfg.IsMinimalRBAC() {
+		return res.Resources{}, nil
+	}
+	names := environments.StringSet{cfg.Name: true}
+	for _, env := range m.Environments {
+		names[env.GetNamespace()] = true
+	}
+	for _, v := range resources {
+		if ns, ok := v.(*corev1.Namespace); ok {
+			names[ns.Name] = true
+		}
+	}
+	base := filepath.ToSlash(filepath.Join(m.GetLayout().PathForPipelines(cfg), "base"))
+	existing, err := readNamespaces(fs, path, filepath.Join(base, filepath.Dir(namespacesPath)))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range existing {
+		names[name] = true
+	}
+	sa := roles.CreateServiceAccount(meta.NamespacedName(cfg.Name, saName))
+	return res.Resources{
+		filepath.ToSlash(filepath.Join(base, namespacesClusterRolePath)): roles.CreateClusterRole(
+			meta.NamespacedName("", roles.NamespacesClusterRoleName), roles.NamespacesRules(names.Items())),
+		filepath.ToSlash(filepath.Join(base, namespacesClusterRoleBindingPath)): roles.CreateClusterRoleBinding(
+			meta.NamespacedName("", roles.NamespacesClusterRoleBindingName), sa, "ClusterRole", roles.NamespacesClusterRoleName),
+	}, nil
+}
+
+// readNamespaces returns the names of the Namespaces in the directory in the
+// path, there are none if there's no path.
+func readNamespaces(fs afero.Fs, path, dir string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, err
+	}
+	filenames, err := afero.Glob(fs, filepath.Join(path, dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, filename := range filenames {
+		data, err := afero.ReadFile(fs, filename)
+		if err != nil {
+			return nil, err
+		}
+		ns := &corev1.Namespace{}
+		if err := sigsyaml.Unmarshal(data, ns); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+		if ns.Kind == "Namespace" && ns.Name != "" {
+			names = append(names, ns.Name)
+		}
+	}
+	return names, nil
+}
+
+// addPipelinesKustomization adds the kustomization for the base of the
+// pipelines to the files if they include files in it, other than the event
+// listener, e.g. the push bindings for the Git providers of the services, so
+// that they are deployed with the existing files in the path.
+func addPipelinesKustomization(fs afero.Fs, m *config.Manifest, path string, files res.Resources) error {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil || path == "" {
+		return nil
+	}
+	cicdPath := m.GetLayout().PathForPipelines(cfg)
+	base := filepath.ToSlash(filepath.Join(cicdPath, "base"))
+	added := false
+	for k := range files {
+		if strings.HasPrefix(k, base+"/") && k != getEventListenerPath(cicdPath) {
+			added = true
+		}
+	}
+	if !added {
+		return nil
+	}
+	k, err := pipelinesKustomization(fs, path, base, files)
+	if err != nil {
+		return err
+	}
+	files[filepath.ToSlash(filepath.Join(base, Kustomize))] = k
+	return nil
+}
+
+// preserveKustomizations merges the generated kustomizations with the
+// kustomizations that already exist in the path, so that the fields that kam
+// doesn't generate, e.g. patches or images, are not lost on a rebuild.
+//
+// If there's no path, there are no existing kustomizations.
+func preserveKustomizations(fs afero.Fs, path string, resources res.Resources) (res.Resources, error) {
+	if path == "" {
+		return resources, nil
+	}
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, err
+	}
+	for filename, v := range resources {
+		generated, ok := v.(*res.Kustomization)
+		if !ok {
+			continue
+		}
+		existing, err := readKustomization(fs, filepath.Join(path, filename))
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			resources[filename] = res.MergeKustomization(existing, generated)
+		}
+	}
 	return resources, nil
 }
@@ -0,0 +1,293 @@
+package pipelines
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/dryrun"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+func TestBuildResourcesPreservesKustomizations(t *testing.T) {
+	fakeFs, gitopsPath := setupServiceForConfigure(t)
+	overlaysFile := filepath.Join(gitopsPath, "environments/test-dev/apps/test-app/services/web/overlays", Kustomize)
+	assertNoError(t, afero.WriteFile(fakeFs, overlaysFile, []byte(`bases:
+- ../old-base
+namespace: shop
+images:
+- name: quay.io/example/web
+  newTag: v2
+patches:
+- path: replicas.yaml
+  target:
+    kind: Deployment
+`), 0644))
+
+	err := BuildResources(&BuildParameters{PipelinesFolderPath: gitopsPath, OutputPath: gitopsPath}, fakeFs)
+	assertNoError(t, err)
+
+	want := map[string]interface{}{
+		"resources": []interface{}{"../base"},
+		"namespace": "shop",
+		"images": []interface{}{
+			map[string]interface{}{"name": "quay.io/example/web", "newTag": "v2"},
+		},
+		"patches": []interface{}{
+			map[string]interface{}{
+				"path":   "replicas.yaml",
+				"target": map[string]interface{}{"kind": "Deployment"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, mustReadFileAsMap(t, fakeFs, overlaysFile)); diff != "" {
+		t.Fatalf("overlay kustomization didn't match:\n%s", diff)
+	}
+}
+
+func TestAddServicePreservesKustomizations(t *testing.T) {
+	fakeFs, gitopsPath := setupServiceForConfigure(t)
+	envKustomization := filepath.Join(gitopsPath, "environments/test-dev/env/overlays", Kustomize)
+	assertNoError(t, afero.WriteFile(fakeFs, envKustomization, []byte(`bases:
+- ../base
+commonAnnotations:
+  owner: shop-team
+`), 0644))
+
+	err := AddService(&AddServiceOptions{
+		AppName:             "test-app",
+		EnvName:             "test-dev",
+		PipelinesFolderPath: gitopsPath,
+		ServiceName:         "api",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	want := map[string]interface{}{
+		"resources":         []interface{}{"../base"},
+		"commonAnnotations": map[string]interface{}{"owner": "shop-team"},
+	}
+	if diff := cmp.Diff(want, mustReadFileAsMap(t, fakeFs, envKustomization)); diff != "" {
+		t.Fatalf("environment kustomization didn't match:\n%s", diff)
+	}
+}
+
+func TestBuildResourcesToWriter(t *testing.T) {
+	fakeFs, gitopsPath := setupServiceForConfigure(t)
+	var b bytes.Buffer
+
+	err := BuildResources(&BuildParameters{PipelinesFolderPath: gitopsPath, OutputPath: "-", Out: &b, Format: yaml.YAMLFormat}, fakeFs)
+	assertNoError(t, err)
+
+	if exists, _ := afero.Exists(fakeFs, "-"); exists {
+		t.Fatal("resources were written to the filesystem")
+	}
+	stream := b.String()
+	path := "environments/test-dev/env/base/test-dev-environment.yaml"
+	if !strings.Contains(stream, yaml.PathAnnotation+": "+path+"\n") {
+		t.Errorf("stream has no resource for %s", path)
+	}
+	if strings.Contains(stream, Kustomize+"\n") {
+		t.Errorf("stream has kustomizations:\n%s", stream)
+	}
+}
+
+func TestBuildResourcesStreamsObjects(t *testing.T) {
+	stream := buildGoldenStream(t)
+	for i, doc := range strings.Split(string(stream), "\n---\n") {
+		obj := map[string]interface{}{}
+		assertNoError(t, sigsyaml.Unmarshal([]byte(doc), &obj))
+		if obj["apiVersion"] == nil || obj["kind"] == nil {
+			t.Errorf("document %d has no apiVersion or kind:\n%s", i, doc)
+		}
+	}
+}
+
+func TestBuildResourcesMatchesGolden(t *testing.T) {
+	stream := buildGoldenStream(t)
+	golden := filepath.Join("testdata", "golden", "resources.yaml")
+	if *updateGolden {
+		assertNoError(t, ioutil.WriteFile(golden, stream, 0644))
+	}
+	want, err := ioutil.ReadFile(golden)
+	assertNoError(t, err)
+	if diff := cmp.Diff(string(want), string(stream)); diff != "" {
+		t.Fatalf("resources don't match %s, run with -update to regenerate:\n%s", golden, diff)
+	}
+}
+
+func TestBuildResourcesIsDeterministic(t *testing.T) {
+	first := buildGoldenStream(t)
+	for i := 0; i < 10; i++ {
+		if diff := cmp.Diff(string(first), string(buildGoldenStream(t))); diff != "" {
+			t.Fatalf("build %d differed from the first build:\n%s", i, diff)
+		}
+	}
+}
+
+func TestBuildResourcesWritesFilesInOrder(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+	manifest, err := ioutil.ReadFile(filepath.Join("testdata", "golden", "pipelines.yaml"))
+	assertNoError(t, err)
+	assertNoError(t, afero.WriteFile(fakeFs, filepath.Join(gitopsPath, pipelinesFile), manifest, 0644))
+	m, err := config.LoadManifest(fakeFs, gitopsPath)
+	assertNoError(t, err)
+	resources, err := buildResources(fakeFs, m, gitopsPath)
+	assertNoError(t, err)
+
+	filenames, err := yaml.WriteResources(fakeFs, gitopsPath, resources)
+	assertNoError(t, err)
+
+	if !sort.StringsAreSorted(filenames) {
+		t.Fatalf("WriteResources() returned unsorted filenames: %v", filenames)
+	}
+}
+
+func buildGoldenStream(t *testing.T) []byte {
+	t.Helper()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+	manifest, err := ioutil.ReadFile(filepath.Join("testdata", "golden", "pipelines.yaml"))
+	assertNoError(t, err)
+	assertNoError(t, afero.WriteFile(fakeFs, filepath.Join(gitopsPath, pipelinesFile), manifest, 0644))
+
+	var b bytes.Buffer
+	err = BuildResources(&BuildParameters{PipelinesFolderPath: gitopsPath, OutputPath: "-", Out: &b, Format: yaml.YAMLFormat}, fakeFs)
+	assertNoError(t, err)
+	return b.Bytes()
+}
+
+func TestGenerateBuildWithPrivateDrivers(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+	m := &config.Manifest{
+		GitOpsURL: "https://gitlab.example.com/org/gitops.git",
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "cicd"},
+			Git:       &config.GitConfig{Drivers: map[string]string{"gitlab.example.com": "gitlab"}},
+		},
+		Environments: []*config.Environment{
+			{
+				Name: "dev",
+				Apps: []*config.Application{
+					{
+						Name: "shop",
+						Services: []*config.Service{
+							{
+								Name:      "web",
+								SourceURL: "https://gitlab.example.com/org/web.git",
+								Webhook:   &config.Webhook{Secret: &config.Secret{Name: "webhook-secret-dev-web", Namespace: "cicd"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	assertNoError(t, yaml.MarshalItemToFile(fakeFs, filepath.Join(gitopsPath, pipelinesFile), m))
+
+	out, err := GenerateBuild(gitopsPath, fakeFs)
+	assertNoError(t, err)
+
+	if _, ok := out.Resources["config/cicd/base/07-eventlisteners/cicd-event-listener.yaml"]; !ok {
+		t.Fatal("no EventListener for the private host was built")
+	}
+	if _, err := scm.GetDriverName(m.GitOpsURL, nil); err == nil {
+		t.Fatal("building the resources registered the private host globally")
+	}
+}
+
+func TestGenerateBuildAddsPushBindingsToPipelinesKustomization(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+	m := &config.Manifest{
+		GitOpsURL: "https://github.com/org/gitops.git",
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "cicd"},
+		},
+		Environments: []*config.Environment{
+			{
+				Name: "dev",
+				Apps: []*config.Application{
+					{
+						Name: "shop",
+						Services: []*config.Service{
+							{
+								Name:      "web",
+								SourceURL: "https://gitlab.com/org/web.git",
+								Webhook:   &config.Webhook{Secret: &config.Secret{Name: "webhook-secret-dev-web", Namespace: "cicd"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	assertNoError(t, yaml.MarshalItemToFile(fakeFs, filepath.Join(gitopsPath, pipelinesFile), m))
+	assertNoError(t, afero.WriteFile(fakeFs, filepath.Join(gitopsPath, "config/cicd/base/05-bindings/github-push-binding.yaml"), []byte("{}"), 0644))
+
+	out, err := GenerateBuild(gitopsPath, fakeFs)
+	assertNoError(t, err)
+
+	if _, ok := out.Resources["config/cicd/base/05-bindings/gitlab-push-binding.yaml"]; !ok {
+		t.Fatal("no push binding for the GitLab service was built")
+	}
+	want := []string{
+		"03-tasks/deploy-from-source-task.yaml",
+		"05-bindings/github-push-binding.yaml",
+		"05-bindings/gitlab-push-binding.yaml",
+		"07-eventlisteners/cicd-event-listener.yaml",
+	}
+	k := out.Resources["config/cicd/base/kustomization.yaml"].(*res.Kustomization)
+	if diff := cmp.Diff(want, k.Resources); diff != "" {
+		t.Fatalf("pipelines kustomization:\n%s", diff)
+	}
+}
+
+func TestGenerateBuildRebuildsDryRunTaskWithLayout(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+	m := &config.Manifest{
+		GitOpsURL: "https://github.com/org/gitops.git",
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "cicd"},
+			Layout: &config.Layout{
+				Environment: "clusters/{{ .Environment }}",
+				Config:      "gitops/{{ .Name }}",
+			},
+		},
+		Environments: []*config.Environment{{Name: "dev"}},
+	}
+	assertNoError(t, yaml.MarshalItemToFile(fakeFs, filepath.Join(gitopsPath, pipelinesFile), m))
+
+	out, err := GenerateBuild(gitopsPath, fakeFs)
+	assertNoError(t, err)
+
+	script, err := dryrun.MakeScript("kubectl", m)
+	assertNoError(t, err)
+	want := tasks.CreateDeployFromSourceTask("cicd", script)
+	if diff := cmp.Diff(want, out.Resources["gitops/cicd/base/03-tasks/deploy-from-source-task.yaml"]); diff != "" {
+		t.Fatalf("dry-run task:\n%s", diff)
+	}
+	for _, path := range []string{"gitops/cicd/overlays", "clusters/dev/env/overlays"} {
+		if !strings.Contains(script, fmt.Sprintf("execute %q", path)) {
+			t.Errorf("the dry-run script does not apply %s:\n%s", path, script)
+		}
+	}
+}
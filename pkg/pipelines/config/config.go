@@ -1,37 +1,57 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"sort"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 )
 
 const (
 	// PipelinesFile is the name of the pipelines manifest file
 	PipelinesFile = "pipelines.yaml"
+
+	// LatestVersion is the version of the manifest, and the files generated
+	// from it, that this version of kam writes.
+	LatestVersion = 3
+
+	// DefaultRBACMode grants the pipelines service account a ClusterRole, and
+	// the edit role in each environment.
+	DefaultRBACMode = "default"
+
+	// MinimalRBACMode grants the pipelines service account namespace-scoped
+	// Roles, limited to what the pipelines need.
+	MinimalRBACMode = "minimal"
 )
 
-// PathForService gives a repo-rooted path within a repository.
+// PathForService gives a repo-rooted path within a repository, for the
+// DefaultLayout.
 func PathForService(app *Application, env *Environment, serviceName string) string {
 	return filepath.Join(PathForApplication(env, app), "services", serviceName)
 }
 
-// PathForApplication generates a repo-rooted path within a repository.
+// PathForApplication generates a repo-rooted path within a repository, for the
+// DefaultLayout.
 func PathForApplication(env *Environment, app *Application) string {
 	return filepath.Join(PathForEnvironment(env), "apps", app.Name)
 }
 
-// PathForEnvironment gives a repo-rooted path within a repository.
+// PathForEnvironment gives a repo-rooted path within a repository, for the
+// DefaultLayout.
 func PathForEnvironment(env *Environment) string {
 	return filepath.Join("environments", env.Name)
 }
 
-// PathForPipelines returns the path only for the CICD environment.
+// PathForPipelines returns the path only for the CICD environment, for the
+// DefaultLayout.
 func PathForPipelines(pipeline *PipelinesConfig) string {
 	return filepath.Join("config", pipeline.Name)
 }
 
-// PathForArgoCD returns the path for recording ArgoCD configuration.
+// PathForArgoCD returns the path for recording ArgoCD configuration, for the
+// DefaultLayout.
 func PathForArgoCD() string {
 	return filepath.Join("config", "argocd")
 }
@@ -44,6 +64,15 @@ type Manifest struct {
 	Version      int            `json:"version,omitempty"`
 }
 
+// GetVersion returns the version of the manifest, manifests without a version
+// are the first version.
+func (m *Manifest) GetVersion() int {
+	if m.Version == 0 {
+		return 1
+	}
+	return m.Version
+}
+
 // GetEnvironment returns a named environment if it exists in the configuration.
 func (m *Manifest) GetEnvironment(n string) *Environment {
 	for _, env := range m.Environments {
@@ -101,13 +130,33 @@ func (m *Manifest) GetArgoCDConfig() *ArgoCDConfig {
 	return nil
 }
 
+// GetDriverResolver returns the resolver for the drivers of the Git hosts,
+// including the private hosts that are configured in the manifest.
+func (m *Manifest) GetDriverResolver() *scm.DriverResolver {
+	if m.Config != nil && m.Config.Git != nil {
+		return scm.NewDriverResolver(m.Config.Git.Drivers)
+	}
+	return scm.NewDriverResolver(nil)
+}
+
 // Environment is a slice of Apps, these are the named apps in the namespace.
 //
+// The Namespace is the namespace that the environment deploys to, if it's not
+// provided the namespace has the name of the environment.
+//
+// The Policies configure the quota, default limits and network isolation of
+// the namespace.
+//
+// The Components are the paths, relative to the GitOps repository, of the
+// kustomize components that are included in the overlays of the environment.
 type Environment struct {
-	Name      string         `json:"name,omitempty"`
-	Cluster   string         `json:"cluster,omitempty"`
-	Pipelines *Pipelines     `json:"pipelines,omitempty"`
-	Apps      []*Application `json:"apps,omitempty"`
+	Name       string         `json:"name,omitempty"`
+	Cluster    string         `json:"cluster,omitempty"`
+	Namespace  string         `json:"namespace,omitempty"`
+	Pipelines  *Pipelines     `json:"pipelines,omitempty"`
+	Policies   *Policies      `json:"policies,omitempty"`
+	Components []string       `json:"components,omitempty"`
+	Apps       []*Application `json:"apps,omitempty"`
 }
 
 // Config represents the configuration for non-application environments.
@@ -115,11 +164,19 @@ type Config struct {
 	Pipelines *PipelinesConfig `json:"pipelines,omitempty"`
 	ArgoCD    *ArgoCDConfig    `json:"argocd,omitempty"`
 	Git       *GitConfig       `json:"git,omitempty"`
+	Layout    *Layout          `json:"layout,omitempty"`
 }
 
 // PipelinesConfig provides configuration for the CI/CD pipelines.
+//
+// The RBACMode is the mode used to generate the roles for the pipelines
+// service account, if it's not provided the DefaultRBACMode is used.
 type PipelinesConfig struct {
-	Name string `json:"name,omitempty"`
+	Name     string `json:"name,omitempty"`
+	RBACMode string `json:"rbac_mode,omitempty"`
+	// Webhook is only needed to accept a previous secret for the GitOps
+	// repository webhook, the current secret has a fixed name.
+	Webhook *Webhook `json:"webhook,omitempty"`
 }
 
 // ArgoCDConfig provides configuration for the ArgoCD application generation.
@@ -132,6 +189,20 @@ type GitConfig struct {
 	Drivers map[string]string `json:"drivers,omitempty"`
 }
 
+// IsMinimalRBAC returns true if the pipelines service account is granted
+// namespace-scoped Roles.
+func (p *PipelinesConfig) IsMinimalRBAC() bool {
+	return p != nil && p.RBACMode == MinimalRBACMode
+}
+
+// GetNamespace returns the namespace that the environment deploys to.
+func (e *Environment) GetNamespace() string {
+	if e.Namespace != "" {
+		return e.Namespace
+	}
+	return e.Name
+}
+
 // GoString return environment name
 func (e Environment) GoString() string {
 	return e.Name
@@ -155,8 +226,12 @@ type Service struct {
 }
 
 // Webhook provides Github webhook secret for eventlisteners
+//
+// The PreviousSecret is accepted as well as the Secret while a rotated secret
+// is rolled out.
 type Webhook struct {
-	Secret *Secret `json:"secret,omitempty"`
+	Secret         *Secret `json:"secret,omitempty"`
+	PreviousSecret *Secret `json:"previous_secret,omitempty"`
 }
 
 // Secret represents a K8s secret in a namespace
@@ -194,13 +269,12 @@ type TemplateBinding struct {
 // Every App, Service and Environment is called once, and any error from the
 // handling function terminates the Walk.
 //
-// The environments are sorted using a custom sorting mechanism, that orders by
-// name, but, moves CICD environments to the bottom of the list.
+// The environments, and the apps and services within them, are visited in
+// order of their names, the manifest is not changed.
 func (m Manifest) Walk(visitor interface{}) error {
-	sort.Sort(byName(m.Environments))
-	for _, env := range m.Environments {
-		for _, app := range env.Apps {
-			for _, svc := range app.Services {
+	for _, env := range sortedEnvironments(m.Environments) {
+		for _, app := range sortedApplications(env.Apps) {
+			for _, svc := range sortedServices(app.Services) {
 				if v, ok := visitor.(ServiceVisitor); ok {
 					err := v.Service(app, env, svc)
 					if err != nil {
@@ -226,10 +300,30 @@ func (m Manifest) Walk(visitor interface{}) error {
 	return nil
 }
 
-type byName []*Environment
+// MarshalJSON implements the json.Marshaler interface, writing the
+// environments in order of their names so that the manifest is written the
+// same way regardless of the order that environments were added.
+func (m Manifest) MarshalJSON() ([]byte, error) {
+	type manifest Manifest
+	sorted := manifest(m)
+	sorted.Environments = sortedEnvironments(m.Environments)
+	return json.Marshal(sorted)
+}
+
+func sortedEnvironments(envs []*Environment) []*Environment {
+	sorted := append([]*Environment(nil), envs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+func sortedApplications(apps []*Application) []*Application {
+	sorted := append([]*Application(nil), apps...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
 
-func (a byName) Len() int      { return len(a) }
-func (a byName) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
-func (a byName) Less(i, j int) bool {
-	return a[i].Name < a[j].Name
+func sortedServices(services []*Service) []*Service {
+	sorted := append([]*Service(nil), services...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"sort"
@@ -71,6 +72,70 @@ func TestManifestWalk(t *testing.T) {
 		t.Fatalf("tree files: %s", diff)
 	}
 }
+
+func TestManifestWalkOrder(t *testing.T) {
+	m := &Manifest{
+		Environments: []*Environment{
+			{
+				Name: "staging",
+				Apps: []*Application{
+					{
+						Name: "my-app-2",
+						Services: []*Service{
+							{Name: "service-b"},
+							{Name: "service-a"},
+						},
+					},
+					{Name: "my-app-1"},
+				},
+			},
+			{Name: "development"},
+		},
+	}
+	v := &testVisitor{paths: []string{}}
+	if err := m.Walk(v); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"envs/development",
+		"staging/apps/my-app-1",
+		"staging/apps/my-app-2/services/service-a",
+		"staging/apps/my-app-2/services/service-b",
+		"staging/apps/my-app-2",
+		"envs/staging",
+	}
+	if diff := cmp.Diff(want, v.paths); diff != "" {
+		t.Fatalf("walk order: %s", diff)
+	}
+	if m.Environments[0].Name != "staging" || m.Environments[0].Apps[0].Name != "my-app-2" || m.Environments[0].Apps[0].Services[0].Name != "service-b" {
+		t.Fatal("Walk() changed the order of the manifest")
+	}
+}
+
+func TestManifestMarshalJSONSortsEnvironments(t *testing.T) {
+	m := &Manifest{
+		GitOpsURL: "https://github.com/example/gitops.git",
+		Environments: []*Environment{
+			{Name: "staging"},
+			{Name: "development"},
+		},
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"gitops_url":"https://github.com/example/gitops.git","environments":[{"name":"development"},{"name":"staging"}]}`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Fatalf("failed to marshal manifest: %s", diff)
+	}
+	if m.Environments[0].Name != "staging" {
+		t.Fatal("MarshalJSON() changed the order of the manifest")
+	}
+}
+
 func TestManifestWalkCalls(t *testing.T) {
 	m := &Manifest{
 		Environments: []*Environment{
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Layout configures the paths in the GitOps repository of the environments,
+// apps and services, and of the configuration, with Go templates.
+//
+// The templates are executed with LayoutData, the templates that are not
+// provided default to the paths of the DefaultLayout.
+//
+// For example, to put the environments in folders for their cluster:
+//
+//	environment: clusters/{{ .Cluster }}/{{ .Environment }}
+type Layout struct {
+	Environment string `json:"environment,omitempty"`
+	Application string `json:"application,omitempty"`
+	Service     string `json:"service,omitempty"`
+	Config      string `json:"config,omitempty"`
+}
+
+// LayoutData is the data that the Layout templates are executed with.
+//
+// The EnvironmentPath and ApplicationPath are the paths of the environment
+// and application, so that apps and services can be nested within them.
+type LayoutData struct {
+	Cluster         string
+	Environment     string
+	Namespace       string
+	Application     string
+	Service         string
+	Name            string
+	EnvironmentPath string
+	ApplicationPath string
+}
+
+// DefaultLayout is the layout of the environments/apps/services tree, and
+// config folder, that kam generates when no layout is configured.
+var DefaultLayout = Layout{
+	Environment: "environments/{{ .Environment }}",
+	Application: "{{ .EnvironmentPath }}/apps/{{ .Application }}",
+	Service:     "{{ .ApplicationPath }}/services/{{ .Service }}",
+	Config:      "config/{{ .Name }}",
+}
+
+// GetLayout returns the layout of the GitOps repository, if one is
+// configured.
+func (m *Manifest) GetLayout() *Layout {
+	if m.Config != nil {
+		return m.Config.Layout
+	}
+	return nil
+}
+
+// PathForEnvironment returns the repo-rooted path of the environment.
+func (l *Layout) PathForEnvironment(env *Environment) string {
+	if l == nil || l.Environment == "" {
+		return PathForEnvironment(env)
+	}
+	return l.execute(l.Environment, environmentData(env), PathForEnvironment(env))
+}
+
+// PathForApplication returns the repo-rooted path of the app in the
+// environment.
+func (l *Layout) PathForApplication(env *Environment, app *Application) string {
+	if l == nil || (l.Environment == "" && l.Application == "") {
+		return PathForApplication(env, app)
+	}
+	data := environmentData(env)
+	data.EnvironmentPath = filepath.ToSlash(l.PathForEnvironment(env))
+	data.Application = app.Name
+	return l.execute(l.template(l.Application, DefaultLayout.Application), data, PathForApplication(env, app))
+}
+
+// PathForService returns the repo-rooted path of the service in the app.
+func (l *Layout) PathForService(app *Application, env *Environment, serviceName string) string {
+	if l == nil || (l.Environment == "" && l.Application == "" && l.Service == "") {
+		return PathForService(app, env, serviceName)
+	}
+	data := environmentData(env)
+	data.EnvironmentPath = filepath.ToSlash(l.PathForEnvironment(env))
+	data.Application = app.Name
+	data.ApplicationPath = filepath.ToSlash(l.PathForApplication(env, app))
+	data.Service = serviceName
+	return l.execute(l.template(l.Service, DefaultLayout.Service), data, PathForService(app, env, serviceName))
+}
+
+// PathForPipelines returns the repo-rooted path of the CICD environment.
+func (l *Layout) PathForPipelines(pipeline *PipelinesConfig) string {
+	if l == nil || l.Config == "" {
+		return PathForPipelines(pipeline)
+	}
+	return l.execute(l.Config, LayoutData{Name: pipeline.Name}, PathForPipelines(pipeline))
+}
+
+// PathForArgoCD returns the repo-rooted path of the ArgoCD configuration.
+func (l *Layout) PathForArgoCD() string {
+	if l == nil || l.Config == "" {
+		return PathForArgoCD()
+	}
+	return l.execute(l.Config, LayoutData{Name: "argocd"}, PathForArgoCD())
+}
+
+func (l *Layout) template(t, defaultTemplate string) string {
+	if t == "" {
+		return defaultTemplate
+	}
+	return t
+}
+
+// execute executes the template with the data, returning the fallback path if
+// the template fails, invalid templates are reported when the manifest is
+// validated.
+func (l *Layout) execute(t string, data LayoutData, fallback string) string {
+	path, err := executeLayoutTemplate(t, data)
+	if err != nil {
+		return fallback
+	}
+	return path
+}
+
+func executeLayoutTemplate(t string, data LayoutData) (string, error) {
+	tmpl, err := template.New("layout").Option("missingkey=error").Parse(t)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return filepath.Clean(strings.TrimSpace(b.String())), nil
+}
+
+func environmentData(env *Environment) LayoutData {
+	return LayoutData{
+		Cluster:     env.Cluster,
+		Environment: env.Name,
+		Namespace:   env.GetNamespace(),
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLayoutPaths(t *testing.T) {
+	env := &Environment{Name: "dev", Cluster: "east"}
+	app := &Application{Name: "shop"}
+	cfg := &PipelinesConfig{Name: "cicd"}
+
+	layoutTests := []struct {
+		desc   string
+		layout *Layout
+		want   []string
+	}{
+		{
+			"no layout", nil,
+			[]string{"environments/dev", "environments/dev/apps/shop", "environments/dev/apps/shop/services/web", "config/cicd", "config/argocd"},
+		},
+		{
+			"default layout", &DefaultLayout,
+			[]string{"environments/dev", "environments/dev/apps/shop", "environments/dev/apps/shop/services/web", "config/cicd", "config/argocd"},
+		},
+		{
+			"cluster layout", &Layout{Environment: "clusters/{{ .Cluster }}/{{ .Environment }}", Config: "clusters/{{ .Name }}"},
+			[]string{"clusters/east/dev", "clusters/east/dev/apps/shop", "clusters/east/dev/apps/shop/services/web", "clusters/cicd", "clusters/argocd"},
+		},
+		{
+			"app-first layout",
+			&Layout{
+				Environment: "envs/{{ .Environment }}",
+				Application: "apps/{{ .Application }}/envs/{{ .Environment }}",
+				Service:     "{{ .ApplicationPath }}/{{ .Service }}",
+			},
+			[]string{"envs/dev", "apps/shop/envs/dev", "apps/shop/envs/dev/web", "config/cicd", "config/argocd"},
+		},
+	}
+
+	for _, tt := range layoutTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			got := []string{
+				tt.layout.PathForEnvironment(env),
+				tt.layout.PathForApplication(env, app),
+				tt.layout.PathForService(app, env, "web"),
+				tt.layout.PathForPipelines(cfg),
+				tt.layout.PathForArgoCD(),
+			}
+			for i := range got {
+				got[i] = filepath.ToSlash(got[i])
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				rt.Fatalf("layout paths didn't match:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestManifestGetLayout(t *testing.T) {
+	layout := &Layout{Environment: "envs/{{ .Environment }}"}
+
+	if l := (&Manifest{}).GetLayout(); l != nil {
+		t.Fatalf("GetLayout() got %v, want nil", l)
+	}
+	if l := (&Manifest{Config: &Config{Layout: layout}}).GetLayout(); l != layout {
+		t.Fatalf("GetLayout() got %v, want %v", l, layout)
+	}
+}
@@ -6,20 +6,22 @@ import (
 	"io/ioutil"
 	"path/filepath"
 
+	"github.com/mkmik/multierror"
 	"github.com/spf13/afero"
 	"sigs.k8s.io/yaml"
 )
 
 // Parse decodes YAML describing an environment manifest.
+//
+// Fields that are not part of the manifest, or have values of the wrong type,
+// are reported as a multi-error with the YAML path to each field.
 func Parse(in io.Reader) (*Manifest, error) {
-	m := &Manifest{}
-	buf, err := ioutil.ReadAll(in)
+	m, schemaErrs, err := decode(in)
 	if err != nil {
 		return nil, err
 	}
-	err = yaml.Unmarshal(buf, m)
-	if err != nil {
-		return nil, err
+	if len(schemaErrs) > 0 {
+		return nil, multierror.Join(schemaErrs)
 	}
 	return m, nil
 }
@@ -38,12 +40,44 @@ func ParseFile(fs afero.Fs, filename string) (*Manifest, error) {
 // ParsePipelinesFolder will accept the pipelines folder path
 // and appends pipelines file name before parsing it
 func ParsePipelinesFolder(fs afero.Fs, folderPath string) (*Manifest, error) {
-	info, err := fs.Stat(folderPath)
+	filename, err := pipelinesFilename(fs, folderPath)
 	if err != nil {
 		return nil, err
 	}
+	return ParseFile(fs, filename)
+}
+
+func pipelinesFilename(fs afero.Fs, folderPath string) (string, error) {
+	info, err := fs.Stat(folderPath)
+	if err != nil {
+		return "", err
+	}
 	if !info.IsDir() {
-		return nil, fmt.Errorf("the path %q is a file path (required directory path)", folderPath)
+		return "", fmt.Errorf("the path %q is a file path (required directory path)", folderPath)
+	}
+	return filepath.Join(folderPath, PipelinesFile), nil // Don't call filepath.ToSlash
+}
+
+// decode decodes the manifest, and checks it against the manifest schema.
+//
+// The manifest is nil if the schema errors prevent decoding it.
+func decode(in io.Reader) (*Manifest, []error, error) {
+	buf, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(buf, &doc); err != nil {
+		return nil, nil, err
+	}
+	schemaErrs := ManifestSchema().validate(doc, "")
+
+	m := &Manifest{}
+	if err := yaml.Unmarshal(buf, m); err != nil {
+		if len(schemaErrs) > 0 {
+			return nil, schemaErrs, nil
+		}
+		return nil, nil, err
 	}
-	return ParseFile(fs, filepath.Join(folderPath, PipelinesFile)) // Don't call filepath.ToSlash
+	return m, schemaErrs, nil
 }
@@ -0,0 +1,98 @@
+package config
+
+// Policies configures the policy objects that are generated for the namespace
+// of an environment.
+//
+// The Size is a preset for the Quota and Limits, one of small, medium or
+// large, and the fields of the Quota and Limits override the preset.
+//
+// NetworkIsolation denies ingress to the namespace, except from the same
+// namespace, the router and the CI/CD namespace.
+type Policies struct {
+	Size             string  `json:"size,omitempty"`
+	Quota            *Quota  `json:"quota,omitempty"`
+	Limits           *Limits `json:"limits,omitempty"`
+	NetworkIsolation bool    `json:"network_isolation,omitempty"`
+}
+
+// Quota is the total of the resources that can be used in a namespace.
+type Quota struct {
+	CPU    string `json:"cpu,omitempty"`
+	Memory string `json:"memory,omitempty"`
+	Pods   int    `json:"pods,omitempty"`
+}
+
+// Limits are the default resource limits and requests for the containers in a
+// namespace.
+type Limits struct {
+	CPU           string `json:"cpu,omitempty"`
+	Memory        string `json:"memory,omitempty"`
+	RequestCPU    string `json:"request_cpu,omitempty"`
+	RequestMemory string `json:"request_memory,omitempty"`
+}
+
+// PolicyPresets are the quotas and limits for each of the policy sizes.
+var PolicyPresets = map[string]Policies{
+	"small": {
+		Quota:  &Quota{CPU: "2", Memory: "4Gi", Pods: 10},
+		Limits: &Limits{CPU: "500m", Memory: "512Mi", RequestCPU: "100m", RequestMemory: "128Mi"},
+	},
+	"medium": {
+		Quota:  &Quota{CPU: "4", Memory: "8Gi", Pods: 20},
+		Limits: &Limits{CPU: "1", Memory: "1Gi", RequestCPU: "250m", RequestMemory: "256Mi"},
+	},
+	"large": {
+		Quota:  &Quota{CPU: "8", Memory: "16Gi", Pods: 50},
+		Limits: &Limits{CPU: "2", Memory: "2Gi", RequestCPU: "500m", RequestMemory: "512Mi"},
+	},
+}
+
+// GetQuota returns the quota from the preset for the size, with the fields
+// of the Quota applied, or nil if there is no quota.
+func (p *Policies) GetQuota() *Quota {
+	if p == nil {
+		return nil
+	}
+	q := &Quota{}
+	if preset, ok := PolicyPresets[p.Size]; ok {
+		*q = *preset.Quota
+	} else if p.Quota == nil {
+		return nil
+	}
+	if p.Quota != nil {
+		q.CPU = override(q.CPU, p.Quota.CPU)
+		q.Memory = override(q.Memory, p.Quota.Memory)
+		if p.Quota.Pods != 0 {
+			q.Pods = p.Quota.Pods
+		}
+	}
+	return q
+}
+
+// GetLimits returns the limits from the preset for the size, with the fields
+// of the Limits applied, or nil if there are no limits.
+func (p *Policies) GetLimits() *Limits {
+	if p == nil {
+		return nil
+	}
+	l := &Limits{}
+	if preset, ok := PolicyPresets[p.Size]; ok {
+		*l = *preset.Limits
+	} else if p.Limits == nil {
+		return nil
+	}
+	if p.Limits != nil {
+		l.CPU = override(l.CPU, p.Limits.CPU)
+		l.Memory = override(l.Memory, p.Limits.Memory)
+		l.RequestCPU = override(l.RequestCPU, p.Limits.RequestCPU)
+		l.RequestMemory = override(l.RequestMemory, p.Limits.RequestMemory)
+	}
+	return l
+}
+
+func override(s, o string) string {
+	if o != "" {
+		return o
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPoliciesGetQuota(t *testing.T) {
+	quotaTests := []struct {
+		desc     string
+		policies *Policies
+		want     *Quota
+	}{
+		{"no policies", nil, nil},
+		{"no size or quota", &Policies{NetworkIsolation: true}, nil},
+		{"preset", &Policies{Size: "small"}, &Quota{CPU: "2", Memory: "4Gi", Pods: 10}},
+		{"preset with overrides", &Policies{Size: "large", Quota: &Quota{Memory: "32Gi"}}, &Quota{CPU: "8", Memory: "32Gi", Pods: 50}},
+		{"quota without a preset", &Policies{Quota: &Quota{Pods: 5}}, &Quota{Pods: 5}},
+	}
+
+	for _, tt := range quotaTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			if diff := cmp.Diff(tt.want, tt.policies.GetQuota()); diff != "" {
+				rt.Fatalf("GetQuota() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPoliciesGetLimits(t *testing.T) {
+	limitsTests := []struct {
+		desc     string
+		policies *Policies
+		want     *Limits
+	}{
+		{"no policies", nil, nil},
+		{"no size or limits", &Policies{NetworkIsolation: true}, nil},
+		{"preset", &Policies{Size: "medium"}, &Limits{CPU: "1", Memory: "1Gi", RequestCPU: "250m", RequestMemory: "256Mi"}},
+		{"preset with overrides", &Policies{Size: "medium", Limits: &Limits{RequestCPU: "100m"}}, &Limits{CPU: "1", Memory: "1Gi", RequestCPU: "100m", RequestMemory: "256Mi"}},
+		{"limits without a preset", &Policies{Limits: &Limits{CPU: "1"}}, &Limits{CPU: "1"}},
+	}
+
+	for _, tt := range limitsTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			if diff := cmp.Diff(tt.want, tt.policies.GetLimits()); diff != "" {
+				rt.Fatalf("GetLimits() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPoliciesGetQuotaDoesNotChangePresets(t *testing.T) {
+	p := &Policies{Size: "small", Quota: &Quota{CPU: "3"}}
+	_ = p.GetQuota()
+
+	if cpu := PolicyPresets["small"].Quota.CPU; cpu != "2" {
+		t.Fatalf("GetQuota() changed the preset CPU to %q", cpu)
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mkmik/multierror"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/spf13/afero"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"knative.dev/pkg/apis"
+)
+
+// generatedTemplates are the names of the TriggerTemplates that kam generates
+// for the CI/CD environment, these, and the push bindings of the Git
+// providers, are accepted before the resources are written to the base of
+// the CI/CD environment.
+var generatedTemplates = map[string]bool{
+	"app-ci-template":              true,
+	"ci-dryrun-from-push-template": true,
+}
+
+// ValidateReferences checks that the TriggerTemplates and TriggerBindings
+// referenced by the environment and service pipelines are defined in the base
+// of the CI/CD environment, returning a multi-error with an error for each
+// missing reference.
+//
+// No references are checked if the base of the CI/CD environment doesn't
+// exist.
+func (m *Manifest) ValidateReferences(fs afero.Fs, pipelinesFolderPath string) error {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return nil
+	}
+	basePath := filepath.Join(pipelinesFolderPath, m.GetLayout().PathForPipelines(cfg), "base")
+	exists, err := afero.DirExists(fs, basePath)
+	if err != nil || !exists {
+		return err
+	}
+	defined, err := readTriggersNames(fs, basePath)
+	if err != nil {
+		return err
+	}
+	rv := &referencesVisitor{errs: []error{}, defined: defined}
+	if err := m.Walk(rv); err != nil {
+		return err
+	}
+	if len(rv.errs) == 0 {
+		return nil
+	}
+	return multierror.Join(rv.errs)
+}
+
+type referencesVisitor struct {
+	errs    []error
+	defined map[string]map[string]bool
+}
+
+func (rv *referencesVisitor) Environment(env *Environment) error {
+	rv.validatePipelines(env.Pipelines, yamlPath(PathForEnvironment(env)))
+	return nil
+}
+
+func (rv *referencesVisitor) Service(app *Application, env *Environment, svc *Service) error {
+	rv.validatePipelines(svc.Pipelines, yamlPath(PathForService(app, env, svc.Name)))
+	return nil
+}
+
+func (rv *referencesVisitor) validatePipelines(pipelines *Pipelines, path string) {
+	if pipelines == nil || pipelines.Integration == nil {
+		return
+	}
+	integrationPath := yamlJoin(path, "pipelines", "integration")
+	if name := pipelines.Integration.Template; name != "" {
+		if !rv.defined["TriggerTemplate"][name] && !generatedTemplates[name] {
+			rv.errs = append(rv.errs, missingReferenceError("TriggerTemplate", name, []string{yamlJoin(integrationPath, "template")}))
+		}
+	}
+	for _, name := range pipelines.Integration.Bindings {
+		if !rv.defined["TriggerBinding"][name] && !scm.IsPushBindingName(name) {
+			rv.errs = append(rv.errs, missingReferenceError("TriggerBinding", name, []string{yamlJoin(integrationPath, "bindings")}))
+		}
+	}
+}
+
+// readTriggersNames returns the names of the TriggerTemplates and
+// TriggerBindings in the YAML files in a folder, keyed by kind, the files can
+// have multiple documents.
+func readTriggersNames(fs afero.Fs, basePath string) (map[string]map[string]bool, error) {
+	names := map[string]map[string]bool{
+		"TriggerTemplate": {},
+		"TriggerBinding":  {},
+	}
+	err := afero.Walk(fs, basePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".yaml" {
+			return nil
+		}
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+		decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+		for {
+			var r struct {
+				Kind     string `json:"kind"`
+				Metadata struct {
+					Name string `json:"name"`
+				} `json:"metadata"`
+			}
+			if err := decoder.Decode(&r); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+			if kindNames, ok := names[r.Kind]; ok {
+				kindNames[r.Metadata.Name] = true
+			}
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the Triggers resources from %s: %w", basePath, err)
+	}
+	return names, nil
+}
+
+func missingReferenceError(kind, name string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("%s %q does not exist", kind, name),
+		Details: "it must be defined in the base of the CI/CD environment",
+		Paths:   paths,
+	}
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/mkmik/multierror"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+const testBinding = `apiVersion: triggers.tekton.dev/v1alpha1
+kind: TriggerBinding
+metadata:
+  name: dev-app-svc-binding
+  namespace: cicd
+`
+
+func TestValidateReferences(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	writeBase(t, fs, map[string]string{"05-bindings/dev-app-svc-binding.yaml": testBinding})
+	m := referencesManifest(&Pipelines{
+		Integration: &TemplateBinding{
+			Template: "app-ci-template",
+			Bindings: []string{"github-push-binding", "dev-app-svc-binding"},
+		},
+	})
+
+	if err := m.ValidateReferences(fs, "/gitops"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateReferencesWithMultipleDocuments(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	writeBase(t, fs, map[string]string{"06-triggers/svc-triggers.yaml": `apiVersion: triggers.tekton.dev/v1alpha1
+kind: TriggerTemplate
+metadata:
+  name: svc-template
+  namespace: cicd
+---
+` + testBinding})
+	m := referencesManifest(&Pipelines{
+		Integration: &TemplateBinding{
+			Template: "svc-template",
+			Bindings: []string{"dev-app-svc-binding"},
+		},
+	})
+
+	if err := m.ValidateReferences(fs, "/gitops"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateReferencesWithSSHPushBindings(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	writeBase(t, fs, map[string]string{})
+	m := referencesManifest(&Pipelines{
+		Integration: &TemplateBinding{
+			Template: "app-ci-template",
+			Bindings: []string{"github-ssh-push-binding", "gitlab-ssh-push-binding"},
+		},
+	})
+
+	if err := m.ValidateReferences(fs, "/gitops"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateReferencesWithMissingReferences(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	writeBase(t, fs, map[string]string{"05-bindings/dev-app-svc-binding.yaml": testBinding})
+	m := referencesManifest(&Pipelines{
+		Integration: &TemplateBinding{
+			Template: "app-ci-templat",
+			Bindings: []string{"dev-app-svc-bindng"},
+		},
+	})
+
+	err := m.ValidateReferences(fs, "/gitops")
+
+	want := multierror.Join([]error{
+		missingReferenceError("TriggerTemplate", "app-ci-templat", []string{"environments.dev.apps.app.services.svc.pipelines.integration.template"}),
+		missingReferenceError("TriggerBinding", "dev-app-svc-bindng", []string{"environments.dev.apps.app.services.svc.pipelines.integration.bindings"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateReferencesWithNoBase(t *testing.T) {
+	m := referencesManifest(&Pipelines{
+		Integration: &TemplateBinding{Template: "unknown-template"},
+	})
+
+	if err := m.ValidateReferences(ioutils.NewMemoryFilesystem(), "/gitops"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadManifestWithMissingReferences(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	writeBase(t, fs, map[string]string{})
+	m := referencesManifest(&Pipelines{
+		Integration: &TemplateBinding{Bindings: []string{"missing-binding"}},
+	})
+	if err := yaml.MarshalItemToFile(fs, "/gitops/pipelines.yaml", m); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadManifest(fs, "/gitops")
+
+	want := missingReferenceError("TriggerBinding", "missing-binding", []string{"environments.dev.apps.app.services.svc.pipelines.integration.bindings"})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func referencesManifest(p *Pipelines) *Manifest {
+	return &Manifest{
+		Config: &Config{Pipelines: &PipelinesConfig{Name: "cicd"}},
+		Environments: []*Environment{
+			{
+				Name: "dev",
+				Apps: []*Application{
+					{
+						Name: "app",
+						Services: []*Service{
+							{Name: "svc", Pipelines: p},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func writeBase(t *testing.T, fs afero.Fs, files map[string]string) {
+	t.Helper()
+	if err := fs.MkdirAll("/gitops/config/cicd/base", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if err := afero.WriteFile(fs, "/gitops/config/cicd/base/"+name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+const schemaVersion = "http://json-schema.org/draft-07/schema#"
+
+// Schema is the subset of JSON Schema that describes the manifest, a Schema
+// without a Type accepts any value.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// ManifestSchema returns the JSON Schema for pipelines.yaml, generated from
+// the Manifest types.
+func ManifestSchema() *Schema {
+	s := schemaForType(reflect.TypeOf(Manifest{}))
+	s.Schema = schemaVersion
+	s.Title = "kam " + PipelinesFile
+	return s
+}
+
+// schemaForType returns the schema for values of the type, types that the
+// schema can't describe, e.g. interfaces, accept any value.
+func schemaForType(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaForType(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaForType(t.Elem())}
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			s.Properties[name] = schemaForType(f.Type)
+		}
+		return s
+	}
+	return &Schema{}
+}
+
+// validate checks a decoded YAML document against the schema, and returns
+// an error for each value that doesn't match, with the YAML path to the value.
+//
+// Null values are always accepted, as all the fields in the manifest are
+// optional.
+func (s *Schema) validate(v interface{}, path string) []error {
+	if v == nil {
+		return nil
+	}
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return list(invalidTypeError(s.Type, v, path))
+		}
+		return s.validateObject(obj, path)
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok {
+			return list(invalidTypeError(s.Type, v, path))
+		}
+		errs := []error{}
+		for i, item := range items {
+			errs = append(errs, s.Items.validate(item, yamlJoin(path, itemKey(item, i)))...)
+		}
+		return errs
+	case "string":
+		if _, ok := v.(string); !ok {
+			return list(invalidTypeError(s.Type, v, path))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return list(invalidTypeError(s.Type, v, path))
+		}
+	case "integer":
+		if n, ok := v.(float64); !ok || n != math.Trunc(n) {
+			return list(invalidTypeError(s.Type, v, path))
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(obj map[string]interface{}, path string) []error {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	errs := []error{}
+	for _, k := range keys {
+		fieldPath := k
+		if path != "" {
+			fieldPath = yamlJoin(path, k)
+		}
+		if prop, ok := s.Properties[k]; ok {
+			errs = append(errs, prop.validate(obj[k], fieldPath)...)
+			continue
+		}
+		if additional, ok := s.AdditionalProperties.(*Schema); ok {
+			errs = append(errs, additional.validate(obj[k], fieldPath)...)
+			continue
+		}
+		errs = append(errs, unknownFieldError(k, []string{fieldPath}))
+	}
+	return errs
+}
+
+// itemKey identifies an item in a list in a YAML path, named items are
+// identified by their name, as in the paths used by Validate.
+func itemKey(item interface{}, i int) string {
+	if obj, ok := item.(map[string]interface{}); ok {
+		if name, ok := obj["name"].(string); ok && name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("[%d]", i)
+}
+
+func unknownFieldError(field string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("unknown field %q", field),
+		Paths:   paths,
+	}
+}
+
+func invalidTypeError(want string, v interface{}, path string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid value %v", v),
+		Details: fmt.Sprintf("expected a value of type %s", want),
+		Paths:   []string{path},
+	}
+}
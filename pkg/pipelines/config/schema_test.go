@@ -0,0 +1,109 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mkmik/multierror"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+func TestManifestSchema(t *testing.T) {
+	s := ManifestSchema()
+
+	if s.Schema != schemaVersion {
+		t.Errorf("ManifestSchema() got $schema %q, want %q", s.Schema, schemaVersion)
+	}
+	want := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"name":      {Type: "string"},
+			"namespace": {Type: "string"},
+		},
+		AdditionalProperties: false,
+	}
+	secret := s.Properties["environments"].Items.Properties["apps"].Items.Properties["services"].Items.Properties["webhook"].Properties["secret"]
+	if diff := cmp.Diff(want, secret); diff != "" {
+		t.Errorf("ManifestSchema() service webhook secret:\n%s", diff)
+	}
+	drivers := s.Properties["config"].Properties["git"].Properties["drivers"]
+	if diff := cmp.Diff(&Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, drivers); diff != "" {
+		t.Errorf("ManifestSchema() git drivers:\n%s", diff)
+	}
+	if got := s.Properties["version"].Type; got != "integer" {
+		t.Errorf("ManifestSchema() got version type %q, want integer", got)
+	}
+}
+
+func TestSchemaForUnsupportedTypes(t *testing.T) {
+	type unsupported struct {
+		Ratio  float64                `json:"ratio"`
+		Any    interface{}            `json:"any"`
+		Values map[string]interface{} `json:"values"`
+	}
+
+	want := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"ratio":  {},
+			"any":    {},
+			"values": {Type: "object", AdditionalProperties: &Schema{}},
+		},
+		AdditionalProperties: false,
+	}
+	s := schemaForType(reflect.TypeOf(unsupported{}))
+	if diff := cmp.Diff(want, s); diff != "" {
+		t.Fatalf("schemaForType() failed:\n%s", diff)
+	}
+	if errs := s.validate(map[string]interface{}{"ratio": 0.5, "any": []interface{}{"a"}, "values": map[string]interface{}{"a": true}}, ""); len(errs) != 0 {
+		t.Fatalf("validate() got errors %v, want none", errs)
+	}
+}
+
+func TestParseWithUnknownFields(t *testing.T) {
+	_, err := ParseFile(ioutils.NewFilesystem(), "testdata/unknown_fields.yaml")
+
+	want := multierror.Join([]error{
+		unknownFieldError("source_ur", []string{"environments.development.apps.app-1.services.service-1.source_ur"}),
+		unknownFieldError("pipeline", []string{"environments.development.pipeline"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseWithInvalidTypes(t *testing.T) {
+	_, err := Parse(strings.NewReader("version: one\nenvironments:\n  - name: dev\n    apps: app-1\n"))
+
+	want := multierror.Join([]error{
+		invalidTypeError("array", "app-1", "environments.dev.apps"),
+		invalidTypeError("integer", "one", "version"),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadManifestReportsSchemaAndValidationErrors(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	data, err := ioutils.NewFilesystem().ReadFile("testdata/unknown_fields.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("/gitops/pipelines.yaml", data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LoadManifest(fs, "/gitops")
+
+	want := multierror.Join([]error{
+		unknownFieldError("source_ur", []string{"environments.development.apps.app-1.services.service-1.source_ur"}),
+		unknownFieldError("pipeline", []string{"environments.development.pipeline"}),
+		invalidNameError("service_2", DNS1035Error, []string{"environments.development.apps.app-1.services.service_2"}),
+	})
+	if err := matchMultiErrors(t, err, want); err != nil {
+		t.Fatal(err)
+	}
+}
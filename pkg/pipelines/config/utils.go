@@ -3,29 +3,51 @@ package config
 import (
 	"fmt"
 
-	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/mkmik/multierror"
 	"github.com/spf13/afero"
 )
 
-// LoadManifest reads a manifest file, and configures the environment based on
-// the configuration.
+// LoadManifest reads a manifest file.
+//
+// Schema errors, validation errors and missing references to Triggers
+// resources are reported together in a multi-error.
 func LoadManifest(fs afero.Fs, path string) (*Manifest, error) {
-	m, err := ParsePipelinesFolder(fs, path)
+	m, schemaErrs, err := decodePipelinesFolder(fs, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load manifest: %w", err)
 	}
-	if !(m.Config == nil || m.Config.Git == nil || m.Config.Git.Drivers == nil) {
-		drivers := []factory.MappingFunc{}
-		for k, v := range m.Config.Git.Drivers {
-			drivers = append(drivers, factory.Mapping(k, v))
-		}
-		if len(drivers) > 0 {
-			id := factory.NewDriverIdentifier(drivers...)
-			factory.DefaultIdentifier = id
-		}
+	if m == nil {
+		return nil, multierror.Join(schemaErrs)
 	}
+	if m.GetVersion() > LatestVersion {
+		return nil, unsupportedVersionError(m.GetVersion())
+	}
+	errs := schemaErrs
 	if err := m.Validate(); err != nil {
-		return nil, err
+		errs = append(errs, multierror.Split(err)...)
+	}
+	if err := m.ValidateReferences(fs, path); err != nil {
+		errs = append(errs, multierror.Split(err)...)
+	}
+	if len(errs) > 0 {
+		return nil, multierror.Join(errs)
 	}
 	return m, nil
 }
+
+func unsupportedVersionError(version int) error {
+	return fmt.Errorf("manifest version %d is newer than the latest version %d supported by this version of kam, please upgrade kam", version, LatestVersion)
+}
+
+func decodePipelinesFolder(fs afero.Fs, folderPath string) (*Manifest, []error, error) {
+	filename, err := pipelinesFilename(fs, folderPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := fs.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	return decode(f)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -9,14 +10,10 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 )
 
-func TestLoadManifestUpdatesDrivers(t *testing.T) {
-	d, err := factory.DefaultIdentifier.Identify("example.com")
-	if err == nil {
-		t.Fatalf("successfully identified an unknown host as %q", d)
-	}
-
+func TestLoadManifestWithPrivateDrivers(t *testing.T) {
 	fs := ioutils.NewMemoryFilesystem()
 	c := &Manifest{
+		GitOpsURL: "https://example.com/org/gitops.git",
 		Config: &Config{
 			Git: &GitConfig{
 				Drivers: map[string]string{
@@ -25,7 +22,7 @@ func TestLoadManifestUpdatesDrivers(t *testing.T) {
 			},
 		},
 	}
-	_, err = yaml.WriteResources(fs, "/manifest", map[string]interface{}{
+	_, err := yaml.WriteResources(fs, "/manifest", map[string]interface{}{
 		"pipelines.yaml": c,
 	})
 	if err != nil {
@@ -34,17 +31,78 @@ func TestLoadManifestUpdatesDrivers(t *testing.T) {
 
 	m, err := LoadManifest(fs, "/manifest")
 	if err != nil {
-		t.Fatal("failed to load manifest")
+		t.Fatalf("failed to load manifest: %v", err)
 	}
 	if diff := cmp.Diff(c, m); diff != "" {
 		t.Fatalf("diff in loading manifest:\n%s", diff)
 	}
 
-	d, err = factory.DefaultIdentifier.Identify("example.com")
+	d, err := m.GetDriverResolver().Identify("example.com")
 	if err != nil {
 		t.Fatal("failed to identify driver after loading from manifest")
 	}
 	if d != "github" {
 		t.Fatalf("incorrectly identified driver, got %q, want %q", d, "github")
 	}
+	d, err = factory.DefaultIdentifier.Identify("example.com")
+	if err == nil {
+		t.Fatalf("loading the manifest changed the default identifier, identified the host as %q", d)
+	}
+}
+
+func TestLoadManifestsWithDifferentDrivers(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	for path, driver := range map[string]string{"/github": "github", "/gitlab": "gitlab"} {
+		_, err := yaml.WriteResources(fs, path, map[string]interface{}{
+			"pipelines.yaml": &Manifest{
+				GitOpsURL: "https://example.com/org/gitops.git",
+				Config:    &Config{Git: &GitConfig{Drivers: map[string]string{"example.com": driver}}},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	github, err := LoadManifest(fs, "/github")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitlab, err := LoadManifest(fs, "/gitlab")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		m    *Manifest
+		want string
+	}{
+		{github, "github"},
+		{gitlab, "gitlab"},
+	} {
+		d, err := tt.m.GetDriverResolver().Identify("example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d != tt.want {
+			t.Errorf("incorrectly identified driver, got %q, want %q", d, tt.want)
+		}
+	}
+}
+
+func TestLoadManifestWithFutureVersion(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	_, err := yaml.WriteResources(fs, "/manifest", map[string]interface{}{
+		"pipelines.yaml": &Manifest{Version: LatestVersion + 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LoadManifest(fs, "/manifest")
+
+	want := fmt.Sprintf("manifest version %d is newer than the latest version %d supported by this version of kam, please upgrade kam", LatestVersion+1, LatestVersion)
+	if err == nil || err.Error() != want {
+		t.Fatalf("LoadManifest() got error %v, want %s", err, want)
+	}
 }
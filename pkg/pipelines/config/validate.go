@@ -3,10 +3,12 @@ package config
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/mkmik/multierror"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/api/validation"
 	"knative.dev/pkg/apis"
 )
@@ -23,6 +25,9 @@ type validateVisitor struct {
 	serviceNames map[string]bool
 	serviceURLs  map[string][]string
 	configNames  map[string]bool
+	// namespaces maps the cluster and namespace of each environment to the
+	// path of the environment.
+	namespaces map[string]string
 }
 
 // Validate validates the Manifest, returning a multi-error representing all the
@@ -35,6 +40,7 @@ func (m *Manifest) Validate() error {
 		serviceNames: map[string]bool{},
 		serviceURLs:  map[string][]string{},
 		configNames:  map[string]bool{},
+		namespaces:   map[string]string{},
 	}
 
 	vv.errs = append(vv.errs, vv.validateConfig(m)...)
@@ -42,7 +48,8 @@ func (m *Manifest) Validate() error {
 	if err != nil {
 		vv.errs = append(vv.errs, err)
 	}
-	vv.errs = append(vv.errs, vv.validateServiceURLs(m.GitOpsURL)...)
+	vv.errs = append(vv.errs, vv.validateServiceURLs(m.GitOpsURL, m.GetDriverResolver())...)
+	vv.errs = append(vv.errs, validateLayout(m)...)
 
 	if len(vv.errs) == 0 {
 		return nil
@@ -50,27 +57,28 @@ func (m *Manifest) Validate() error {
 	return multierror.Join(vv.errs)
 }
 
-func (vv *validateVisitor) validateServiceURLs(gitOpsURL string) []error {
+func (vv *validateVisitor) validateServiceURLs(gitOpsURL string, drivers *scm.DriverResolver) []error {
 	errs := []error{}
 
-	// all services must be the same git type as the gitops repo
-	var gitType string
-
+	// The services can be on different Git hosts, and providers, to the
+	// GitOps repo, but the driver for every host must be known to generate
+	// the triggers.
 	if gitOpsURL != "" {
-		gitOpsDriver, err := scm.GetDriverName(gitOpsURL)
-		if err != nil {
+		if _, err := scm.GetDriverName(gitOpsURL, drivers); err != nil {
 			errs = append(errs, err)
 		}
-		gitType = gitOpsDriver
 	}
 
-	for url, paths := range vv.serviceURLs {
-		if gitType != "" {
-			serviceDriver, err := scm.GetDriverName(url)
-			if err != nil {
-				errs = append(errs, err)
-			} else if gitType != serviceDriver {
-				errs = append(errs, inconsistentGitTypeError(gitType, url, paths))
+	urls := []string{}
+	for url := range vv.serviceURLs {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	for _, url := range urls {
+		paths := vv.serviceURLs[url]
+		if gitOpsURL != "" {
+			if _, err := scm.GetDriverName(url, drivers); err != nil {
+				errs = append(errs, unknownGitDriverError(url, err, paths))
 			}
 		}
 		if len(paths) > 1 {
@@ -85,8 +93,9 @@ func (vv *validateVisitor) Environment(env *Environment) error {
 	if _, ok := vv.configNames[env.Name]; ok {
 		vv.errs = append(vv.errs, invalidEnvironment(env.Name, "Environment name cannot be the same as a config name.", []string{envPath}))
 	}
-	if err := checkDuplicate(env.Name, envPath, vv.envNames); err != nil {
-		vv.errs = append(vv.errs, err)
+	duplicate := checkDuplicate(env.Name, envPath, vv.envNames)
+	if duplicate != nil {
+		vv.errs = append(vv.errs, duplicate)
 	}
 	if err := validateName(env.Name, envPath); err != nil {
 		vv.errs = append(vv.errs, err)
@@ -94,9 +103,36 @@ func (vv *validateVisitor) Environment(env *Environment) error {
 	if err := validatePipelines(env.Pipelines, envPath); err != nil {
 		vv.errs = append(vv.errs, err...)
 	}
+	if err := validatePolicies(env.Policies, envPath); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
+	vv.errs = append(vv.errs, validateComponents(env.Components, envPath)...)
+	// A duplicate environment is already reported, so it's not reported as
+	// deploying to the same namespace.
+	if duplicate == nil {
+		vv.validateNamespace(env, envPath)
+	}
 	return nil
 }
 
+func (vv *validateVisitor) validateNamespace(env *Environment, envPath string) {
+	ns := env.GetNamespace()
+	if env.Namespace != "" {
+		if errs := validation.ValidateNamespaceName(env.Namespace, false); len(errs) > 0 {
+			vv.errs = append(vv.errs, invalidNameError(env.Namespace, errs[0], []string{yamlJoin(envPath, "namespace")}))
+		}
+		if _, ok := vv.configNames[ns]; ok {
+			vv.errs = append(vv.errs, invalidEnvironment(env.Name, "Environment namespace cannot be the same as a config name.", []string{yamlJoin(envPath, "namespace")}))
+		}
+	}
+	key := env.Cluster + "/" + ns
+	if previous, ok := vv.namespaces[key]; ok {
+		vv.errs = append(vv.errs, namespaceClashError(ns, []string{previous, envPath}))
+		return
+	}
+	vv.namespaces[key] = envPath
+}
+
 func (vv *validateVisitor) Application(env *Environment, app *Application) error {
 	appPath := yamlPath(PathForApplication(env, app))
 	if err := checkDuplicate(app.Name, appPath, vv.appNames); err != nil {
@@ -187,6 +223,14 @@ func validateWebhook(hook *Webhook, path string) []error {
 	if err := validateName(hook.Secret.Namespace, yamlJoin(path, "webhook", "secret", "namespace")); err != nil {
 		errs = append(errs, err)
 	}
+	if hook.PreviousSecret != nil {
+		if err := validateName(hook.PreviousSecret.Name, yamlJoin(path, "webhook", "previous_secret", "name")); err != nil {
+			errs = append(errs, err)
+		}
+		if err := validateName(hook.PreviousSecret.Namespace, yamlJoin(path, "webhook", "previous_secret", "namespace")); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	return errs
 }
 
@@ -205,6 +249,177 @@ func validatePipelines(pipelines *Pipelines, path string) []error {
 	}
 	return errs
 }
+func validatePolicies(policies *Policies, path string) []error {
+	errs := []error{}
+	if policies == nil {
+		return nil
+	}
+	policiesPath := yamlJoin(path, "policies")
+	if _, ok := PolicyPresets[policies.Size]; policies.Size != "" && !ok {
+		errs = append(errs, invalidPolicySizeError(policies.Size, []string{yamlJoin(policiesPath, "size")}))
+	}
+	if q := policies.Quota; q != nil {
+		quotaPath := yamlJoin(policiesPath, "quota")
+		errs = append(errs, validateQuantities(quotaPath, map[string]string{"cpu": q.CPU, "memory": q.Memory})...)
+		if q.Pods < 0 {
+			errs = append(errs, apis.ErrInvalidValue(q.Pods, yamlJoin(quotaPath, "pods")))
+		}
+	}
+	limitsPath := yamlJoin(policiesPath, "limits")
+	if l := policies.Limits; l != nil {
+		limitsErrs := validateQuantities(limitsPath, map[string]string{
+			"cpu":            l.CPU,
+			"memory":         l.Memory,
+			"request_cpu":    l.RequestCPU,
+			"request_memory": l.RequestMemory,
+		})
+		if len(limitsErrs) > 0 {
+			return append(errs, limitsErrs...)
+		}
+	}
+	return append(errs, validateRequests(policies.GetLimits(), limitsPath)...)
+}
+
+// validateRequests checks that the requests don't exceed the limits, once the
+// limits of the preset for the size are overridden.
+func validateRequests(l *Limits, path string) []error {
+	if l == nil {
+		return nil
+	}
+	errs := []error{}
+	for _, q := range []struct {
+		field, request, limit string
+	}{
+		{"request_cpu", l.RequestCPU, l.CPU},
+		{"request_memory", l.RequestMemory, l.Memory},
+	} {
+		if q.request == "" || q.limit == "" {
+			continue
+		}
+		request, err := resource.ParseQuantity(q.request)
+		if err != nil {
+			continue
+		}
+		limit, err := resource.ParseQuantity(q.limit)
+		if err != nil {
+			continue
+		}
+		if request.Cmp(limit) > 0 {
+			errs = append(errs, requestExceedsLimitError(q.request, q.limit, []string{yamlJoin(path, q.field)}))
+		}
+	}
+	return errs
+}
+
+// validateComponents checks that the components are paths within the GitOps
+// repository.
+func validateComponents(components []string, path string) []error {
+	errs := []error{}
+	for _, c := range components {
+		if !isRepoPath(c) {
+			errs = append(errs, invalidComponentError(c, []string{yamlJoin(path, "components")}))
+		}
+	}
+	return errs
+}
+
+// isRepoPath returns true if the path is a relative path within the GitOps
+// repository.
+func isRepoPath(path string) bool {
+	cleaned := filepath.ToSlash(filepath.Clean(path))
+	return path != "" && !filepath.IsAbs(path) && cleaned != "." && cleaned != ".." && !strings.HasPrefix(cleaned, "../")
+}
+
+// validateLayout checks that the layout templates are valid, and that they
+// give each environment, app and service, and the configuration, a unique
+// path within the repository.
+func validateLayout(m *Manifest) []error {
+	l := m.GetLayout()
+	if l == nil {
+		return nil
+	}
+	errs := []error{}
+	layoutPath := "config.layout"
+	sample := LayoutData{
+		Cluster:         "cluster",
+		Environment:     "env",
+		Namespace:       "ns",
+		Application:     "app",
+		Service:         "svc",
+		Name:            "cicd",
+		EnvironmentPath: "environments/env",
+		ApplicationPath: "environments/env/apps/app",
+	}
+	templates := []struct {
+		field    string
+		template string
+	}{
+		{"environment", l.Environment},
+		{"application", l.Application},
+		{"service", l.Service},
+		{"config", l.Config},
+	}
+	for _, t := range templates {
+		if t.template == "" {
+			continue
+		}
+		if _, err := executeLayoutTemplate(t.template, sample); err != nil {
+			errs = append(errs, invalidLayoutError(t.template, err.Error(), []string{yamlJoin(layoutPath, t.field)}))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	paths := map[string]string{}
+	add := func(path, owner string) {
+		path = filepath.ToSlash(path)
+		if !isRepoPath(path) {
+			errs = append(errs, invalidLayoutError(path, "the layout must give a relative path within the GitOps repository", []string{owner}))
+			return
+		}
+		if previous, ok := paths[path]; ok {
+			errs = append(errs, duplicateLayoutPathError(path, []string{previous, owner}))
+			return
+		}
+		paths[path] = owner
+	}
+	if cfg := m.GetPipelinesConfig(); cfg != nil {
+		add(l.PathForPipelines(cfg), yamlPath(PathForPipelines(cfg)))
+	}
+	if m.GetArgoCDConfig() != nil {
+		add(l.PathForArgoCD(), yamlPath(PathForArgoCD()))
+	}
+	for _, env := range m.Environments {
+		add(l.PathForEnvironment(env), yamlPath(PathForEnvironment(env)))
+		for _, app := range env.Apps {
+			add(l.PathForApplication(env, app), yamlPath(PathForApplication(env, app)))
+			for _, svc := range app.Services {
+				add(l.PathForService(app, env, svc.Name), yamlPath(PathForService(app, env, svc.Name)))
+			}
+		}
+	}
+	return errs
+}
+
+func validateQuantities(path string, quantities map[string]string) []error {
+	errs := []error{}
+	fields := []string{}
+	for k := range quantities {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if quantities[field] == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(quantities[field]); err != nil {
+			errs = append(errs, invalidQuantityError(quantities[field], []string{yamlJoin(path, field)}))
+		}
+	}
+	return errs
+}
+
 func (vv *validateVisitor) validateConfig(manifest *Manifest) []error {
 	errs := []error{}
 	if manifest.Config != nil {
@@ -218,12 +433,27 @@ func (vv *validateVisitor) validateConfig(manifest *Manifest) []error {
 			if err := validateName(manifest.Config.Pipelines.Name, yamlPath(PathForPipelines(manifest.Config.Pipelines))); err != nil {
 				errs = append(errs, err)
 			}
+			if err := validateRBACMode(manifest.Config.Pipelines.RBACMode, yamlJoin(yamlPath(PathForPipelines(manifest.Config.Pipelines)), "rbac_mode")); err != nil {
+				errs = append(errs, err)
+			}
 			vv.configNames[manifest.Config.Pipelines.Name] = true
 		}
 	}
 	return errs
 }
 
+func validateRBACMode(mode, path string) *apis.FieldError {
+	switch mode {
+	case "", DefaultRBACMode, MinimalRBACMode:
+		return nil
+	}
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid RBAC mode %q", mode),
+		Details: fmt.Sprintf("the RBAC mode must be one of %s or %s", DefaultRBACMode, MinimalRBACMode),
+		Paths:   []string{path},
+	}
+}
+
 func validateName(name, path string) *apis.FieldError {
 	err := validation.NameIsDNS1035Label(name, true)
 	if len(err) > 0 {
@@ -277,6 +507,60 @@ func duplicateFieldsError(fields, paths []string) *apis.FieldError {
 	}
 }
 
+func invalidPolicySizeError(size string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid policy size %q", size),
+		Details: "the size must be one of small, medium or large",
+		Paths:   paths,
+	}
+}
+
+func invalidQuantityError(quantity string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid quantity %q", quantity),
+		Details: "quantities must be Kubernetes resource quantities e.g. 500m or 1Gi",
+		Paths:   paths,
+	}
+}
+
+func requestExceedsLimitError(request, limit string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("request %q exceeds the limit %q", request, limit),
+		Details: "requests must not exceed the limits, including the limits of the policy size",
+		Paths:   paths,
+	}
+}
+
+func invalidComponentError(component string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid component %q", component),
+		Details: "components must be relative paths within the GitOps repository",
+		Paths:   paths,
+	}
+}
+
+func invalidLayoutError(value, details string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("invalid layout %q", value),
+		Details: details,
+		Paths:   paths,
+	}
+}
+
+func duplicateLayoutPathError(path string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("the layout gives multiple entries the path %q", path),
+		Paths:   paths,
+	}
+}
+
+func namespaceClashError(ns string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("multiple environments deploy to namespace %q on the same cluster", ns),
+		Paths:   paths,
+	}
+}
+
 func missingServiceError(app string, paths []string) *apis.FieldError {
 	return &apis.FieldError{
 		Message: fmt.Sprintf("missing service app %q", app),
@@ -291,9 +575,9 @@ func duplicateSourceError(url string, paths []string) *apis.FieldError {
 	}
 }
 
-func inconsistentGitTypeError(gitType, serviceURL string, paths []string) *apis.FieldError {
+func unknownGitDriverError(serviceURL string, err error, paths []string) *apis.FieldError {
 	return &apis.FieldError{
-		Message: fmt.Sprintf("service URL must be a %s repository: %v", gitType, serviceURL),
+		Message: fmt.Sprintf("unable to identify the Git driver for service URL %s, add the host to config.git.drivers: %v", serviceURL, err),
 		Paths:   paths,
 	}
 }
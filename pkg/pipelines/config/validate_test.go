@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -12,6 +13,7 @@ import (
 
 const (
 	DNS1035Error = "a DNS-1035 label must consist of lower case alphanumeric characters or '-', start with an alphabetic character, and end with an alphanumeric character (e.g. 'my-name',  or 'abc-123', regex used for validation is '[a-z]([-a-z0-9]*[a-z0-9])?')"
+	DNS1123Error = "a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')"
 )
 
 var validateTests = []struct {
@@ -20,14 +22,19 @@ var validateTests = []struct {
 	wantErr  error
 }{
 	{
-		"service repo URL must be the same Git type as the GitOps URL",
-		"testdata/svc_git_type_mismatch.yaml",
+		"service repo URL must be on a Git host with a known driver",
+		"testdata/svc_unknown_git_driver.yaml",
 		multierror.Join(
 			[]error{
-				inconsistentGitTypeError("github", "https://gitlab.com/myproject/myservice.git", []string{"environments.test-dev.apps.bus.services.bus-svc"}),
+				unknownGitDriverError("https://git.example.com/myproject/myservice.git", errors.New("unable to identify driver from hostname: git.example.com"), []string{"environments.test-dev.apps.bus.services.bus-svc"}),
 			},
 		),
 	},
+	{
+		"service repos can use different Git providers to the GitOps repo",
+		"testdata/svc_mixed_git_providers.yaml",
+		nil,
+	},
 	{
 		"Environment Duplicate Name entry",
 		"testdata/environment_config_name.yaml",
@@ -70,6 +77,15 @@ var validateTests = []struct {
 			missingFieldsError([]string{"integration"}, []string{"environments.development.apps.app-1.services.service-1.pipelines"}),
 		}),
 	},
+	{
+		"Invalid previous webhook secret name error",
+		"testdata/previous_secret_name_error.yaml",
+		multierror.Join(
+			[]error{
+				invalidNameError("webhook-secret.previous", DNS1035Error, []string{"environments.development.apps.app-1.services.service-1.webhook.previous_secret.name"}),
+			},
+		),
+	},
 	{
 		"Missing service and config repo from application",
 		"testdata/missing_service_error.yaml",
@@ -89,6 +105,77 @@ var validateTests = []struct {
 			},
 		),
 	},
+	{
+		"environment namespace errors",
+		"testdata/environment_namespace_error.yaml",
+		multierror.Join(
+			[]error{
+				invalidNameError("qa.shop", DNS1123Error, []string{"environments.qa.namespace"}),
+				namespaceClashError("shop", []string{"environments.dev", "environments.stage"}),
+				invalidEnvironment("test", "Environment namespace cannot be the same as a config name.", []string{"environments.test.namespace"}),
+			},
+		),
+	},
+	{
+		"environment policies errors",
+		"testdata/environment_policies_error.yaml",
+		multierror.Join(
+			[]error{
+				invalidPolicySizeError("huge", []string{"environments.dev.policies.size"}),
+				invalidQuantityError("two", []string{"environments.dev.policies.quota.cpu"}),
+				apis.ErrInvalidValue(-1, "environments.dev.policies.quota.pods"),
+				invalidQuantityError("1GB", []string{"environments.dev.policies.limits.memory"}),
+			},
+		),
+	},
+	{
+		"environment policies requests errors",
+		"testdata/environment_policies_requests_error.yaml",
+		multierror.Join(
+			[]error{
+				requestExceedsLimitError("1", "500m", []string{"environments.dev.policies.limits.request_cpu"}),
+				requestExceedsLimitError("512Mi", "256Mi", []string{"environments.stage.policies.limits.request_memory"}),
+			},
+		),
+	},
+	{
+		"environment components errors",
+		"testdata/environment_components_error.yaml",
+		multierror.Join(
+			[]error{
+				invalidComponentError("/components/tracing", []string{"environments.dev.components"}),
+				invalidComponentError("../components/logging", []string{"environments.dev.components"}),
+			},
+		),
+	},
+	{
+		"layout template errors",
+		"testdata/layout_template_error.yaml",
+		multierror.Join(
+			[]error{
+				invalidLayoutError("clusters/{{ .Cluster }/{{ .Environment }}", layoutTemplateError("clusters/{{ .Cluster }/{{ .Environment }}"), []string{"config.layout.environment"}),
+				invalidLayoutError("{{ .Svc }}", layoutTemplateError("{{ .Svc }}"), []string{"config.layout.service"}),
+			},
+		),
+	},
+	{
+		"layout duplicate path error",
+		"testdata/layout_duplicate_error.yaml",
+		multierror.Join(
+			[]error{
+				duplicateLayoutPathError("apps/shop", []string{"environments.dev.apps.shop", "environments.stage.apps.shop"}),
+			},
+		),
+	},
+	{
+		"invalid RBAC mode error",
+		"testdata/rbac_mode_error.yaml",
+		multierror.Join(
+			[]error{
+				validateRBACMode("strict", "config.cicd.rbac_mode"),
+			},
+		),
+	},
 	{
 		"duplicate application name error",
 		"testdata/duplicate_application.yaml",
@@ -149,6 +236,11 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func layoutTemplateError(t string) string {
+	_, err := executeLayoutTemplate(t, LayoutData{})
+	return err.Error()
+}
+
 func matchMultiErrors(t *testing.T, a, b error) error {
 	t.Helper()
 	if a == nil || b == nil {
@@ -1,9 +1,13 @@
 package deployment
 
 import (
+	"fmt"
+	"strings"
+
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 )
@@ -29,6 +33,19 @@ func Env(env []corev1.EnvVar) PodSpecFunc {
 	}
 }
 
+// ParseEnvVars parses environment variables of the form KEY=VAL.
+func ParseEnvVars(env []string) ([]corev1.EnvVar, error) {
+	vars := []corev1.EnvVar{}
+	for _, v := range env {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid environment variable %q, must be of the form KEY=VAL", v)
+		}
+		vars = append(vars, corev1.EnvVar{Name: parts[0], Value: parts[1]})
+	}
+	return vars, nil
+}
+
 // Command configures the command for the first container in the PodSpec.
 func Command(s []string) PodSpecFunc {
 	return func(c *corev1.PodSpec) {
@@ -46,6 +63,35 @@ func ContainerPort(p int32) PodSpecFunc {
 	}
 }
 
+// ReadinessProbe configures an HTTP readiness probe for the first container in
+// the PodSpec, with the path and port to probe.
+func ReadinessProbe(path string, port int32) PodSpecFunc {
+	return func(c *corev1.PodSpec) {
+		c.Containers[0].ReadinessProbe = &corev1.Probe{
+			Handler: corev1.Handler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: path,
+					Port: intstr.FromInt(int(port)),
+				},
+			},
+		}
+	}
+}
+
+// Resources configures the resource requests and limits for the first
+// container in the PodSpec.
+func Resources(r corev1.ResourceRequirements) PodSpecFunc {
+	return func(c *corev1.PodSpec) {
+		c.Containers[0].Resources = r
+	}
+}
+
+// Replicas sets the number of replicas for the Deployment.
+func Replicas(d *appsv1.Deployment, n int32) *appsv1.Deployment {
+	d.Spec.Replicas = ptr32(n)
+	return d
+}
+
 // Create creates and returns a Deployment with the specified configuration.
 func Create(partOf, ns, name, image string, opts ...PodSpecFunc) *appsv1.Deployment {
 	return &appsv1.Deployment{
@@ -5,10 +5,13 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/test"
 )
 
 const (
@@ -97,6 +100,29 @@ func TestPodTemplateEnv(t *testing.T) {
 	}
 }
 
+func TestParseEnvVars(t *testing.T) {
+	envTests := []struct {
+		env     []string
+		want    []corev1.EnvVar
+		wantErr string
+	}{
+		{[]string{"A=b", "C=d=e", "F="}, []corev1.EnvVar{{Name: "A", Value: "b"}, {Name: "C", Value: "d=e"}, {Name: "F"}}, ""},
+		{[]string{"A"}, nil, `invalid environment variable "A", must be of the form KEY=VAL`},
+		{[]string{"=b"}, nil, `invalid environment variable "=b", must be of the form KEY=VAL`},
+	}
+
+	for _, tt := range envTests {
+		got, err := ParseEnvVars(tt.env)
+		if !test.ErrorMatch(t, tt.wantErr, err) {
+			t.Errorf("ParseEnvVars(%v) error got %v, want %v", tt.env, err, tt.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("ParseEnvVars(%v) failed:\n%s", tt.env, diff)
+		}
+	}
+}
+
 func TestPodTemplateCommand(t *testing.T) {
 	spec := podTemplate(testComponentPartOf, testComponent, testImage, Command([]string{"/usr/local/bin/test"}))
 
@@ -152,3 +178,41 @@ func TestPodTemplateContainerPort(t *testing.T) {
 		t.Fatalf("podTemplate diff: %s", diff)
 	}
 }
+
+func TestPodTemplateReadinessProbe(t *testing.T) {
+	spec := podTemplate(testComponentPartOf, testComponent, testImage, ContainerPort(80), ReadinessProbe("/healthz", 80))
+
+	want := &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: "/healthz",
+				Port: intstr.FromInt(80),
+			},
+		},
+	}
+	if diff := cmp.Diff(want, spec.Spec.Containers[0].ReadinessProbe); diff != "" {
+		t.Fatalf("readiness probe diff: %s", diff)
+	}
+}
+
+func TestPodTemplateResources(t *testing.T) {
+	r := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+	}
+
+	spec := podTemplate(testComponentPartOf, testComponent, testImage, Resources(r))
+
+	if diff := cmp.Diff(r, spec.Spec.Containers[0].Resources, cmp.Comparer(func(x, y resource.Quantity) bool {
+		return x.Cmp(y) == 0
+	})); diff != "" {
+		t.Fatalf("resources diff: %s", diff)
+	}
+}
+
+func TestReplicas(t *testing.T) {
+	d := Replicas(Create(testComponentPartOf, "", testComponent, testImage), 3)
+
+	if r := *d.Spec.Replicas; r != 3 {
+		t.Fatalf("Replicas() got %d, want 3", r)
+	}
+}
@@ -0,0 +1,315 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+	argoappv1 "github.com/redhat-developer/kam/pkg/pipelines/argocd/v1alpha1"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+)
+
+var applicationsGVR = schema.GroupVersionResource{
+	Group:    "argoproj.io",
+	Version:  "v1alpha1",
+	Resource: "applications",
+}
+
+// argoCDErrorConditions are the Application conditions that indicate that
+// Argo CD can't generate the manifests from the repository.
+var argoCDErrorConditions = map[string]bool{
+	argoappv1.ApplicationConditionComparisonError:  true,
+	argoappv1.ApplicationConditionInvalidSpecError: true,
+	argoappv1.ApplicationConditionUnknownError:     true,
+}
+
+func (c *checker) checkNamespace(ns string) Result {
+	check := "CI/CD namespace"
+	namespace, err := c.kubeClient.CoreV1().Namespaces().Get(context.Background(), ns, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fail(check, fmt.Sprintf("namespace %s does not exist", ns),
+			c.cicdApplyHint(ns))
+	}
+	if err != nil {
+		return warn(check, fmt.Sprintf("unable to get namespace %s: %v", ns, err), "Check that you are logged in to the cluster")
+	}
+	if namespace.Status.Phase != corev1.NamespaceActive {
+		return fail(check, fmt.Sprintf("namespace %s is %s", ns, namespace.Status.Phase), "Wait for the namespace to be deleted and apply the CI/CD configuration again")
+	}
+	return pass(check, fmt.Sprintf("namespace %s is active", ns))
+}
+
+func (c *checker) checkEventListener(ns string) Result {
+	check := "EventListener"
+	pods, err := c.kubeClient.CoreV1().Pods(ns).List(context.Background(), metav1.ListOptions{
+		LabelSelector: "eventlistener=" + eventlisteners.EventListenerName,
+	})
+	if err != nil {
+		return warn(check, fmt.Sprintf("unable to list pods in %s: %v", ns, err), "Check that you are logged in to the cluster")
+	}
+	if len(pods.Items) == 0 {
+		return fail(check, fmt.Sprintf("no pods found for EventListener %s in %s", eventlisteners.EventListenerName, ns),
+			"Check that OpenShift Pipelines is installed and look at the status of the EventListener with 'oc describe eventlistener "+eventlisteners.EventListenerName+" -n "+ns+"'")
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning && podReady(&pod) {
+			return pass(check, fmt.Sprintf("EventListener pod %s is running", pod.Name))
+		}
+	}
+	return fail(check, fmt.Sprintf("no EventListener pods are ready in %s", ns),
+		"Look at the EventListener pod logs with 'oc logs -l eventlistener="+eventlisteners.EventListenerName+" -n "+ns+"'")
+}
+
+// checkRoute returns the result and, if the route exists, the URL that
+// webhooks should be delivered to.
+func (c *checker) checkRoute(ns string) (Result, string) {
+	check := "EventListener route"
+	name := eventlisteners.GitOpsWebhookEventListenerRouteName
+	route, err := c.routeClient.Routes(ns).Get(context.Background(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fail(check, fmt.Sprintf("route %s does not exist in %s", name, ns),
+			c.cicdApplyHint(ns)), ""
+	}
+	if err != nil {
+		return warn(check, fmt.Sprintf("unable to get route %s: %v", name, err), "Check that you are logged in to the cluster"), ""
+	}
+	listenerURL := listenerURLForRoute(route)
+	if !routeAdmitted(route) {
+		return fail(check, fmt.Sprintf("route %s has not been admitted by a router", name),
+			"Look at the route status with 'oc describe route "+name+" -n "+ns+"'"), listenerURL
+	}
+	return pass(check, fmt.Sprintf("route %s is admitted at %s", name, listenerURL)), listenerURL
+}
+
+func (c *checker) checkWebhookSecrets(m *config.Manifest) []Result {
+	cfg := m.GetPipelinesConfig()
+	results := []Result{}
+	if m.GitOpsURL != "" {
+		results = append(results, c.checkWebhookSecret("GitOps repository", cfg.Name, eventlisteners.GitOpsWebhookSecret))
+	}
+	for _, env := range m.Environments {
+		for _, app := range env.Apps {
+			for _, svc := range app.Services {
+				if svc.SourceURL == "" || svc.Webhook == nil || svc.Webhook.Secret == nil {
+					continue
+				}
+				ns := svc.Webhook.Secret.Namespace
+				if ns == "" {
+					ns = cfg.Name
+				}
+				results = append(results, c.checkWebhookSecret(fmt.Sprintf("service %s in environment %s", svc.Name, env.Name), ns, svc.Webhook.Secret.Name))
+			}
+		}
+	}
+	return results
+}
+
+func (c *checker) checkWebhookSecret(owner, ns, name string) Result {
+	check := "Webhook secret for " + owner
+	secret, err := c.kubeClient.CoreV1().Secrets(ns).Get(context.Background(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fail(check, fmt.Sprintf("secret %s does not exist in %s", name, ns),
+			"Encrypt the generated secrets in the secrets folder and apply them to the cluster")
+	}
+	if err != nil {
+		return warn(check, fmt.Sprintf("unable to get secret %s: %v", name, err), "Check that you are logged in to the cluster")
+	}
+	if len(secret.Data[eventlisteners.WebhookSecretKey]) == 0 {
+		return fail(check, fmt.Sprintf("secret %s has no %s key", name, eventlisteners.WebhookSecretKey),
+			"Recreate the secret with a "+eventlisteners.WebhookSecretKey+" key and apply it to the cluster")
+	}
+	return pass(check, fmt.Sprintf("secret %s exists in %s", name, ns))
+}
+
+func (c *checker) checkWebhooks(m *config.Manifest, listenerURL string) []Result {
+	results := []Result{}
+	for _, repoURL := range webhookRepositories(m) {
+		check := "Webhook for " + repoURL
+		if listenerURL == "" {
+			results = append(results, warn(check, "skipped because the EventListener route was not found", "Fix the EventListener route first"))
+			continue
+		}
+		results = append(results, c.checkWebhook(check, repoURL, listenerURL, m))
+	}
+	return results
+}
+
+func (c *checker) checkWebhook(check, repoURL, listenerURL string, m *config.Manifest) Result {
+	token, err := c.getAccessToken(repoURL)
+	if err != nil {
+		return warn(check, fmt.Sprintf("unable to find an access token: %v", err), "Pass a token with --git-host-access-token")
+	}
+	repo, err := c.newRepository(repoURL, token)
+	if err != nil {
+		return warn(check, fmt.Sprintf("unable to access the repository: %v", err), "Check the repository URL in pipelines.yaml")
+	}
+	ids, err := repo.ListWebhooks(listenerURL)
+	if err != nil {
+		return warn(check, fmt.Sprintf("unable to list webhooks: %v", err), "Check that the access token can read the repository webhooks")
+	}
+	if len(ids) == 0 {
+		return fail(check, fmt.Sprintf("no webhook delivers to %s", listenerURL), webhookCreateHint(m, repoURL))
+	}
+	return pass(check, fmt.Sprintf("webhook %s delivers to %s", strings.Join(ids, ", "), listenerURL))
+}
+
+func (c *checker) checkServiceAccount(cfg *config.PipelinesConfig) Result {
+	check := "Pipeline ServiceAccount"
+	filename := filepath.Join(c.pipelinesFolderPath, c.layout.PathForPipelines(cfg), "base", pipelines.ServiceAccountPath)
+	data, err := afero.ReadFile(c.fs, filename)
+	if err != nil {
+		return warn(check, fmt.Sprintf("unable to read %s: %v", filename, err), "Run 'kam build' to regenerate the CI/CD configuration")
+	}
+	want := corev1.ServiceAccount{}
+	if err := yaml.Unmarshal(data, &want); err != nil {
+		return warn(check, fmt.Sprintf("unable to parse %s: %v", filename, err), "Run 'kam build' to regenerate the CI/CD configuration")
+	}
+	ns := want.Namespace
+	if ns == "" {
+		ns = cfg.Name
+	}
+	sa, err := c.kubeClient.CoreV1().ServiceAccounts(ns).Get(context.Background(), want.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fail(check, fmt.Sprintf("ServiceAccount %s does not exist in %s", want.Name, ns),
+			c.cicdApplyHint(cfg.Name))
+	}
+	if err != nil {
+		return warn(check, fmt.Sprintf("unable to get ServiceAccount %s: %v", want.Name, err), "Check that you are logged in to the cluster")
+	}
+	missing := missingSecrets(want.Secrets, sa.Secrets)
+	if len(missing) > 0 {
+		return fail(check, fmt.Sprintf("ServiceAccount %s is missing secrets: %s", want.Name, strings.Join(missing, ", ")),
+			c.cicdApplyHint(cfg.Name))
+	}
+	return pass(check, fmt.Sprintf("ServiceAccount %s has the expected secrets", want.Name))
+}
+
+func (c *checker) checkArgoCD(m *config.Manifest) Result {
+	check := "Argo CD repository access"
+	argoCDConfig := m.GetArgoCDConfig()
+	if argoCDConfig == nil || argoCDConfig.Namespace == "" {
+		return warn(check, "no Argo CD namespace is configured in the manifest", "Add config.argocd.namespace to pipelines.yaml")
+	}
+	u, err := c.dynamicClient.Resource(applicationsGVR).Namespace(argoCDConfig.Namespace).Get(context.Background(), argocd.RootApplicationName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fail(check, fmt.Sprintf("Application %s does not exist in %s", argocd.RootApplicationName, argoCDConfig.Namespace),
+			fmt.Sprintf("Apply the Argo CD configuration with 'oc apply -k %s'", filepath.ToSlash(c.layout.PathForArgoCD())))
+	}
+	if err != nil {
+		return warn(check, fmt.Sprintf("unable to get Application %s: %v", argocd.RootApplicationName, err), "Check that OpenShift GitOps is installed")
+	}
+	app := argoappv1.Application{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &app); err != nil {
+		return warn(check, fmt.Sprintf("unable to parse Application %s: %v", argocd.RootApplicationName, err), "Check that OpenShift GitOps is installed")
+	}
+	for _, cond := range app.Status.Conditions {
+		if argoCDErrorConditions[cond.Type] {
+			return fail(check, fmt.Sprintf("Application %s has a %s: %s", argocd.RootApplicationName, cond.Type, cond.Message),
+				"Check that the GitOps repository URL is correct, and that Argo CD has credentials for it if it is private")
+		}
+	}
+	return pass(check, fmt.Sprintf("Application %s can read %s", argocd.RootApplicationName, app.Spec.Source.RepoURL))
+}
+
+// webhookRepositories returns the unique repositories that should deliver
+// webhooks to the EventListener.
+func webhookRepositories(m *config.Manifest) []string {
+	repos := map[string]bool{}
+	if m.GitOpsURL != "" {
+		repos[m.GitOpsURL] = true
+	}
+	for _, env := range m.Environments {
+		for _, app := range env.Apps {
+			for _, svc := range app.Services {
+				if svc.SourceURL != "" {
+					repos[svc.SourceURL] = true
+				}
+			}
+		}
+	}
+	urls := []string{}
+	for k := range repos {
+		urls = append(urls, k)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+func webhookCreateHint(m *config.Manifest, repoURL string) string {
+	if repoURL == m.GitOpsURL {
+		return "Create the webhook with 'kam webhook create --cicd'"
+	}
+	for _, env := range m.Environments {
+		for _, app := range env.Apps {
+			for _, svc := range app.Services {
+				if svc.SourceURL == repoURL {
+					return fmt.Sprintf("Create the webhook with 'kam webhook create --env-name %s --service-name %s'", env.Name, svc.Name)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func missingSecrets(want, got []corev1.ObjectReference) []string {
+	attached := map[string]bool{}
+	for _, s := range got {
+		attached[s.Name] = true
+	}
+	missing := []string{}
+	for _, s := range want {
+		if !attached[s.Name] {
+			missing = append(missing, s.Name)
+		}
+	}
+	return missing
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func routeAdmitted(route *routev1.Route) bool {
+	for _, ingress := range route.Status.Ingress {
+		for _, c := range ingress.Conditions {
+			if c.Type == routev1.RouteAdmitted && c.Status == corev1.ConditionTrue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func listenerURLForRoute(route *routev1.Route) string {
+	scheme := "http"
+	if route.Spec.TLS != nil {
+		scheme += "s"
+	}
+	return scheme + "://" + route.Spec.Host
+}
+
+// cicdApplyHint returns the hint to apply the configuration of the CI/CD
+// environment with the name.
+func (c *checker) cicdApplyHint(name string) string {
+	path := filepath.Join(c.layout.PathForPipelines(&config.PipelinesConfig{Name: name}), "overlays")
+	return fmt.Sprintf("Apply the CI/CD configuration with 'oc apply -k %s' or sync the cicd-app in Argo CD", filepath.ToSlash(path))
+}
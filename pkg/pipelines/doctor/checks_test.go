@@ -0,0 +1,334 @@
+package doctor
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+	fakeRouteClientset "github.com/openshift/client-go/route/clientset/versioned/fake"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeDynamicClient "k8s.io/client-go/dynamic/fake"
+	fakeKubeClientset "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+const (
+	testCICDNamespace   = "tst-cicd"
+	testArgoCDNamespace = "openshift-gitops"
+	testGitOpsURL       = "https://github.com/example/gitops.git"
+	testServiceURL      = "https://github.com/example/taxi.git"
+	testListenerURL     = "https://gitops-webhook-event-listener-route-tst-cicd.apps.example.com"
+)
+
+func TestRunWithHealthySetup(t *testing.T) {
+	c := makeChecker(t, healthyKubeObjects(), admittedRoute(), argoCDApplication(nil), validHooks())
+
+	results := c.run(testManifest())
+
+	want := []Result{
+		pass("CI/CD namespace", "namespace tst-cicd is active"),
+		pass("EventListener", "EventListener pod el-cicd-event-listener-abc is running"),
+		pass("EventListener route", "route gitops-webhook-event-listener-route is admitted at "+testListenerURL),
+		pass("Webhook secret for GitOps repository", "secret gitops-webhook-secret exists in tst-cicd"),
+		pass("Webhook secret for service taxi in environment dev", "secret webhook-secret-dev-taxi exists in tst-cicd"),
+		pass("Webhook for "+testGitOpsURL, "webhook 1 delivers to "+testListenerURL),
+		pass("Webhook for "+testServiceURL, "webhook 2 delivers to "+testListenerURL),
+		pass("Pipeline ServiceAccount", "ServiceAccount pipeline has the expected secrets"),
+		pass("Argo CD repository access", "Application argo-app can read "+testGitOpsURL),
+	}
+	if diff := cmp.Diff(want, results); diff != "" {
+		t.Fatalf("run() failed:\n%s", diff)
+	}
+	if Failed(results) {
+		t.Fatal("Failed() got true for a healthy setup")
+	}
+}
+
+func TestRunWithMissingResources(t *testing.T) {
+	c := makeChecker(t, nil, nil, nil, map[string][]string{})
+
+	results := c.run(testManifest())
+
+	want := []Result{
+		fail("CI/CD namespace", "namespace tst-cicd does not exist",
+			"Apply the CI/CD configuration with 'oc apply -k config/tst-cicd/overlays' or sync the cicd-app in Argo CD"),
+		fail("EventListener", "no pods found for EventListener cicd-event-listener in tst-cicd",
+			"Check that OpenShift Pipelines is installed and look at the status of the EventListener with 'oc describe eventlistener cicd-event-listener -n tst-cicd'"),
+		fail("EventListener route", "route gitops-webhook-event-listener-route does not exist in tst-cicd",
+			"Apply the CI/CD configuration with 'oc apply -k config/tst-cicd/overlays' or sync the cicd-app in Argo CD"),
+		fail("Webhook secret for GitOps repository", "secret gitops-webhook-secret does not exist in tst-cicd",
+			"Encrypt the generated secrets in the secrets folder and apply them to the cluster"),
+		fail("Webhook secret for service taxi in environment dev", "secret webhook-secret-dev-taxi does not exist in tst-cicd",
+			"Encrypt the generated secrets in the secrets folder and apply them to the cluster"),
+		warn("Webhook for "+testGitOpsURL, "skipped because the EventListener route was not found", "Fix the EventListener route first"),
+		warn("Webhook for "+testServiceURL, "skipped because the EventListener route was not found", "Fix the EventListener route first"),
+		fail("Pipeline ServiceAccount", "ServiceAccount pipeline does not exist in tst-cicd",
+			"Apply the CI/CD configuration with 'oc apply -k config/tst-cicd/overlays' or sync the cicd-app in Argo CD"),
+		fail("Argo CD repository access", "Application argo-app does not exist in openshift-gitops",
+			"Apply the Argo CD configuration with 'oc apply -k config/argocd'"),
+	}
+	if diff := cmp.Diff(want, results); diff != "" {
+		t.Fatalf("run() failed:\n%s", diff)
+	}
+	if !Failed(results) {
+		t.Fatal("Failed() got false for a missing setup")
+	}
+}
+
+func TestCheckRouteNotAdmitted(t *testing.T) {
+	route := admittedRoute()
+	route.Status.Ingress[0].Conditions[0].Status = corev1.ConditionFalse
+	c := makeChecker(t, nil, route, nil, nil)
+
+	result, listenerURL := c.checkRoute(testCICDNamespace)
+
+	want := fail("EventListener route", "route gitops-webhook-event-listener-route has not been admitted by a router",
+		"Look at the route status with 'oc describe route gitops-webhook-event-listener-route -n tst-cicd'")
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Fatalf("checkRoute() failed:\n%s", diff)
+	}
+	if listenerURL != testListenerURL {
+		t.Fatalf("checkRoute() got listener URL %q, want %q", listenerURL, testListenerURL)
+	}
+}
+
+func TestCheckWebhooksWithStaleHost(t *testing.T) {
+	c := makeChecker(t, nil, nil, nil, map[string][]string{
+		testGitOpsURL:  {},
+		testServiceURL: {},
+	})
+
+	results := c.checkWebhooks(testManifest(), testListenerURL)
+
+	want := []Result{
+		fail("Webhook for "+testGitOpsURL, "no webhook delivers to "+testListenerURL, "Create the webhook with 'kam webhook create --cicd'"),
+		fail("Webhook for "+testServiceURL, "no webhook delivers to "+testListenerURL, "Create the webhook with 'kam webhook create --env-name dev --service-name taxi'"),
+	}
+	if diff := cmp.Diff(want, results); diff != "" {
+		t.Fatalf("checkWebhooks() failed:\n%s", diff)
+	}
+}
+
+func TestCheckServiceAccountWithMissingSecrets(t *testing.T) {
+	objs := healthyKubeObjects()
+	for _, o := range objs {
+		if sa, ok := o.(*corev1.ServiceAccount); ok {
+			sa.Secrets = []corev1.ObjectReference{{Name: "regcred"}}
+		}
+	}
+	c := makeChecker(t, objs, nil, nil, nil)
+
+	result := c.checkServiceAccount(testManifest().GetPipelinesConfig())
+
+	want := fail("Pipeline ServiceAccount", "ServiceAccount pipeline is missing secrets: git-host-basic-auth-token",
+		"Apply the CI/CD configuration with 'oc apply -k config/tst-cicd/overlays' or sync the cicd-app in Argo CD")
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Fatalf("checkServiceAccount() failed:\n%s", diff)
+	}
+}
+
+func TestCheckArgoCDWithComparisonError(t *testing.T) {
+	c := makeChecker(t, nil, nil, argoCDApplication([]interface{}{
+		map[string]interface{}{
+			"type":    "ComparisonError",
+			"message": "rpc error: code = Unknown desc = authentication required",
+		},
+	}), nil)
+
+	result := c.checkArgoCD(testManifest())
+
+	want := fail("Argo CD repository access", "Application argo-app has a ComparisonError: rpc error: code = Unknown desc = authentication required",
+		"Check that the GitOps repository URL is correct, and that Argo CD has credentials for it if it is private")
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Fatalf("checkArgoCD() failed:\n%s", diff)
+	}
+}
+
+func TestCheckArgoCDWithoutConfig(t *testing.T) {
+	c := makeChecker(t, nil, nil, nil, nil)
+	m := testManifest()
+	m.Config.ArgoCD = nil
+
+	result := c.checkArgoCD(m)
+
+	want := warn("Argo CD repository access", "no Argo CD namespace is configured in the manifest", "Add config.argocd.namespace to pipelines.yaml")
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Fatalf("checkArgoCD() failed:\n%s", diff)
+	}
+}
+
+type fakeHookLister struct {
+	ids []string
+}
+
+func (f fakeHookLister) ListWebhooks(listenerURL string) ([]string, error) {
+	return f.ids, nil
+}
+
+func makeChecker(t *testing.T, kubeObjs []runtime.Object, route *routev1.Route, app *unstructured.Unstructured, hooks map[string][]string) *checker {
+	t.Helper()
+	fs := ioutils.NewMemoryFilesystem()
+	saPath := filepath.Join("/pipelines/config", testCICDNamespace, "base", pipelines.ServiceAccountPath)
+	err := afero.WriteFile(fs, saPath, []byte(`apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: pipeline
+  namespace: tst-cicd
+secrets:
+- name: regcred
+- name: git-host-basic-auth-token
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	routeObjs := []runtime.Object{}
+	if route != nil {
+		routeObjs = append(routeObjs, route)
+	}
+	dynamicObjs := []runtime.Object{}
+	if app != nil {
+		dynamicObjs = append(dynamicObjs, app)
+	}
+	return &checker{
+		fs:                  fs,
+		pipelinesFolderPath: "/pipelines",
+		accessToken:         "test-token",
+		kubeClient:          fakeKubeClientset.NewSimpleClientset(kubeObjs...),
+		routeClient:         fakeRouteClientset.NewSimpleClientset(routeObjs...).RouteV1(),
+		dynamicClient:       fakeDynamicClient.NewSimpleDynamicClient(runtime.NewScheme(), dynamicObjs...),
+		newRepository: func(rawURL, token string) (hookLister, error) {
+			ids, ok := hooks[rawURL]
+			if !ok {
+				return nil, fmt.Errorf("unknown repository %s", rawURL)
+			}
+			return fakeHookLister{ids: ids}, nil
+		},
+	}
+}
+
+func testManifest() *config.Manifest {
+	return &config.Manifest{
+		GitOpsURL: testGitOpsURL,
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: testCICDNamespace},
+			ArgoCD:    &config.ArgoCDConfig{Namespace: testArgoCDNamespace},
+		},
+		Environments: []*config.Environment{
+			{
+				Name: "dev",
+				Apps: []*config.Application{
+					{
+						Name: "app-taxi",
+						Services: []*config.Service{
+							{
+								Name:      "taxi",
+								SourceURL: testServiceURL,
+								Webhook: &config.Webhook{
+									Secret: &config.Secret{Name: "webhook-secret-dev-taxi", Namespace: testCICDNamespace},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func healthyKubeObjects() []runtime.Object {
+	webhookSecret := func(name string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testCICDNamespace},
+			Data:       map[string][]byte{"webhook-secret-key": []byte("secret")},
+		}
+	}
+	return []runtime.Object{
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: testCICDNamespace},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "el-cicd-event-listener-abc",
+				Namespace: testCICDNamespace,
+				Labels:    map[string]string{"eventlistener": "cicd-event-listener"},
+			},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		},
+		webhookSecret("gitops-webhook-secret"),
+		webhookSecret("webhook-secret-dev-taxi"),
+		&corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipeline", Namespace: testCICDNamespace},
+			Secrets: []corev1.ObjectReference{
+				{Name: "pipeline-token-abcde"},
+				{Name: "regcred"},
+				{Name: "git-host-basic-auth-token"},
+			},
+		},
+	}
+}
+
+func admittedRoute() *routev1.Route {
+	return &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gitops-webhook-event-listener-route",
+			Namespace: testCICDNamespace,
+		},
+		Spec: routev1.RouteSpec{
+			Host: "gitops-webhook-event-listener-route-tst-cicd.apps.example.com",
+			TLS:  &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge},
+		},
+		Status: routev1.RouteStatus{
+			Ingress: []routev1.RouteIngress{
+				{
+					Conditions: []routev1.RouteIngressCondition{
+						{Type: routev1.RouteAdmitted, Status: corev1.ConditionTrue},
+					},
+				},
+			},
+		},
+	}
+}
+
+func argoCDApplication(conditions []interface{}) *unstructured.Unstructured {
+	status := map[string]interface{}{}
+	if conditions != nil {
+		status["conditions"] = conditions
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "argoproj.io/v1alpha1",
+			"kind":       "Application",
+			"metadata": map[string]interface{}{
+				"name":      "argo-app",
+				"namespace": testArgoCDNamespace,
+			},
+			"spec": map[string]interface{}{
+				"source": map[string]interface{}{
+					"repoURL": testGitOpsURL,
+					"path":    "config/argocd",
+				},
+			},
+			"status": status,
+		},
+	}
+}
+
+func validHooks() map[string][]string {
+	return map[string][]string{
+		testGitOpsURL:  {"1"},
+		testServiceURL: {"2"},
+	}
+}
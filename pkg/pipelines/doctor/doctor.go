@@ -0,0 +1,153 @@
+package doctor
+
+import (
+	"errors"
+	"fmt"
+
+	routeclientset "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	"github.com/spf13/afero"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/accesstoken"
+	"github.com/redhat-developer/kam/pkg/pipelines/clientconfig"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/git"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+)
+
+// Status is the outcome of a single check.
+type Status string
+
+const (
+	// Pass indicates that the check found no problems.
+	Pass Status = "pass"
+	// Warn indicates that the check could not be completed, or found a
+	// problem that doesn't stop pipelines from running.
+	Warn Status = "warn"
+	// Fail indicates that the check found a problem that needs fixing.
+	Fail Status = "fail"
+)
+
+// Result is the outcome of a check, with a hint on how to fix any problem
+// that was found.
+type Result struct {
+	Check   string `json:"check"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+// Options is the configuration for the checks.
+type Options struct {
+	PipelinesFolderPath string
+	GitHostAccessToken  string
+}
+
+// hookLister is the part of git.Repository used to check webhooks.
+type hookLister interface {
+	ListWebhooks(listenerURL string) ([]string, error)
+}
+
+type checker struct {
+	fs                  afero.Fs
+	pipelinesFolderPath string
+	accessToken         string
+	kubeClient          kubernetes.Interface
+	routeClient         routeclientset.RouteV1Interface
+	dynamicClient       dynamic.Interface
+	newRepository       func(rawURL, token string) (hookLister, error)
+	layout              *config.Layout
+}
+
+// Run checks the health of the GitOps setup described by the manifest in the
+// pipelines folder against the current cluster and the Git hosting services.
+func Run(o *Options) ([]Result, error) {
+	fs := ioutils.NewFilesystem()
+	manifest, err := config.LoadManifest(fs, o.PipelinesFolderPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pipelines: %v", err)
+	}
+	if manifest.GetPipelinesConfig() == nil {
+		return nil, errors.New("failed to find a CI/CD environment in the manifest")
+	}
+
+	restConfig, err := clientconfig.GetRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	routeClient, err := routeclientset.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &checker{
+		fs:                  fs,
+		pipelinesFolderPath: o.PipelinesFolderPath,
+		accessToken:         o.GitHostAccessToken,
+		kubeClient:          kubeClient,
+		routeClient:         routeClient,
+		dynamicClient:       dynamicClient,
+		newRepository:       newGitRepository(manifest.GetDriverResolver()),
+		layout:              manifest.GetLayout(),
+	}
+	return c.run(manifest), nil
+}
+
+// Failed returns true if any of the results is a failure.
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) run(m *config.Manifest) []Result {
+	cfg := m.GetPipelinesConfig()
+	results := []Result{}
+	results = append(results, c.checkNamespace(cfg.Name))
+	results = append(results, c.checkEventListener(cfg.Name))
+	routeResult, listenerURL := c.checkRoute(cfg.Name)
+	results = append(results, routeResult)
+	results = append(results, c.checkWebhookSecrets(m)...)
+	results = append(results, c.checkWebhooks(m, listenerURL)...)
+	results = append(results, c.checkServiceAccount(cfg))
+	results = append(results, c.checkArgoCD(m))
+	return results
+}
+
+func (c *checker) getAccessToken(repoURL string) (string, error) {
+	if c.accessToken != "" {
+		return c.accessToken, nil
+	}
+	return accesstoken.GetAccessToken(repoURL)
+}
+
+func newGitRepository(drivers *scm.DriverResolver) func(rawURL, token string) (hookLister, error) {
+	return func(rawURL, token string) (hookLister, error) {
+		return git.NewRepository(rawURL, token, drivers)
+	}
+}
+
+func pass(check, message string) Result {
+	return Result{Check: check, Status: Pass, Message: message}
+}
+
+func warn(check, message, hint string) Result {
+	return Result{Check: check, Status: Warn, Message: message, Hint: hint}
+}
+
+func fail(check, message, hint string) Result {
+	return Result{Check: check, Status: Fail, Message: message, Hint: hint}
+}
@@ -3,13 +3,13 @@ package dryrun
 import (
 	"bytes"
 	"fmt"
+	"path/filepath"
 	"text/template"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
 )
 
 const scriptTemplate = `#!/bin/bash
-is_argocd=false
-argo_path="config/argocd"
-cicd_path="config/{{ .CICDEnv }}"
 cmd={{ .Cmd }}
 overall_exit=0
 
@@ -20,45 +20,28 @@ execute() {
     overall_exit=$e
   fi
 }
-
-if [[ -d "${argo_path}" ]]; then
-  printf "Apply $(basename ${argo_path}) applications\n"
-  execute "${argo_path}"
-  is_argocd=true
-fi
-
-printf "Apply $(basename ${cicd_path}) environment\n"
-execute "${cicd_path}/overlays"
-
-for dir in $(ls -d environments/*/); do
-  if ! $is_argocd; then
-    printf "Apply $(basename ${dir}) environment\n"
-    execute "${dir}env/overlays"
-  else
-    if [[ -d "${dir}apps" ]]; then
-      for app in $(ls -d ${dir}apps/*/); do
-        printf "Apply $(basename ${app}) application\n"
-        execute $app
-      done
-    else
-      printf "Apply $(basename ${dir}) environment\n"
-      execute "${dir}env/overlays"
-    fi
-  fi
-done
-
+{{ range .Steps }}
+printf "Apply {{ .Description }}\n"
+execute "{{ .Path }}"
+{{ end }}
 exit $overall_exit
 `
 
 type templateParam struct {
-	Cmd     string
-	CICDEnv string
+	Cmd   string
+	Steps []step
+}
+
+// step is a kustomization that the script applies.
+type step struct {
+	Description string
+	Path        string
 }
 
 // MakeScript will create a script that can dry-run/apply
-// across all environments/applications
-func MakeScript(command, cicdEnv string) (string, error) {
-	params := templateParam{CICDEnv: cicdEnv, Cmd: command}
+// across all environments/applications in the layout of the manifest
+func MakeScript(command string, m *config.Manifest) (string, error) {
+	params := templateParam{Cmd: command, Steps: scriptSteps(m)}
 	parsed, err := template.New("dryrun_script").Parse(scriptTemplate)
 	if err != nil {
 		return "", fmt.Errorf("unable to parse template: %v", err)
@@ -70,3 +53,31 @@ func MakeScript(command, cicdEnv string) (string, error) {
 	}
 	return buf.String(), nil
 }
+
+// scriptSteps returns the kustomizations to apply, the Argo CD applications
+// and the CI/CD configuration, and then the environments.
+//
+// With Argo CD, the apps of the environments are applied, as Argo CD applies
+// the environments.
+func scriptSteps(m *config.Manifest) []step {
+	layout := m.GetLayout()
+	steps := []step{}
+	argoCD := m.GetArgoCDConfig()
+	isArgoCD := argoCD != nil
+	if isArgoCD && argoCD.Namespace != "" {
+		steps = append(steps, step{Description: "argocd applications", Path: filepath.ToSlash(layout.PathForArgoCD())})
+	}
+	if cfg := m.GetPipelinesConfig(); cfg != nil {
+		steps = append(steps, step{Description: cfg.Name + " environment", Path: filepath.ToSlash(filepath.Join(layout.PathForPipelines(cfg), "overlays"))})
+	}
+	for _, env := range m.Environments {
+		if isArgoCD && len(env.Apps) > 0 {
+			for _, app := range env.Apps {
+				steps = append(steps, step{Description: app.Name + " application", Path: filepath.ToSlash(layout.PathForApplication(env, app))})
+			}
+			continue
+		}
+		steps = append(steps, step{Description: env.Name + " environment", Path: filepath.ToSlash(filepath.Join(layout.PathForEnvironment(env), "env", "overlays"))})
+	}
+	return steps
+}
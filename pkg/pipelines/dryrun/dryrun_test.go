@@ -8,6 +8,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/pkg/pipelines/namespaces"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
@@ -37,7 +39,7 @@ func TestMakeScriptWithArgo(t *testing.T) {
 
 	fs := ioutils.NewFilesystem()
 	setupGitOpsTree(t, fs, tempDir, true)
-	s, err := MakeScript("", "cicd")
+	s, err := MakeScript("", testManifest(true))
 	assertNoError(t, err)
 
 	want := logsWithArgoCD
@@ -53,7 +55,7 @@ func TestMakeScriptWithoutArgo(t *testing.T) {
 
 	fs := ioutils.NewFilesystem()
 	setupGitOpsTree(t, fs, tempDir, false)
-	s, err := MakeScript("", "cicd")
+	s, err := MakeScript("", testManifest(false))
 	assertNoError(t, err)
 
 	want := logsWithoutArgoCD
@@ -63,10 +65,48 @@ func TestMakeScriptWithoutArgo(t *testing.T) {
 	}
 }
 
+func TestScriptStepsWithLayout(t *testing.T) {
+	m := testManifest(true)
+	m.Config.Layout = &config.Layout{
+		Environment: "clusters/{{ .Environment }}",
+		Application: "{{ .EnvironmentPath }}/{{ .Application }}",
+		Config:      "gitops/{{ .Name }}",
+	}
+	m.Environments = append(m.Environments, &config.Environment{Name: "empty"})
+
+	want := []step{
+		{Description: "argocd applications", Path: "gitops/argocd"},
+		{Description: "cicd environment", Path: "gitops/cicd/overlays"},
+		{Description: "taxi application", Path: "clusters/dev/taxi"},
+		{Description: "go-app application", Path: "clusters/stage/go-app"},
+		{Description: "empty environment", Path: "clusters/empty/env/overlays"},
+	}
+	if diff := cmp.Diff(want, scriptSteps(m)); diff != "" {
+		t.Fatalf("scriptSteps() failed:\n%s", diff)
+	}
+}
+
+func testManifest(withArgoCD bool) *config.Manifest {
+	m := &config.Manifest{
+		GitOpsURL: "https://example.com/gitops.git",
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "cicd"},
+		},
+		Environments: []*config.Environment{
+			{Name: "dev", Apps: []*config.Application{{Name: "taxi"}}},
+			{Name: "stage", Apps: []*config.Application{{Name: "go-app"}}},
+		},
+	}
+	if withArgoCD {
+		m.Config.ArgoCD = &config.ArgoCDConfig{Namespace: "argocd"}
+	}
+	return m
+}
+
 func setupGitOpsTree(t *testing.T, fs afero.Fs, base string, withArgoCD bool) {
 	t.Helper()
 	// minimal resources to have a valid GitOps tree
-	script, err := MakeScript("", "cicd")
+	script, err := MakeScript("", testManifest(withArgoCD))
 	assertNoError(t, err)
 	files := res.Resources{
 		"environments/dev/env/overlays/kustomization.yaml":   res.Kustomization{Bases: []string{"../base"}},
@@ -0,0 +1,201 @@
+package pipelines
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	v1rbac "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resource"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/roles"
+)
+
+// dryRunVerbs are the verbs that kubectl apply uses for objects that exist,
+// and the verbs it uses to create the namespaced objects that don't.
+var dryRunVerbs = []string{"get", "patch"}
+
+// TestMinimalRBACCoversDryRun builds the kustomizations that the CI dry-run
+// applies, and checks that the Roles and the ClusterRole of the pipeline
+// ServiceAccount allow every object to be applied.
+func TestMinimalRBACCoversDryRun(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		RBACMode:             config.MinimalRBACMode,
+		OutputPath:           "/gitops",
+	}
+	out, err := GenerateBootstrap(params, fs)
+	fatalIfError(t, err)
+	fatalIfError(t, WriteOutput(fs, params.OutputPath, out))
+
+	objects := buildDryRunObjects(t, fs, params.OutputPath, []string{"config/argocd", "config/tst-cicd/overlays", "environments/*/env/overlays", "environments/*/apps/*"})
+	namespaceRules := map[string][]v1rbac.PolicyRule{}
+	clusterRules := []v1rbac.PolicyRule{}
+	for _, r := range objects {
+		switch {
+		case r.GetKind() == "Role" && r.GetName() == roles.RoleName:
+			role := &v1rbac.Role{}
+			decodeResource(t, r, role)
+			namespaceRules[r.GetNamespace()] = role.Rules
+		case r.GetKind() == "ClusterRole" && r.GetName() == roles.NamespacesClusterRoleName:
+			role := &v1rbac.ClusterRole{}
+			decodeResource(t, r, role)
+			clusterRules = role.Rules
+		}
+	}
+
+	for _, r := range objects {
+		gvk := r.GetGvk()
+		resource := pluralResource(gvk.Kind)
+		rules := clusterRules
+		if gvk.IsNamespaceableKind() {
+			rules = namespaceRules[r.GetNamespace()]
+		}
+		for _, verb := range dryRunVerbs {
+			if !rulesAllow(rules, gvk.Group, resource, r.GetName(), verb) {
+				t.Errorf("%s %s in namespace %q can't be applied: %s is not allowed", gvk.Kind, r.GetName(), r.GetNamespace(), verb)
+			}
+		}
+		if gvk.Kind == "RoleBinding" {
+			binding := &v1rbac.RoleBinding{}
+			decodeResource(t, r, binding)
+			if binding.RoleRef.Kind == "ClusterRole" && !rulesAllow(rules, "rbac.authorization.k8s.io", "clusterroles", binding.RoleRef.Name, "bind") {
+				t.Errorf("RoleBinding %s in namespace %q can't be applied: bind to ClusterRole %s is not allowed", r.GetName(), r.GetNamespace(), binding.RoleRef.Name)
+			}
+		}
+	}
+}
+
+// TestMinimalRBACAfterAddEnvironment checks that the ClusterRole on the cluster
+// doesn't allow the CI dry-run to apply the namespace of a new environment, so
+// an admin must apply it, and that the regenerated ClusterRole allows it.
+func TestMinimalRBACAfterAddEnvironment(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		RBACMode:             config.MinimalRBACMode,
+		OutputPath:           "/gitops",
+	}
+	out, err := GenerateBootstrap(params, fs)
+	fatalIfError(t, err)
+	fatalIfError(t, WriteOutput(fs, params.OutputPath, out))
+	applied := namespacesClusterRules(t, buildDryRunObjects(t, fs, params.OutputPath, []string{"config/tst-cicd/overlays"}))
+
+	fatalIfError(t, AddEnv(&EnvParameters{PipelinesFolderPath: params.OutputPath, EnvName: "tst-staging"}, fs))
+	regenerated := namespacesClusterRules(t, buildDryRunObjects(t, fs, params.OutputPath, []string{"config/tst-cicd/overlays"}))
+
+	for _, verb := range dryRunVerbs {
+		if rulesAllow(applied, "", "namespaces", "tst-staging", verb) {
+			t.Errorf("the applied ClusterRole allows %s of the new namespace", verb)
+		}
+		if !rulesAllow(regenerated, "", "namespaces", "tst-staging", verb) {
+			t.Errorf("the regenerated ClusterRole doesn't allow %s of the new namespace", verb)
+		}
+	}
+	if rulesAllow(regenerated, "", "namespaces", "tst-staging", "create") {
+		t.Error("the regenerated ClusterRole allows the creation of namespaces")
+	}
+}
+
+func namespacesClusterRules(t *testing.T, objects []*resource.Resource) []v1rbac.PolicyRule {
+	t.Helper()
+	for _, r := range objects {
+		if r.GetKind() == "ClusterRole" && r.GetName() == roles.NamespacesClusterRoleName {
+			role := &v1rbac.ClusterRole{}
+			decodeResource(t, r, role)
+			return role.Rules
+		}
+	}
+	t.Fatalf("failed to find the ClusterRole %s", roles.NamespacesClusterRoleName)
+	return nil
+}
+
+// buildDryRunObjects builds the kustomizations that match the patterns in the
+// path, and returns the objects.
+func buildDryRunObjects(t *testing.T, fs afero.Fs, path string, patterns []string) []*resource.Resource {
+	t.Helper()
+	kfs := filesys.MakeFsInMemory()
+	err := afero.Walk(fs, path, func(filename string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := afero.ReadFile(fs, filename)
+		if err != nil {
+			return err
+		}
+		return kfs.WriteFile(filename, data)
+	})
+	fatalIfError(t, err)
+
+	k := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	objects := []*resource.Resource{}
+	for _, pattern := range patterns {
+		dirs, err := afero.Glob(fs, filepath.Join(path, pattern))
+		fatalIfError(t, err)
+		for _, dir := range dirs {
+			built, err := k.Run(kfs, dir)
+			if err != nil {
+				t.Fatalf("failed to build %s: %v", dir, err)
+			}
+			objects = append(objects, built.Resources()...)
+		}
+	}
+	if len(objects) == 0 {
+		t.Fatal("no objects were built")
+	}
+	return objects
+}
+
+func decodeResource(t *testing.T, r *resource.Resource, v interface{}) {
+	t.Helper()
+	data, err := r.AsYAML()
+	fatalIfError(t, err)
+	fatalIfError(t, sigsyaml.Unmarshal(data, v))
+}
+
+func pluralResource(kind string) string {
+	resource := strings.ToLower(kind)
+	if strings.HasSuffix(resource, "y") {
+		return strings.TrimSuffix(resource, "y") + "ies"
+	}
+	return resource + "s"
+}
+
+func rulesAllow(rules []v1rbac.PolicyRule, group, resource, name, verb string) bool {
+	for _, rule := range rules {
+		if matches(rule.APIGroups, group) && matches(rule.Resources, resource) && matches(rule.Verbs, verb) &&
+			(len(rule.ResourceNames) == 0 || matches(rule.ResourceNames, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(values []string, value string) bool {
+	for _, v := range values {
+		if v == value || v == "*" {
+			return true
+		}
+	}
+	return false
+}
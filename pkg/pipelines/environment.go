@@ -6,7 +6,6 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
-	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 	"github.com/spf13/afero"
 )
 
@@ -15,41 +14,53 @@ type EnvParameters struct {
 	PipelinesFolderPath string
 	EnvName             string
 	Cluster             string
+	Namespace           string
 }
 
 // AddEnv adds a new environment to the pipelines file.
 func AddEnv(o *EnvParameters, appFs afero.Fs) error {
-	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	out, err := GenerateEnvironment(o, appFs)
 	if err != nil {
 		return err
 	}
+	return WriteOutput(appFs, o.PipelinesFolderPath, out)
+}
+
+// GenerateEnvironment generates the pipelines file with the new environment,
+// and the resources built from it, without writing them.
+func GenerateEnvironment(o *EnvParameters, appFs afero.Fs) (*Output, error) {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return nil, err
+	}
 	env := m.GetEnvironment(o.EnvName)
 	if env != nil {
-		return fmt.Errorf("environment %s already exists", o.EnvName)
+		return nil, fmt.Errorf("environment %s already exists", o.EnvName)
 	}
 	files := res.Resources{}
 	newEnv, err := newEnvironment(m, o.EnvName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if o.Cluster != "" {
 		newEnv.Cluster = o.Cluster
 	}
+	if o.Namespace != "" {
+		newEnv.Namespace = o.Namespace
+	}
 	m.Environments = append(m.Environments, newEnv)
 	files[pipelinesFile] = m
-	built, err := buildResources(appFs, m)
+	built, err := buildResources(appFs, m, o.PipelinesFolderPath)
 	if err != nil {
-		return fmt.Errorf("failed to build resources: %v", err)
+		return nil, fmt.Errorf("failed to build resources: %v", err)
 	}
-	files = res.Merge(built, files)
-	_, err = yaml.WriteResources(appFs, o.PipelinesFolderPath, files)
-	return err
+	return newOutput(res.Merge(built, files), nil), nil
 }
 
 func newEnvironment(m *config.Manifest, name string) (*config.Environment, error) {
 	pipelinesConfig := m.GetPipelinesConfig()
 	if pipelinesConfig != nil && m.GitOpsURL != "" {
-		r, err := scm.NewRepository(m.GitOpsURL)
+		r, err := scm.NewRepository(m.GitOpsURL, m.GetDriverResolver())
 		if err != nil {
 			return nil, err
 		}
@@ -102,6 +102,39 @@ func TestAddEnvWithClusterProvided(t *testing.T) {
 	}
 }
 
+func TestAddEnvWithNamespaceProvided(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+	pipelinesFilePath := filepath.ToSlash(filepath.Join(gitopsPath, pipelinesFile))
+	envParameters := EnvParameters{
+		PipelinesFolderPath: gitopsPath,
+		EnvName:             "prod-eu",
+		Namespace:           "shop",
+	}
+	_ = afero.WriteFile(fakeFs, pipelinesFilePath, []byte("environments:"), 0644)
+
+	if err := AddEnv(&envParameters, fakeFs); err != nil {
+		t.Fatalf("AddEnv() failed :%s", err)
+	}
+
+	got := mustReadFileAsMap(t, fakeFs, filepath.Join(gitopsPath, "environments/prod-eu/env/base/prod-eu-environment.yaml"))
+	if name := got["metadata"].(map[string]interface{})["name"]; name != "shop" {
+		t.Fatalf("environment namespace got %v, want shop", name)
+	}
+	got = mustReadFileAsMap(t, fakeFs, pipelinesFilePath)
+	want := map[string]interface{}{
+		"environments": []interface{}{
+			map[string]interface{}{
+				"name":      "prod-eu",
+				"namespace": "shop",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("written environments failed:\n%s", diff)
+	}
+}
+
 func TestAddEnvWithExistingName(t *testing.T) {
 	fakeFs := ioutils.NewMemoryFilesystem()
 	gitopsPath := afero.GetTempDir(fakeFs, "test")
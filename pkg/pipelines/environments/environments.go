@@ -2,15 +2,16 @@ package environments
 
 import (
 	"fmt"
-	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	"github.com/redhat-developer/kam/pkg/pipelines/namespaces"
+	"github.com/redhat-developer/kam/pkg/pipelines/policies"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/roles"
 	"github.com/spf13/afero"
@@ -40,6 +41,7 @@ type envBuilder struct {
 	appLinks        AppLinks
 	gitOpsRepoURL   string
 	repoPath        string
+	layout          *config.Layout
 }
 
 // Build generates a set of resources from the manifest, related to the
@@ -48,7 +50,7 @@ func Build(fs afero.Fs, m *config.Manifest, saName string, o AppLinks) (res.Reso
 	files := res.Resources{}
 	cfg := m.GetPipelinesConfig()
 
-	parsed, err := url.Parse(m.GitOpsURL)
+	parsed, err := giturl.Parse(m.GitOpsURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse GitOpsURL %q: %w", m.GitOpsURL, err)
 	}
@@ -62,13 +64,14 @@ func Build(fs afero.Fs, m *config.Manifest, saName string, o AppLinks) (res.Reso
 		appLinks:        o,
 		gitOpsRepoURL:   m.GitOpsURL,
 		repoPath:        repoPath,
+		layout:          m.GetLayout(),
 	}
 	return eb.files, m.Walk(eb)
 }
 
 func (b *envBuilder) Application(env *config.Environment, app *config.Application) error {
-	appPath := filepath.ToSlash(filepath.Join(config.PathForApplication(env, app)))
-	appFiles, err := filesForApplication(env, b.repoPath, appPath, app)
+	appPath := filepath.ToSlash(b.layout.PathForApplication(env, app))
+	appFiles, err := filesForApplication(b.layout, env, b.repoPath, appPath, app)
 	if err != nil {
 		return err
 	}
@@ -77,7 +80,7 @@ func (b *envBuilder) Application(env *config.Environment, app *config.Applicatio
 }
 
 func (b *envBuilder) Service(app *config.Application, env *config.Environment, svc *config.Service) error {
-	svcPath := config.PathForService(app, env, svc.Name)
+	svcPath := b.layout.PathForService(app, env, svc.Name)
 	svcFiles, err := filesForService(svcPath)
 	if err != nil {
 		return err
@@ -88,25 +91,34 @@ func (b *envBuilder) Service(app *config.Application, env *config.Environment, s
 	if b.pipelinesConfig == nil {
 		return nil
 	}
-	envBasePath := filepath.ToSlash(filepath.Join(config.PathForEnvironment(env), "env", "base"))
+	envBasePath := filepath.ToSlash(filepath.Join(b.layout.PathForEnvironment(env), "env", "base"))
 	envBindingPath := filepath.ToSlash(filepath.Join(envBasePath, fmt.Sprintf("%s-rolebinding.yaml", env.Name)))
 	if _, ok := b.files[envBindingPath]; !ok {
-		b.files[envBindingPath] = createRoleBinding(env, b.pipelinesConfig.Name, b.saName)
+		b.files[envBindingPath] = createRoleBinding(env, b.pipelinesConfig, b.saName)
+	}
+	if b.pipelinesConfig.IsMinimalRBAC() {
+		envRolePath := filepath.ToSlash(filepath.Join(envBasePath, fmt.Sprintf("%s-role.yaml", env.Name)))
+		b.files[envRolePath] = roles.CreateRole(meta.NamespacedName(env.GetNamespace(), roles.RoleName), roles.EnvironmentRules)
 	}
 	return nil
 }
 
 func (b *envBuilder) Environment(env *config.Environment) error {
-	envPath := filepath.ToSlash(filepath.Join(config.PathForEnvironment(env), "env"))
+	envPath := filepath.ToSlash(filepath.Join(b.layout.PathForEnvironment(env), "env"))
 	basePath := filepath.ToSlash(filepath.Join(envPath, "base"))
-	envFiles := filesForEnvironment(basePath, env, b.gitOpsRepoURL)
+	envFiles, err := filesForEnvironment(basePath, env, b.gitOpsRepoURL, b.pipelinesConfig)
+	if err != nil {
+		return err
+	}
 	kustomizedFilenames, err := ListFiles(b.fs, basePath)
 	if err != nil {
 		return fmt.Errorf("failed to list initial files for %s: %s", basePath, err)
 	}
-	envBindingPath := filepath.ToSlash(filepath.Join(basePath, fmt.Sprintf("%s-rolebinding.yaml", env.Name)))
-	if _, ok := b.files[envBindingPath]; ok {
-		envFiles[envBindingPath] = b.files[envBindingPath]
+	for _, name := range []string{"rolebinding", "role"} {
+		rbacPath := filepath.ToSlash(filepath.Join(basePath, fmt.Sprintf("%s-%s.yaml", env.Name, name)))
+		if _, ok := b.files[rbacPath]; ok {
+			envFiles[rbacPath] = b.files[rbacPath]
+		}
 	}
 
 	for k := range envFiles {
@@ -114,32 +126,71 @@ func (b *envBuilder) Environment(env *config.Environment) error {
 	}
 
 	kustomizationPath := filepath.ToSlash(filepath.Join(basePath, kustomization))
-	relApps, err := appsFromEnvironment(env, kustomizationPath, b.appLinks)
+	relApps, err := appsFromEnvironment(b.layout, env, kustomizationPath, b.appLinks)
 	if err != nil {
 		return err
 	}
 	envFiles[kustomizationPath] = &res.Kustomization{
-		Bases:     relApps,
-		Resources: kustomizedFilenames.Items(),
+		Resources: append(kustomizedFilenames.Items(), relApps...),
 	}
 	overlaysPath := filepath.ToSlash(filepath.Join(envPath, "overlays"))
 	relPath, err := filepath.Rel(overlaysPath, basePath)
 	if err != nil {
 		return err
 	}
-	envFiles[filepath.ToSlash(filepath.Join(overlaysPath, kustomization))] = &res.Kustomization{Bases: []string{filepath.ToSlash(relPath)}}
+	relComponents, err := componentsFromEnvironment(env, overlaysPath)
+	if err != nil {
+		return err
+	}
+	envFiles[filepath.ToSlash(filepath.Join(overlaysPath, kustomization))] = &res.Kustomization{
+		Resources:  []string{filepath.ToSlash(relPath)},
+		Components: relComponents,
+	}
 	b.files = res.Merge(envFiles, b.files)
 	return nil
 }
 
-func filesForEnvironment(basePath string, env *config.Environment, gitOpsRepoURL string) res.Resources {
+func filesForEnvironment(basePath string, env *config.Environment, gitOpsRepoURL string, pipelinesConfig *config.PipelinesConfig) (res.Resources, error) {
 	envFiles := res.Resources{}
 	filename := filepath.ToSlash(filepath.Join(basePath, fmt.Sprintf("%s-environment.yaml", env.Name)))
-	envFiles[filename] = namespaces.Create(env.Name, gitOpsRepoURL)
-	return envFiles
+	envFiles[filename] = namespaces.Create(env.GetNamespace(), gitOpsRepoURL)
+	policyFiles, err := filesForPolicies(basePath, env, pipelinesConfig)
+	if err != nil {
+		return nil, err
+	}
+	return res.Merge(policyFiles, envFiles), nil
+}
+
+func filesForPolicies(basePath string, env *config.Environment, pipelinesConfig *config.PipelinesConfig) (res.Resources, error) {
+	policyFiles := res.Resources{}
+	policyPath := func(kind string) string {
+		return filepath.ToSlash(filepath.Join(basePath, fmt.Sprintf("%s-%s.yaml", env.Name, kind)))
+	}
+	if q := env.Policies.GetQuota(); q != nil {
+		quota, err := policies.CreateResourceQuota(meta.NamespacedName(env.GetNamespace(), fmt.Sprintf("%s-quota", env.Name)), q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the quota for environment %s: %w", env.Name, err)
+		}
+		policyFiles[policyPath("resourcequota")] = quota
+	}
+	if l := env.Policies.GetLimits(); l != nil {
+		limits, err := policies.CreateLimitRange(meta.NamespacedName(env.GetNamespace(), fmt.Sprintf("%s-limits", env.Name)), l)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the limits for environment %s: %w", env.Name, err)
+		}
+		policyFiles[policyPath("limitrange")] = limits
+	}
+	if env.Policies != nil && env.Policies.NetworkIsolation {
+		cicdNS := ""
+		if pipelinesConfig != nil {
+			cicdNS = pipelinesConfig.Name
+		}
+		policyFiles[policyPath("networkpolicy")] = policies.CreateNetworkPolicy(meta.NamespacedName(env.GetNamespace(), fmt.Sprintf("%s-network-isolation", env.Name)), cicdNS)
+	}
+	return policyFiles, nil
 }
 
-func filesForApplication(env *config.Environment, fullname, appPath string, app *config.Application) (res.Resources, error) {
+func filesForApplication(layout *config.Layout, env *config.Environment, fullname, appPath string, app *config.Application) (res.Resources, error) {
 	envFiles := res.Resources{}
 	basePath := filepath.ToSlash(filepath.Join(appPath, "base"))
 	overlaysPath := filepath.ToSlash(filepath.Join(appPath, "overlays"))
@@ -151,32 +202,37 @@ func filesForApplication(env *config.Environment, fullname, appPath string, app
 	baseKustomization := filepath.ToSlash(filepath.Join(appPath, "base", kustomization))
 	relServices := []string{}
 	for _, v := range app.Services {
-		svcPath := config.PathForService(app, env, v.Name)
+		svcPath := layout.PathForService(app, env, v.Name)
 		relService, err := filepath.Rel(filepath.Dir(baseKustomization), svcPath)
 		if err != nil {
 			return nil, err
 		}
 		relServices = append(relServices, filepath.ToSlash(relService))
 	}
+	sort.Strings(relServices)
 
 	envFiles[filepath.ToSlash(filepath.Join(appPath, kustomization))] = &res.Kustomization{
-		Bases: []string{"overlays"},
+		Resources: []string{"overlays"},
 		CommonLabels: map[string]string{
 			vcsSourceLabel: fullname,
 		},
 	}
 	envFiles[filepath.ToSlash(filepath.Join(appPath, "base", kustomization))] = &res.Kustomization{
-		Bases: relServices,
+		Resources: relServices,
 	}
 	envFiles[overlaysFile] = &res.Kustomization{
-		Bases: []string{filepath.ToSlash(overlayRel)},
+		Resources: []string{filepath.ToSlash(overlayRel)},
 	}
 	return envFiles, nil
 }
 
-func createRoleBinding(env *config.Environment, cicdNS, saName string) *v1.RoleBinding {
-	sa := roles.CreateServiceAccount(meta.NamespacedName(cicdNS, saName))
-	return roles.CreateRoleBinding(meta.NamespacedName(env.Name, fmt.Sprintf("%s-rolebinding", env.Name)), sa, "ClusterRole", "edit")
+func createRoleBinding(env *config.Environment, cfg *config.PipelinesConfig, saName string) *v1.RoleBinding {
+	sa := roles.CreateServiceAccount(meta.NamespacedName(cfg.Name, saName))
+	name := meta.NamespacedName(env.GetNamespace(), fmt.Sprintf("%s-rolebinding", env.Name))
+	if cfg.IsMinimalRBAC() {
+		return roles.CreateRoleBinding(name, sa, "Role", roles.RoleName)
+	}
+	return roles.CreateRoleBinding(name, sa, "ClusterRole", "edit")
 }
 
 func filesForService(svcPath string) (res.Resources, error) {
@@ -188,9 +244,9 @@ func filesForService(svcPath string) (res.Resources, error) {
 	if err != nil {
 		return nil, err
 	}
-	envFiles[filepath.ToSlash(filepath.Join(svcPath, kustomization))] = &res.Kustomization{Bases: []string{"overlays"}}
-	envFiles[filepath.ToSlash(filepath.Join(svcPath, "base", kustomization))] = &res.Kustomization{Bases: []string{"./config"}}
-	envFiles[overlaysFile] = &res.Kustomization{Bases: []string{filepath.ToSlash(overlayRel)}}
+	envFiles[filepath.ToSlash(filepath.Join(svcPath, kustomization))] = &res.Kustomization{Resources: []string{"overlays"}}
+	envFiles[filepath.ToSlash(filepath.Join(svcPath, "base", kustomization))] = &res.Kustomization{Resources: []string{"./config"}}
+	envFiles[overlaysFile] = &res.Kustomization{Resources: []string{filepath.ToSlash(overlayRel)}}
 
 	return envFiles, nil
 }
@@ -232,18 +288,35 @@ func ListFiles(fs afero.Fs, base string) (StringSet, error) {
 	return files, err
 }
 
-func appsFromEnvironment(env *config.Environment, kustomizationPath string, appLinks AppLinks) ([]string, error) {
+func appsFromEnvironment(layout *config.Layout, env *config.Environment, kustomizationPath string, appLinks AppLinks) ([]string, error) {
 	relApps := []string{}
 	if appLinks != EnvironmentsToApps {
 		return nil, nil
 	}
 	for _, v := range env.Apps {
-		appPath := config.PathForApplication(env, v)
+		appPath := layout.PathForApplication(env, v)
 		relApp, err := filepath.Rel(filepath.Dir(kustomizationPath), appPath)
 		if err != nil {
 			return nil, err
 		}
 		relApps = append(relApps, filepath.Join(relApp, "overlays"))
 	}
+	sort.Strings(relApps)
 	return relApps, nil
 }
+
+// componentsFromEnvironment returns the paths of the components of the
+// environment relative to the overlays path, the components are never nil so
+// that components that are removed from the environment are removed from an
+// existing kustomization.
+func componentsFromEnvironment(env *config.Environment, overlaysPath string) ([]string, error) {
+	relComponents := []string{}
+	for _, v := range env.Components {
+		relComponent, err := filepath.Rel(overlaysPath, v)
+		if err != nil {
+			return nil, err
+		}
+		relComponents = append(relComponents, filepath.ToSlash(relComponent))
+	}
+	return relComponents, nil
+}
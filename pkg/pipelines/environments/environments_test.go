@@ -9,9 +9,13 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	"github.com/redhat-developer/kam/pkg/pipelines/namespaces"
+	"github.com/redhat-developer/kam/pkg/pipelines/policies"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/roles"
 	"github.com/spf13/afero"
+	v1 "k8s.io/api/rbac/v1"
 )
 
 const testGitOpsRepoURL = "https://github.com/example/example.git"
@@ -26,27 +30,27 @@ func TestBuildEnvironmentFilesWithAppsToEnvironment(t *testing.T) {
 	}
 	want := res.Resources{
 		"environments/test-dev/apps/my-app-1/base/kustomization.yaml": &res.Kustomization{
-			Bases: []string{
+			Resources: []string{
 				"../services/service-http",
 				"../services/service-metrics",
 			},
 		},
 		"environments/test-dev/apps/my-app-1/kustomization.yaml": &res.Kustomization{
-			Bases: []string{"overlays"},
+			Resources: []string{"overlays"},
 			CommonLabels: map[string]string{
 				vcsSourceLabel: "example/example",
 			}},
-		"environments/test-dev/apps/my-app-1/overlays/kustomization.yaml":                          &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/my-app-1/overlays/kustomization.yaml":                          &res.Kustomization{Resources: []string{"../base"}},
 		"environments/test-dev/env/base/test-dev-environment.yaml":                                 namespaces.Create("test-dev", testGitOpsRepoURL),
-		"environments/test-dev/env/base/test-dev-rolebinding.yaml":                                 createRoleBinding(m.Environments[0], "cicd", "pipelines"),
+		"environments/test-dev/env/base/test-dev-rolebinding.yaml":                                 createRoleBinding(m.Environments[0], m.GetPipelinesConfig(), "pipelines"),
 		"environments/test-dev/env/base/kustomization.yaml":                                        &res.Kustomization{Resources: []string{"test-dev-environment.yaml", "test-dev-rolebinding.yaml"}},
-		"environments/test-dev/env/overlays/kustomization.yaml":                                    &res.Kustomization{Bases: []string{"../base"}},
-		"environments/test-dev/apps/my-app-1/services/service-http/kustomization.yaml":             &res.Kustomization{Bases: []string{"overlays"}},
-		"environments/test-dev/apps/my-app-1/services/service-http/base/kustomization.yaml":        &res.Kustomization{Bases: []string{"./config"}},
-		"environments/test-dev/apps/my-app-1/services/service-http/overlays/kustomization.yaml":    &res.Kustomization{Bases: []string{"../base"}},
-		"environments/test-dev/apps/my-app-1/services/service-metrics/kustomization.yaml":          &res.Kustomization{Bases: []string{"overlays"}},
-		"environments/test-dev/apps/my-app-1/services/service-metrics/base/kustomization.yaml":     &res.Kustomization{Bases: []string{"./config"}},
-		"environments/test-dev/apps/my-app-1/services/service-metrics/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/env/overlays/kustomization.yaml":                                    &res.Kustomization{Resources: []string{"../base"}, Components: []string{}},
+		"environments/test-dev/apps/my-app-1/services/service-http/kustomization.yaml":             &res.Kustomization{Resources: []string{"overlays"}},
+		"environments/test-dev/apps/my-app-1/services/service-http/base/kustomization.yaml":        &res.Kustomization{Resources: []string{"./config"}},
+		"environments/test-dev/apps/my-app-1/services/service-http/overlays/kustomization.yaml":    &res.Kustomization{Resources: []string{"../base"}},
+		"environments/test-dev/apps/my-app-1/services/service-metrics/kustomization.yaml":          &res.Kustomization{Resources: []string{"overlays"}},
+		"environments/test-dev/apps/my-app-1/services/service-metrics/base/kustomization.yaml":     &res.Kustomization{Resources: []string{"./config"}},
+		"environments/test-dev/apps/my-app-1/services/service-metrics/overlays/kustomization.yaml": &res.Kustomization{Resources: []string{"../base"}},
 	}
 
 	if diff := cmp.Diff(want, files); diff != "" {
@@ -54,6 +58,139 @@ func TestBuildEnvironmentFilesWithAppsToEnvironment(t *testing.T) {
 	}
 }
 
+func TestBuildEnvironmentFilesWithNamespace(t *testing.T) {
+	var appFs = ioutils.NewMemoryFilesystem()
+	m := buildManifestWithCICD()
+	m.Environments[0].Namespace = "shop"
+
+	files, err := Build(appFs, m, "pipelines", AppsToEnvironments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(namespaces.Create("shop", testGitOpsRepoURL), files["environments/test-dev/env/base/test-dev-environment.yaml"]); diff != "" {
+		t.Fatalf("namespace didn't match: %s\n", diff)
+	}
+	rb := files["environments/test-dev/env/base/test-dev-rolebinding.yaml"].(*v1.RoleBinding)
+	if rb.Namespace != "shop" {
+		t.Fatalf("rolebinding namespace got %q, want %q", rb.Namespace, "shop")
+	}
+}
+
+func TestBuildEnvironmentFilesWithPolicies(t *testing.T) {
+	var appFs = ioutils.NewMemoryFilesystem()
+	m := buildManifestWithCICD()
+	m.Environments[0].Policies = &config.Policies{Size: "small", NetworkIsolation: true}
+
+	files, err := Build(appFs, m, "pipelines", AppsToEnvironments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &res.Kustomization{Resources: []string{
+		"test-dev-environment.yaml",
+		"test-dev-limitrange.yaml",
+		"test-dev-networkpolicy.yaml",
+		"test-dev-resourcequota.yaml",
+		"test-dev-rolebinding.yaml",
+	}}
+	if diff := cmp.Diff(want, files["environments/test-dev/env/base/kustomization.yaml"]); diff != "" {
+		t.Fatalf("kustomization didn't match: %s\n", diff)
+	}
+	wantNetworkPolicy := policies.CreateNetworkPolicy(meta.NamespacedName("test-dev", "test-dev-network-isolation"), "cicd")
+	if diff := cmp.Diff(wantNetworkPolicy, files["environments/test-dev/env/base/test-dev-networkpolicy.yaml"]); diff != "" {
+		t.Fatalf("network policy didn't match: %s\n", diff)
+	}
+}
+
+func TestBuildEnvironmentFilesWithComponents(t *testing.T) {
+	var appFs = ioutils.NewMemoryFilesystem()
+	m := buildManifestWithCICD()
+	m.Environments[0].Components = []string{"components/monitoring"}
+
+	files, err := Build(appFs, m, "pipelines", AppsToEnvironments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &res.Kustomization{
+		Resources:  []string{"../base"},
+		Components: []string{"../../../../components/monitoring"},
+	}
+	if diff := cmp.Diff(want, files["environments/test-dev/env/overlays/kustomization.yaml"]); diff != "" {
+		t.Fatalf("kustomization didn't match: %s\n", diff)
+	}
+}
+
+func TestBuildEnvironmentFilesWithLayout(t *testing.T) {
+	var appFs = ioutils.NewMemoryFilesystem()
+	m := buildManifestWithCICD()
+	m.Config.Layout = &config.Layout{
+		Environment: "envs/{{ .Environment }}",
+		Application: "apps/{{ .Application }}/envs/{{ .Environment }}",
+	}
+
+	files, err := Build(appFs, m, "pipelines", EnvironmentsToApps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := res.Resources{
+		"apps/my-app-1/envs/test-dev/base/kustomization.yaml": &res.Kustomization{
+			Resources: []string{
+				"../services/service-http",
+				"../services/service-metrics",
+			},
+		},
+		"apps/my-app-1/envs/test-dev/kustomization.yaml": &res.Kustomization{
+			Resources: []string{"overlays"},
+			CommonLabels: map[string]string{
+				vcsSourceLabel: "example/example",
+			},
+		},
+		"apps/my-app-1/envs/test-dev/overlays/kustomization.yaml": &res.Kustomization{Resources: []string{"../base"}},
+		"envs/test-dev/env/base/test-dev-environment.yaml":        namespaces.Create("test-dev", testGitOpsRepoURL),
+		"envs/test-dev/env/base/test-dev-rolebinding.yaml":        createRoleBinding(m.Environments[0], m.GetPipelinesConfig(), "pipelines"),
+		"envs/test-dev/env/base/kustomization.yaml": &res.Kustomization{
+			Resources: []string{"test-dev-environment.yaml", "test-dev-rolebinding.yaml", "../../../../apps/my-app-1/envs/test-dev/overlays"},
+		},
+		"envs/test-dev/env/overlays/kustomization.yaml":                                    &res.Kustomization{Resources: []string{"../base"}, Components: []string{}},
+		"apps/my-app-1/envs/test-dev/services/service-http/kustomization.yaml":             &res.Kustomization{Resources: []string{"overlays"}},
+		"apps/my-app-1/envs/test-dev/services/service-http/base/kustomization.yaml":        &res.Kustomization{Resources: []string{"./config"}},
+		"apps/my-app-1/envs/test-dev/services/service-http/overlays/kustomization.yaml":    &res.Kustomization{Resources: []string{"../base"}},
+		"apps/my-app-1/envs/test-dev/services/service-metrics/kustomization.yaml":          &res.Kustomization{Resources: []string{"overlays"}},
+		"apps/my-app-1/envs/test-dev/services/service-metrics/base/kustomization.yaml":     &res.Kustomization{Resources: []string{"./config"}},
+		"apps/my-app-1/envs/test-dev/services/service-metrics/overlays/kustomization.yaml": &res.Kustomization{Resources: []string{"../base"}},
+	}
+	if diff := cmp.Diff(want, files); diff != "" {
+		t.Fatalf("files didn't match: %s\n", diff)
+	}
+}
+
+func TestBuildEnvironmentFilesWithMinimalRBAC(t *testing.T) {
+	var appFs = ioutils.NewMemoryFilesystem()
+	m := buildManifestWithCICD()
+	m.Config.Pipelines.RBACMode = config.MinimalRBACMode
+
+	files, err := Build(appFs, m, "pipelines", AppsToEnvironments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := res.Resources{
+		"environments/test-dev/env/base/kustomization.yaml": &res.Kustomization{Resources: []string{"test-dev-environment.yaml", "test-dev-role.yaml", "test-dev-rolebinding.yaml"}},
+		"environments/test-dev/env/base/test-dev-role.yaml": roles.CreateRole(meta.NamespacedName("test-dev", roles.RoleName), roles.EnvironmentRules),
+		"environments/test-dev/env/base/test-dev-rolebinding.yaml": roles.CreateRoleBinding(
+			meta.NamespacedName("test-dev", "test-dev-rolebinding"),
+			roles.CreateServiceAccount(meta.NamespacedName("cicd", "pipelines")), "Role", roles.RoleName),
+	}
+	for k, v := range want {
+		if diff := cmp.Diff(v, files[k]); diff != "" {
+			t.Errorf("%s didn't match: %s\n", k, diff)
+		}
+	}
+}
+
 func TestBuildEnvironmentFilesWithEnvironmentsToApps(t *testing.T) {
 	var appFs = ioutils.NewMemoryFilesystem()
 	m := buildManifestWithCICD()
@@ -64,31 +201,30 @@ func TestBuildEnvironmentFilesWithEnvironmentsToApps(t *testing.T) {
 	}
 	want := res.Resources{
 		"environments/test-dev/apps/my-app-1/base/kustomization.yaml": &res.Kustomization{
-			Bases: []string{
+			Resources: []string{
 				"../services/service-http",
 				"../services/service-metrics",
 			},
 		},
 		"environments/test-dev/apps/my-app-1/kustomization.yaml": &res.Kustomization{
-			Bases: []string{"overlays"},
+			Resources: []string{"overlays"},
 			CommonLabels: map[string]string{
 				vcsSourceLabel: "example/example",
 			},
 		},
-		"environments/test-dev/apps/my-app-1/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/my-app-1/overlays/kustomization.yaml": &res.Kustomization{Resources: []string{"../base"}},
 		"environments/test-dev/env/base/test-dev-environment.yaml":        namespaces.Create("test-dev", testGitOpsRepoURL),
-		"environments/test-dev/env/base/test-dev-rolebinding.yaml":        createRoleBinding(m.Environments[0], "cicd", "pipelines"),
+		"environments/test-dev/env/base/test-dev-rolebinding.yaml":        createRoleBinding(m.Environments[0], m.GetPipelinesConfig(), "pipelines"),
 		"environments/test-dev/env/base/kustomization.yaml": &res.Kustomization{
-			Resources: []string{"test-dev-environment.yaml", "test-dev-rolebinding.yaml"},
-			Bases:     []string{"../../apps/my-app-1/overlays"},
+			Resources: []string{"test-dev-environment.yaml", "test-dev-rolebinding.yaml", "../../apps/my-app-1/overlays"},
 		},
-		"environments/test-dev/env/overlays/kustomization.yaml":                                    &res.Kustomization{Bases: []string{"../base"}},
-		"environments/test-dev/apps/my-app-1/services/service-http/kustomization.yaml":             &res.Kustomization{Bases: []string{"overlays"}},
-		"environments/test-dev/apps/my-app-1/services/service-http/base/kustomization.yaml":        &res.Kustomization{Bases: []string{"./config"}},
-		"environments/test-dev/apps/my-app-1/services/service-http/overlays/kustomization.yaml":    &res.Kustomization{Bases: []string{"../base"}},
-		"environments/test-dev/apps/my-app-1/services/service-metrics/kustomization.yaml":          &res.Kustomization{Bases: []string{"overlays"}},
-		"environments/test-dev/apps/my-app-1/services/service-metrics/base/kustomization.yaml":     &res.Kustomization{Bases: []string{"./config"}},
-		"environments/test-dev/apps/my-app-1/services/service-metrics/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/env/overlays/kustomization.yaml":                                    &res.Kustomization{Resources: []string{"../base"}, Components: []string{}},
+		"environments/test-dev/apps/my-app-1/services/service-http/kustomization.yaml":             &res.Kustomization{Resources: []string{"overlays"}},
+		"environments/test-dev/apps/my-app-1/services/service-http/base/kustomization.yaml":        &res.Kustomization{Resources: []string{"./config"}},
+		"environments/test-dev/apps/my-app-1/services/service-http/overlays/kustomization.yaml":    &res.Kustomization{Resources: []string{"../base"}},
+		"environments/test-dev/apps/my-app-1/services/service-metrics/kustomization.yaml":          &res.Kustomization{Resources: []string{"overlays"}},
+		"environments/test-dev/apps/my-app-1/services/service-metrics/base/kustomization.yaml":     &res.Kustomization{Resources: []string{"./config"}},
+		"environments/test-dev/apps/my-app-1/services/service-metrics/overlays/kustomization.yaml": &res.Kustomization{Resources: []string{"../base"}},
 	}
 
 	if diff := cmp.Diff(want, files); diff != "" {
@@ -177,27 +313,27 @@ func TestBuildEnvironmentFilesWithNoCICDEnv(t *testing.T) {
 
 	want := res.Resources{
 		"environments/test-dev/apps/my-app-1/base/kustomization.yaml": &res.Kustomization{
-			Bases: []string{
+			Resources: []string{
 				"../services/service-http",
 				"../services/service-metrics",
 			},
 		},
 		"environments/test-dev/apps/my-app-1/kustomization.yaml": &res.Kustomization{
-			Bases: []string{"overlays"},
+			Resources: []string{"overlays"},
 			CommonLabels: map[string]string{
 				vcsSourceLabel: "example/example",
 			},
 		},
-		"environments/test-dev/apps/my-app-1/overlays/kustomization.yaml":                          &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/my-app-1/overlays/kustomization.yaml":                          &res.Kustomization{Resources: []string{"../base"}},
 		"environments/test-dev/env/base/test-dev-environment.yaml":                                 namespaces.Create("test-dev", testGitOpsRepoURL),
 		"environments/test-dev/env/base/kustomization.yaml":                                        &res.Kustomization{Resources: []string{"test-dev-environment.yaml"}},
-		"environments/test-dev/env/overlays/kustomization.yaml":                                    &res.Kustomization{Bases: []string{"../base"}},
-		"environments/test-dev/apps/my-app-1/services/service-http/kustomization.yaml":             &res.Kustomization{Bases: []string{"overlays"}},
-		"environments/test-dev/apps/my-app-1/services/service-http/base/kustomization.yaml":        &res.Kustomization{Bases: []string{"./config"}},
-		"environments/test-dev/apps/my-app-1/services/service-http/overlays/kustomization.yaml":    &res.Kustomization{Bases: []string{"../base"}},
-		"environments/test-dev/apps/my-app-1/services/service-metrics/kustomization.yaml":          &res.Kustomization{Bases: []string{"overlays"}},
-		"environments/test-dev/apps/my-app-1/services/service-metrics/base/kustomization.yaml":     &res.Kustomization{Bases: []string{"./config"}},
-		"environments/test-dev/apps/my-app-1/services/service-metrics/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/env/overlays/kustomization.yaml":                                    &res.Kustomization{Resources: []string{"../base"}, Components: []string{}},
+		"environments/test-dev/apps/my-app-1/services/service-http/kustomization.yaml":             &res.Kustomization{Resources: []string{"overlays"}},
+		"environments/test-dev/apps/my-app-1/services/service-http/base/kustomization.yaml":        &res.Kustomization{Resources: []string{"./config"}},
+		"environments/test-dev/apps/my-app-1/services/service-http/overlays/kustomization.yaml":    &res.Kustomization{Resources: []string{"../base"}},
+		"environments/test-dev/apps/my-app-1/services/service-metrics/kustomization.yaml":          &res.Kustomization{Resources: []string{"overlays"}},
+		"environments/test-dev/apps/my-app-1/services/service-metrics/base/kustomization.yaml":     &res.Kustomization{Resources: []string{"./config"}},
+		"environments/test-dev/apps/my-app-1/services/service-metrics/overlays/kustomization.yaml": &res.Kustomization{Resources: []string{"../base"}},
 	}
 
 	if diff := cmp.Diff(want, files); diff != "" {
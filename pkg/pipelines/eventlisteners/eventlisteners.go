@@ -1,6 +1,8 @@
 package eventlisteners
 
 import (
+	"fmt"
+
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -17,6 +19,10 @@ const (
 	// WebhookSecretKey is the name of the generated secret for hooks from the
 	// bootstrapped application.
 	WebhookSecretKey = "webhook-secret-key"
+
+	// EventListenerName is the name of the generated EventListener in the
+	// CI/CD namespace.
+	EventListenerName = "cicd-event-listener"
 )
 
 var (
@@ -47,7 +53,7 @@ func CreateELFromTriggers(cicdNS, saName string, triggers []triggersv1.EventList
 	return &triggersv1.EventListener{
 		TypeMeta: eventListenerTypeMeta,
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "cicd-event-listener",
+			Name:      EventListenerName,
 			Namespace: cicdNS,
 		},
 		Spec: triggersv1.EventListenerSpec{
@@ -63,3 +69,9 @@ func createListenerObjectMeta(name, ns string) metav1.ObjectMeta {
 		Namespace: ns,
 	}
 }
+
+// ServiceTriggerName returns the name of the EventListener trigger that
+// starts the CI pipeline for pushes to the named service's repository.
+func ServiceTriggerName(svc string) string {
+	return fmt.Sprintf("app-ci-build-from-push-%s", svc)
+}
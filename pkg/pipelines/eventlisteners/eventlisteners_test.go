@@ -10,7 +10,7 @@ import (
 )
 
 func TestGenerateEventListener(t *testing.T) {
-	repo, err := scm.NewRepository("http://github.com/org/test")
+	repo, err := scm.NewRepository("http://github.com/org/test", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -8,7 +8,9 @@ import (
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
-	"github.com/jenkins-x/go-scm/scm/factory"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
+	kamscm "github.com/redhat-developer/kam/pkg/pipelines/scm"
 )
 
 // Repository represent a Git repository ofa specific Git repository URL
@@ -19,14 +21,21 @@ type Repository struct {
 	name string
 }
 
-// NewRepository creates a new Git repository object
-func NewRepository(rawURL, token string) (*Repository, error) {
-	parsed, err := url.Parse(rawURL)
+// NewRepository creates a new Git repository object, the driver for the
+// host of the repository is resolved by the drivers.
+//
+// SSH URLs are accessed with the https API of the host.
+func NewRepository(rawURL, token string, drivers *kamscm.DriverResolver) (*Repository, error) {
+	httpsURL, err := giturl.HTTPS(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse repository URL %q: %w", rawURL, err)
+	}
+	parsed, err := url.Parse(httpsURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse repository URL %q: %w", rawURL, err)
 	}
 	parsed.User = url.UserPassword("", token)
-	client, err := factory.FromRepoURL(parsed.String())
+	client, err := drivers.NewClient(parsed.String())
 	if err != nil {
 		return nil, err
 	}
@@ -85,10 +94,60 @@ func (r *Repository) CreateWebhook(listenerURL, secret string) (string, error) {
 	return created.ID, err
 }
 
-// TODO: this likely won't work for GitLab projects because it assumes that the
-// path is always composed of two elements.
+// ListHooks returns all the webhooks in this repository.
+func (r *Repository) ListHooks() ([]*scm.Hook, error) {
+	hooks, _, err := r.Client.Repositories.ListHooks(context.Background(), r.name, scm.ListOptions{})
+	return hooks, err
+}
+
+// UpdateWebhook changes the target and secret of an existing webhook, and
+// ensures that it delivers the push and pull request events.
+//
+// Some drivers can't update webhooks in place, for these the webhook is
+// deleted and recreated.
+//
+// It returns the ID of the updated webhook, which may differ from the original.
+func (r *Repository) UpdateWebhook(id, listenerURL, secret string) (string, error) {
+	in := &scm.HookInput{
+		// The GitLab driver uses the name to identify the hook to update.
+		Name:   id,
+		Target: listenerURL,
+		Secret: secret,
+		Events: scm.HookEvents{
+			PullRequest: true,
+			Push:        true,
+		},
+	}
+	updated, _, err := r.Client.Repositories.UpdateHook(context.Background(), r.name, in)
+	if err == nil {
+		return updated.ID, nil
+	}
+	if !errors.Is(err, scm.ErrNotSupported) {
+		return "", fmt.Errorf("failed to update webhook id %s: %v", id, err)
+	}
+	if _, err := r.DeleteWebhooks([]string{id}); err != nil {
+		return "", err
+	}
+	return r.CreateWebhook(listenerURL, secret)
+}
+
+// FindCommit returns the commit that the ref (a branch, tag or SHA) points to.
+func (r *Repository) FindCommit(ref string) (*scm.Commit, error) {
+	commit, _, err := r.Client.Git.FindCommit(context.Background(), r.name, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find commit for %s: %w", ref, err)
+	}
+	if commit == nil {
+		return nil, fmt.Errorf("failed to find commit for %s", ref)
+	}
+	return commit, nil
+}
+
 // GetRepoName takes a URL of the form https://github.com/my-org/my-repo.git and
 // attempts to determine the name of the repo from this, i.e. "my-org/my-repo".
+//
+// GitLab projects can be in nested subgroups, the name of
+// https://gitlab.com/group/subgroup/my-repo.git is "group/subgroup/my-repo".
 func GetRepoName(u *url.URL) (string, error) {
 	var components []string
 	for _, s := range strings.Split(u.Path, "/") {
@@ -96,12 +155,13 @@ func GetRepoName(u *url.URL) (string, error) {
 			components = append(components, s)
 		}
 	}
-	if len(components) == 1 {
+	if len(components) < 2 {
 		return "", errors.New("failed to get Git repo: " + u.Path)
 	}
 	components[len(components)-1] = strings.TrimSuffix(components[len(components)-1], ".git")
 	for _, s := range components {
-		if strings.Contains(s, ".") {
+		// Names can contain dots, but not be relative path elements.
+		if strings.Trim(s, ".") == "" {
 			return "", errors.New("failed to get Git repo: " + u.Path)
 		}
 	}
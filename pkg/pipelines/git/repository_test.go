@@ -1,14 +1,20 @@
 package git
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/h2non/gock"
-	"github.com/jenkins-x/go-scm/scm/factory"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/test"
 )
 
+var fakeDrivers = scm.NewDriverResolver(map[string]string{"fake.com": "fake"})
+
 var mockHeaders = map[string]string{
 	"X-GitHub-Request-Id":   "DD0E:6011:12F21A8:1926790:5A2064E2",
 	"X-RateLimit-Limit":     "60",
@@ -17,9 +23,7 @@ var mockHeaders = map[string]string{
 }
 
 func TestWebhookWithFakeClient(t *testing.T) {
-	fakeID := factory.NewDriverIdentifier(factory.Mapping("fake.com", "fake"))
-	factory.DefaultIdentifier = fakeID
-	repo, err := NewRepository("https://fake.com/foo/bar.git", "token")
+	repo, err := NewRepository("https://fake.com/foo/bar.git", "token", fakeDrivers)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -89,7 +93,7 @@ func TestListWebHooks(t *testing.T) {
 		SetHeaders(mockHeaders).
 		File("testdata/hooks.json")
 
-	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -104,6 +108,62 @@ func TestListWebHooks(t *testing.T) {
 	}
 }
 
+func TestListWebHooksWithSSHURL(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		File("testdata/hooks.json")
+
+	repo, err := NewRepository("git@github.com:foo/bar.git", "token", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.ListWebhooks("http://example.com/webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"1"}, ids); diff != "" {
+		t.Errorf("webhook ids mismatch got\n%s", diff)
+	}
+}
+
+func TestListWebHooksInGitLabSubgroup(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fsubgroup%2Fteam%2Fbar/hooks" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		http.ServeFile(w, r, "testdata/gitlab_hooks.json")
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drivers := scm.NewDriverResolver(map[string]string{u.Host: "gitlab"})
+
+	repo, err := NewRepository(ts.URL+"/group/subgroup/team/bar.git", "token", drivers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.ListWebhooks("http://example.com/webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"1"}, ids); diff != "" {
+		t.Errorf("webhook ids mismatch got\n%s", diff)
+	}
+}
+
 func TestDeleteWebHooks(t *testing.T) {
 	defer gock.Off()
 
@@ -113,7 +173,7 @@ func TestDeleteWebHooks(t *testing.T) {
 		Type("application/json").
 		SetHeaders(mockHeaders)
 
-	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -138,7 +198,7 @@ func TestCreateWebHook(t *testing.T) {
 		SetHeaders(mockHeaders).
 		File("testdata/hook.json")
 
-	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -161,6 +221,9 @@ func TestGetRepoName(t *testing.T) {
 		{"https://github.com/example/gitops.git?ref=main", "example/gitops"},
 		{"https://github.com/example/testing.git", "example/testing"},
 		{"https://gitlab.com/project/example/testing.git", "project/example/testing"},
+		{"https://gitlab.com/group/subgroup/team/testing.git", "group/subgroup/team/testing"},
+		{"https://github.com/example/my.app.git", "example/my.app"},
+		{"https://gitlab.example.com/my.group/sub.group/testing", "my.group/sub.group/testing"},
 	}
 
 	for _, tt := range urlTests {
@@ -179,3 +242,62 @@ func TestGetRepoName(t *testing.T) {
 		})
 	}
 }
+
+func TestGetRepoNameWithInvalidPath(t *testing.T) {
+	for _, raw := range []string{
+		"https://github.com/example",
+		"https://github.com/",
+		"https://gitlab.com/group/../testing.git",
+	} {
+		t.Run(raw, func(t *testing.T) {
+			u, err := url.Parse(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = GetRepoName(u)
+			if !test.ErrorMatch(t, "failed to get Git repo", err) {
+				t.Fatalf("GetRepoName() got error %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdateWebhookWithFakeClient(t *testing.T) {
+	repo, err := NewRepository("https://fake.com/foo/bar.git", "token", fakeDrivers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := repo.CreateWebhook("http://old.example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the fake driver doesn't support updating hooks, so this recreates it.
+	newID, err := repo.UpdateWebhook(id, "http://new.example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hooks, err := repo.ListHooks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(hooks); l != 1 {
+		t.Fatalf("got %d hooks, want 1", l)
+	}
+	if hooks[0].ID != newID || hooks[0].Target != "http://new.example.com" {
+		t.Fatalf("got hook %#v, want ID %s with target http://new.example.com", hooks[0], newID)
+	}
+}
+
+func TestFindCommitWithFakeClient(t *testing.T) {
+	repo, err := NewRepository("https://fake.com/foo/bar.git", "token", fakeDrivers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repo.FindCommit("main")
+	if err == nil || err.Error() != "failed to find commit for main" {
+		t.Fatalf("FindCommit() got error %v, want failed to find commit for main", err)
+	}
+}
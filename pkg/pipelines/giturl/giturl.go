@@ -0,0 +1,84 @@
+package giturl
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const sshScheme = "ssh"
+
+// scpLikeURL matches SCP-style SSH URLs e.g. git@github.com:org/repo.git, the
+// path can't start with a "/" so that malformed URLs e.g. https:/org/repo
+// aren't parsed as SSH URLs.
+var scpLikeURL = regexp.MustCompile(`^(?:([a-zA-Z0-9._~-]+)@)?([a-zA-Z0-9.-]+):([^/].*)?$`)
+
+// Parse parses a Git repository URL.
+//
+// In addition to the URLs that url.Parse accepts, SCP-style SSH URLs
+// e.g. git@github.com:org/repo.git are parsed as the equivalent
+// ssh://git@github.com/org/repo.git URL.
+func Parse(rawURL string) (*url.URL, error) {
+	if strings.Contains(rawURL, "://") {
+		return url.Parse(rawURL)
+	}
+	m := scpLikeURL.FindStringSubmatch(rawURL)
+	if m == nil {
+		return url.Parse(rawURL)
+	}
+	if m[3] == "" {
+		return nil, fmt.Errorf("missing repository path in %q", rawURL)
+	}
+	u := &url.URL{
+		Scheme: sshScheme,
+		Host:   m[2],
+		Path:   "/" + m[3],
+	}
+	if m[1] != "" {
+		u.User = url.User(m[1])
+	}
+	return u, nil
+}
+
+// IsSSH returns true if the URL is an SSH URL, either ssh:// or SCP-style.
+func IsSSH(rawURL string) bool {
+	u, err := Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == sshScheme
+}
+
+// HTTPS returns the https URL of the repository for an SSH URL, this is the
+// form that is needed to access the API of the Git hosting service.
+//
+// The SSH user and port are dropped, other URLs are returned unchanged.
+func HTTPS(rawURL string) (string, error) {
+	u, err := Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != sshScheme {
+		return rawURL, nil
+	}
+	https := &url.URL{
+		Scheme: "https",
+		Host:   u.Hostname(),
+		Path:   u.Path,
+	}
+	return https.String(), nil
+}
+
+// Host returns the host of the repository URL, for SSH URLs this is the host
+// without the SSH port.
+func Host(rawURL string) (string, error) {
+	u, err := Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == sshScheme {
+		return u.Hostname(), nil
+	}
+	return u.Host, nil
+}
@@ -0,0 +1,94 @@
+package giturl
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestParse(t *testing.T) {
+	parseTests := []struct {
+		rawURL   string
+		want     string
+		wantPath string
+		wantErr  string
+	}{
+		{"https://github.com/org/repo.git", "https://github.com/org/repo.git", "/org/repo.git", ""},
+		{"git@github.com:org/repo.git", "ssh://git@github.com/org/repo.git", "/org/repo.git", ""},
+		{"git@gitlab.com:group/subgroup/repo.git", "ssh://git@gitlab.com/group/subgroup/repo.git", "/group/subgroup/repo.git", ""},
+		{"gitlab.example.com:org/repo.git", "ssh://gitlab.example.com/org/repo.git", "/org/repo.git", ""},
+		{"https:/%/", "", "", "invalid URL escape"},
+		{"ssh://git@gitlab.example.com:2222/org/repo.git", "ssh://git@gitlab.example.com:2222/org/repo.git", "/org/repo.git", ""},
+		{"git@github.com:", "", "", "missing repository path"},
+	}
+
+	for _, tt := range parseTests {
+		t.Run(tt.rawURL, func(rt *testing.T) {
+			u, err := Parse(tt.rawURL)
+			if !test.ErrorMatch(rt, tt.wantErr, err) {
+				rt.Fatalf("error did not match: got %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != "" {
+				return
+			}
+			if diff := cmp.Diff(tt.want, u.String()); diff != "" {
+				rt.Errorf("Parse() failed:\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantPath, u.Path); diff != "" {
+				rt.Errorf("Parse() path failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsSSH(t *testing.T) {
+	sshTests := []struct {
+		rawURL string
+		want   bool
+	}{
+		{"https://github.com/org/repo.git", false},
+		{"http://gitlab.example.com/org/repo.git", false},
+		{"git@github.com:org/repo.git", true},
+		{"ssh://git@github.com/org/repo.git", true},
+	}
+
+	for _, tt := range sshTests {
+		if got := IsSSH(tt.rawURL); got != tt.want {
+			t.Errorf("IsSSH(%q) got %v, want %v", tt.rawURL, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPSAndHost(t *testing.T) {
+	urlTests := []struct {
+		rawURL    string
+		wantHTTPS string
+		wantHost  string
+	}{
+		{"https://github.com/org/repo.git", "https://github.com/org/repo.git", "github.com"},
+		{"https://gitlab.example.com:8443/org/repo.git", "https://gitlab.example.com:8443/org/repo.git", "gitlab.example.com:8443"},
+		{"git@github.com:org/repo.git", "https://github.com/org/repo.git", "github.com"},
+		{"ssh://git@gitlab.example.com:2222/group/subgroup/repo.git", "https://gitlab.example.com/group/subgroup/repo.git", "gitlab.example.com"},
+	}
+
+	for _, tt := range urlTests {
+		t.Run(tt.rawURL, func(rt *testing.T) {
+			https, err := HTTPS(tt.rawURL)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantHTTPS, https); diff != "" {
+				rt.Errorf("HTTPS() failed:\n%s", diff)
+			}
+			host, err := Host(tt.rawURL)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantHost, host); diff != "" {
+				rt.Errorf("Host() failed:\n%s", diff)
+			}
+		})
+	}
+}
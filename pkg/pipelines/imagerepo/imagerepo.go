@@ -16,7 +16,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
-const registryURL = "image-registry.openshift-image-registry.svc:5000"
+const (
+	registryURL = "image-registry.openshift-image-registry.svc:5000"
+
+	// imageBuilderRole is the ClusterRole that allows pushing images to the
+	// internal registry.
+	imageBuilderRole = "system:image-builder"
+)
 
 // ValidateImageRepo validates the input image repo.  It determines if it is
 // for internal registry and prepend internal registry hostname if necessary.
@@ -62,8 +68,8 @@ func imageRepoValidationErrors(imageRepo string) error {
 }
 
 // CreateInternalRegistryResources creates and returns a set of resources, along
-// with the filenames of those resources.
-func CreateInternalRegistryResources(cfg *config.PipelinesConfig, sa *corev1.ServiceAccount, imageRepo, gitOpsRepoURL string) ([]string, res.Resources, error) {
+// with the filenames of those resources, in the CICD environment of the layout.
+func CreateInternalRegistryResources(layout *config.Layout, cfg *config.PipelinesConfig, sa *corev1.ServiceAccount, imageRepo, gitOpsRepoURL string) ([]string, res.Resources, error) {
 	// Provide access to service account for using internal registry
 	namespace := strings.Split(imageRepo, "/")[1]
 
@@ -71,17 +77,44 @@ func CreateInternalRegistryResources(cfg *config.PipelinesConfig, sa *corev1.Ser
 	filenames := []string{}
 
 	filename := filepath.ToSlash(filepath.Join("01-namespaces", fmt.Sprintf("%s-environment.yaml", namespace)))
-	namespacePath := filepath.ToSlash(filepath.Join(config.PathForPipelines(cfg), "base", filename))
+	namespacePath := filepath.ToSlash(filepath.Join(layout.PathForPipelines(cfg), "base", filename))
 	resources[namespacePath] = namespaces.Create(namespace, gitOpsRepoURL)
 	filenames = append(filenames, filename)
 
-	filename, roleBinding := createInternalRegistryRoleBinding(cfg, namespace, sa)
-	return append(filenames, filename), res.Merge(roleBinding, resources), nil
+	filename, roleBinding := createInternalRegistryRoleBinding(layout, cfg, namespace, sa)
+	filenames = append(filenames, filename)
+	resources = res.Merge(roleBinding, resources)
+	if cfg.IsMinimalRBAC() {
+		roleFilenames, role := createInternalRegistryRole(layout, cfg, namespace, sa)
+		filenames = append(filenames, roleFilenames...)
+		resources = res.Merge(role, resources)
+	}
+	return filenames, resources, nil
+}
+
+// createInternalRegistryRole creates the Role, and the binding to it, that
+// allows the service account to apply the binding to push images, when the
+// RBAC mode is minimal.
+func createInternalRegistryRole(layout *config.Layout, cfg *config.PipelinesConfig, ns string, sa *corev1.ServiceAccount) ([]string, res.Resources) {
+	roleFilename := filepath.ToSlash(filepath.Join("02-rolebindings", fmt.Sprintf("internal-registry-%s-role.yaml", ns)))
+	roleBindingName := fmt.Sprintf("internal-registry-%s-role-binding", ns)
+	roleBindingFilename := filepath.ToSlash(filepath.Join("02-rolebindings", fmt.Sprintf("%s.yaml", roleBindingName)))
+	basePath := filepath.ToSlash(filepath.Join(layout.PathForPipelines(cfg), "base"))
+	return []string{roleFilename, roleBindingFilename}, res.Resources{
+		basePath + "/" + roleFilename:        roles.CreateRole(meta.NamespacedName(ns, roles.RoleName), roles.InternalRegistryRules(imageBuilderRole)),
+		basePath + "/" + roleBindingFilename: roles.CreateRoleBinding(meta.NamespacedName(ns, roleBindingName), sa, "Role", roles.RoleName),
+	}
 }
 
-func createInternalRegistryRoleBinding(cfg *config.PipelinesConfig, ns string, sa *corev1.ServiceAccount) (string, res.Resources) {
+func createInternalRegistryRoleBinding(layout *config.Layout, cfg *config.PipelinesConfig, ns string, sa *corev1.ServiceAccount) (string, res.Resources) {
 	roleBindingName := fmt.Sprintf("internal-registry-%s-binding", ns)
 	roleBindingFilname := filepath.ToSlash(filepath.Join("02-rolebindings", fmt.Sprintf("%s.yaml", roleBindingName)))
-	roleBindingPath := filepath.ToSlash(filepath.Join(config.PathForPipelines(cfg), "base", roleBindingFilname))
-	return roleBindingFilname, res.Resources{roleBindingPath: roles.CreateRoleBinding(meta.NamespacedName(ns, roleBindingName), sa, "ClusterRole", "edit")}
+	roleBindingPath := filepath.ToSlash(filepath.Join(layout.PathForPipelines(cfg), "base", roleBindingFilname))
+	// With minimal RBAC the service account can only push images to the
+	// namespace of the image repository.
+	roleName := "edit"
+	if cfg.IsMinimalRBAC() {
+		roleName = imageBuilderRole
+	}
+	return roleBindingFilname, res.Resources{roleBindingPath: roles.CreateRoleBinding(meta.NamespacedName(ns, roleBindingName), sa, "ClusterRole", roleName)}
 }
@@ -17,7 +17,7 @@ func TestCreateInternalRegistryRoleBinding(t *testing.T) {
 		Name: "test-cicd",
 	}
 	sa := roles.CreateServiceAccount(meta.NamespacedName("test-cicd", "pipeline"))
-	gotFilename, got := createInternalRegistryRoleBinding(pipelinesConfig, "new-proj", sa)
+	gotFilename, got := createInternalRegistryRoleBinding(nil, pipelinesConfig, "new-proj", sa)
 
 	want := res.Resources{"config/test-cicd/base/02-rolebindings/internal-registry-new-proj-binding.yaml": &v1rbac.RoleBinding{
 		TypeMeta:   meta.TypeMeta("RoleBinding", "rbac.authorization.k8s.io/v1"),
@@ -39,6 +39,25 @@ func TestCreateInternalRegistryRoleBinding(t *testing.T) {
 	}
 }
 
+func TestCreateInternalRegistryRoleBindingWithMinimalRBAC(t *testing.T) {
+	pipelinesConfig := &config.PipelinesConfig{
+		Name:     "test-cicd",
+		RBACMode: config.MinimalRBACMode,
+	}
+	sa := roles.CreateServiceAccount(meta.NamespacedName("test-cicd", "pipeline"))
+	_, got := createInternalRegistryRoleBinding(nil, pipelinesConfig, "new-proj", sa)
+
+	rb := got["config/test-cicd/base/02-rolebindings/internal-registry-new-proj-binding.yaml"].(*v1rbac.RoleBinding)
+	want := v1rbac.RoleRef{
+		APIGroup: "rbac.authorization.k8s.io",
+		Kind:     "ClusterRole",
+		Name:     "system:image-builder",
+	}
+	if diff := cmp.Diff(want, rb.RoleRef); diff != "" {
+		t.Errorf("role ref does not match:\n%s", diff)
+	}
+}
+
 func TestValidateImageRepo(t *testing.T) {
 	errorMsg := "failed to parse image repo:%s, expected image repository in the form <registry>/<username>/<repository> or <project>/<app> for internal registry"
 
@@ -0,0 +1,57 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+	"github.com/spf13/afero"
+)
+
+// UnencryptedSecretsWarning is the code of the Warning that is reported when
+// the generated configuration includes secrets that are not encrypted.
+const UnencryptedSecretsWarning = "UnencryptedSecrets"
+
+// Output is the configuration generated for a GitOps repository.
+//
+// The Resources are relative to the GitOps repository, the Secrets are not
+// encrypted, and are relative to the folder that contains the GitOps
+// repository, so that they are not committed to it.
+type Output struct {
+	Resources res.Resources
+	Secrets   res.Resources
+	Warnings  []Warning
+}
+
+// Warning is a problem with the generated configuration that doesn't prevent
+// it from being generated.
+type Warning struct {
+	Code    string
+	Message string
+}
+
+func newOutput(resources, secrets res.Resources) *Output {
+	o := &Output{Resources: resources, Secrets: secrets}
+	if len(secrets) > 0 {
+		o.Warnings = append(o.Warnings, Warning{
+			Code:    UnencryptedSecretsWarning,
+			Message: fmt.Sprintf("%d generated secrets are not encrypted, deploying the GitOps configuration without encrypting secrets is insecure", len(secrets)),
+		})
+	}
+	return o
+}
+
+// WriteOutput writes the resources to the path, and the secrets to the parent
+// folder of the path.
+func WriteOutput(appFs afero.Fs, path string, o *Output) error {
+	_, err := yaml.WriteResources(appFs, path, o.Resources)
+	if err != nil {
+		return fmt.Errorf("failed to write resources: %w", err)
+	}
+	_, err = yaml.WriteResources(appFs, filepath.Join(path, ".."), o.Secrets) // Don't call filepath.ToSlash
+	if err != nil {
+		return fmt.Errorf("failed to write resources: %w", err)
+	}
+	return nil
+}
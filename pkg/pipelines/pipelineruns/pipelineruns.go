@@ -0,0 +1,234 @@
+package pipelineruns
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	pipelineclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/apis"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/clientconfig"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+)
+
+// TriggerLabel is the label that Tekton Triggers applies to resources created
+// by an EventListener trigger, the value is the name of the trigger.
+const TriggerLabel = "triggers.tekton.dev/trigger"
+
+// EventIDLabel is the label that Tekton Triggers applies to resources created
+// by an EventListener, the value is the ID of the event that was received.
+const EventIDLabel = "triggers.tekton.dev/triggers-eventid"
+
+// Run is a summary of a CI PipelineRun for a service.
+type Run struct {
+	Name      string       `json:"name"`
+	Status    string       `json:"status"`
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Duration is rounded to the second, e.g. 1m30s, it's empty if the
+	// PipelineRun hasn't started.
+	Duration string `json:"duration,omitempty"`
+	Commit   string `json:"commit,omitempty"`
+	Ref      string `json:"ref,omitempty"`
+	Author   string `json:"author,omitempty"`
+	Image    string `json:"image,omitempty"`
+}
+
+// Client queries the cluster for the PipelineRuns and their logs.
+type Client struct {
+	tektonClient pipelineclientset.Interface
+	kubeClient   kubernetes.Interface
+}
+
+// NewClient creates a Client from the current kubeconfig.
+func NewClient() (*Client, error) {
+	config, err := clientconfig.GetRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	tektonClient, err := pipelineclientset.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{tektonClient: tektonClient, kubeClient: kubeClient}, nil
+}
+
+// List returns the CI PipelineRuns in the namespace that were started by the
+// service's push trigger in the environment, most recent first.
+//
+// PipelineRuns without an environment label were started before the label
+// was added to the app-ci template, these are returned for every environment
+// of the service.
+//
+// If commit is not empty, only PipelineRuns for that commit are returned.
+func (c *Client) List(ns, envName, serviceName, commit string) ([]Run, error) {
+	selector := labels.Set{
+		TriggerLabel: eventlisteners.ServiceTriggerName(serviceName),
+	}
+	if commit != "" {
+		selector[triggers.GitCommitID] = commit
+	}
+	runs, err := c.listPipelineRuns(ns, selector)
+	if err != nil {
+		return nil, err
+	}
+	inEnv := []pipelinev1.PipelineRun{}
+	for _, pr := range runs {
+		if env, ok := pr.Labels[triggers.EnvironmentLabel]; !ok || env == envName {
+			inEnv = append(inEnv, pr)
+		}
+	}
+	return summariseAll(inEnv), nil
+}
+
+// ListForEvent returns the PipelineRuns in the namespace that were started for
+// the event received by the EventListener.
+func (c *Client) ListForEvent(ns, eventID string) ([]Run, error) {
+	return c.list(ns, labels.Set{EventIDLabel: eventID})
+}
+
+func (c *Client) list(ns string, selector labels.Set) ([]Run, error) {
+	runs, err := c.listPipelineRuns(ns, selector)
+	if err != nil {
+		return nil, err
+	}
+	return summariseAll(runs), nil
+}
+
+func summariseAll(runs []pipelinev1.PipelineRun) []Run {
+	summaries := make([]Run, len(runs))
+	for i := range runs {
+		summaries[i] = summarise(&runs[i], time.Now())
+	}
+	return summaries
+}
+
+// Logs writes the logs for each of the steps of the named PipelineRun to the
+// writer, in the order that the tasks started.
+func (c *Client) Logs(ns, name string, out io.Writer) error {
+	ctx := context.Background()
+	pr, err := c.tektonClient.TektonV1beta1().PipelineRuns(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get PipelineRun %s: %w", name, err)
+	}
+	for _, tr := range sortedTaskRuns(pr) {
+		if tr.Status == nil || tr.Status.PodName == "" {
+			continue
+		}
+		pod, err := c.kubeClient.CoreV1().Pods(ns).Get(ctx, tr.Status.PodName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get pod %s for task %s: %w", tr.Status.PodName, tr.PipelineTaskName, err)
+		}
+		for _, container := range pod.Spec.Containers {
+			if err := c.streamContainerLogs(ctx, ns, pod.Name, container.Name, tr.PipelineTaskName, out); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Client) listPipelineRuns(ns string, selector labels.Set) ([]pipelinev1.PipelineRun, error) {
+	list, err := c.tektonClient.TektonV1beta1().PipelineRuns(ns).List(context.Background(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PipelineRuns in %s: %w", ns, err)
+	}
+	runs := list.Items
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[j].CreationTimestamp.Before(&runs[i].CreationTimestamp)
+	})
+	return runs, nil
+}
+
+func (c *Client) streamContainerLogs(ctx context.Context, ns, podName, containerName, taskName string, out io.Writer) error {
+	stream, err := c.kubeClient.CoreV1().Pods(ns).GetLogs(podName, &corev1.PodLogOptions{Container: containerName}).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get logs for %s in pod %s: %w", containerName, podName, err)
+	}
+	defer stream.Close()
+	fmt.Fprintf(out, "[%s : %s]\n", taskName, strings.TrimPrefix(containerName, "step-"))
+	if _, err := io.Copy(out, stream); err != nil {
+		return err
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+func sortedTaskRuns(pr *pipelinev1.PipelineRun) []*pipelinev1.PipelineRunTaskRunStatus {
+	taskRuns := []*pipelinev1.PipelineRunTaskRunStatus{}
+	for _, tr := range pr.Status.TaskRuns {
+		taskRuns = append(taskRuns, tr)
+	}
+	sort.SliceStable(taskRuns, func(i, j int) bool {
+		return startTime(taskRuns[i]).Before(startTime(taskRuns[j]))
+	})
+	return taskRuns
+}
+
+func startTime(tr *pipelinev1.PipelineRunTaskRunStatus) time.Time {
+	if tr.Status == nil || tr.Status.StartTime == nil {
+		return time.Time{}
+	}
+	return tr.Status.StartTime.Time
+}
+
+func summarise(pr *pipelinev1.PipelineRun, now time.Time) Run {
+	r := Run{
+		Name:      pr.Name,
+		Status:    runStatus(pr),
+		StartTime: pr.Status.StartTime,
+		Commit:    paramValue(pr, "COMMIT_SHA"),
+		Ref:       paramValue(pr, "GIT_REF"),
+		Author:    paramValue(pr, "COMMIT_AUTHOR"),
+		Image:     paramValue(pr, "IMAGE"),
+	}
+	if pr.Status.StartTime != nil {
+		end := now
+		if pr.Status.CompletionTime != nil {
+			end = pr.Status.CompletionTime.Time
+		}
+		r.Duration = end.Sub(pr.Status.StartTime.Time).Round(time.Second).String()
+	}
+	return r
+}
+
+func runStatus(pr *pipelinev1.PipelineRun) string {
+	c := pr.Status.GetCondition(apis.ConditionSucceeded)
+	if c == nil {
+		return "Pending"
+	}
+	switch c.Status {
+	case corev1.ConditionTrue:
+		return "Succeeded"
+	case corev1.ConditionFalse:
+		if c.Reason != "" {
+			return c.Reason
+		}
+		return "Failed"
+	}
+	return "Running"
+}
+
+func paramValue(pr *pipelinev1.PipelineRun, name string) string {
+	for _, p := range pr.Spec.Params {
+		if p.Name == name {
+			return p.Value.StringVal
+		}
+	}
+	return ""
+}
@@ -0,0 +1,316 @@
+package pipelineruns
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	fakeTektonClientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeKubeClientset "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	"github.com/redhat-developer/kam/test"
+)
+
+const (
+	testNamespace = "tst-cicd"
+	testEnv       = "tst-dev"
+)
+
+var testStart = time.Date(2021, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+func TestList(t *testing.T) {
+	otherEnv := makePipelineRun("app-ci-other-env", "svc-1", "abc123", testStart, corev1.ConditionTrue, "")
+	otherEnv.Labels[triggers.EnvironmentLabel] = "tst-stage"
+	client := &Client{
+		tektonClient: fakeTektonClientset.NewSimpleClientset(
+			makePipelineRun("app-ci-older", "svc-1", "abc123", testStart, corev1.ConditionTrue, ""),
+			makePipelineRun("app-ci-newer", "svc-1", "def456", testStart.Add(time.Hour), corev1.ConditionFalse, "Failed"),
+			makePipelineRun("app-ci-other", "svc-2", "abc123", testStart, corev1.ConditionTrue, ""),
+			otherEnv,
+		),
+	}
+
+	runs, err := client.List(testNamespace, testEnv, "svc-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Run{
+		{
+			Name:      "app-ci-newer",
+			Status:    "Failed",
+			StartTime: &metav1.Time{Time: testStart.Add(time.Hour)},
+			Duration:  "1m30s",
+			Commit:    "def456",
+			Ref:       "main",
+			Author:    "testuser",
+			Image:     "quay.io/example/svc-1:main-def456",
+		},
+		{
+			Name:      "app-ci-older",
+			Status:    "Succeeded",
+			StartTime: &metav1.Time{Time: testStart},
+			Duration:  "1m30s",
+			Commit:    "abc123",
+			Ref:       "main",
+			Author:    "testuser",
+			Image:     "quay.io/example/svc-1:main-abc123",
+		},
+	}
+	if diff := cmp.Diff(want, runs); diff != "" {
+		t.Fatalf("List() failed:\n%s", diff)
+	}
+}
+
+func TestListWithoutEnvironmentLabel(t *testing.T) {
+	preUpgrade := makePipelineRun("app-ci-pre-upgrade", "svc-1", "abc123", testStart, corev1.ConditionTrue, "")
+	delete(preUpgrade.Labels, triggers.EnvironmentLabel)
+	otherEnv := makePipelineRun("app-ci-other-env", "svc-1", "abc123", testStart, corev1.ConditionTrue, "")
+	otherEnv.Labels[triggers.EnvironmentLabel] = "tst-stage"
+	client := &Client{
+		tektonClient: fakeTektonClientset.NewSimpleClientset(
+			preUpgrade,
+			otherEnv,
+			makePipelineRun("app-ci-newer", "svc-1", "def456", testStart.Add(time.Hour), corev1.ConditionTrue, ""),
+		),
+	}
+
+	runs, err := client.List(testNamespace, testEnv, "svc-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, r := range runs {
+		names = append(names, r.Name)
+	}
+	if diff := cmp.Diff([]string{"app-ci-newer", "app-ci-pre-upgrade"}, names); diff != "" {
+		t.Fatalf("List() failed:\n%s", diff)
+	}
+}
+
+func TestListWithCommit(t *testing.T) {
+	client := &Client{
+		tektonClient: fakeTektonClientset.NewSimpleClientset(
+			makePipelineRun("app-ci-older", "svc-1", "abc123", testStart, corev1.ConditionTrue, ""),
+			makePipelineRun("app-ci-newer", "svc-1", "def456", testStart.Add(time.Hour), corev1.ConditionFalse, "Failed"),
+		),
+	}
+
+	runs, err := client.List(testNamespace, testEnv, "svc-1", "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l := len(runs); l != 1 || runs[0].Name != "app-ci-older" {
+		t.Fatalf("List() with commit got %#v, want only app-ci-older", runs)
+	}
+}
+
+func TestListForEvent(t *testing.T) {
+	pr := makePipelineRun("app-ci-event", "svc-1", "abc123", testStart, corev1.ConditionTrue, "")
+	pr.Labels[EventIDLabel] = "test-event"
+	client := &Client{
+		tektonClient: fakeTektonClientset.NewSimpleClientset(
+			pr,
+			makePipelineRun("app-ci-other", "svc-1", "abc123", testStart, corev1.ConditionTrue, ""),
+		),
+	}
+
+	runs, err := client.ListForEvent(testNamespace, "test-event")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l := len(runs); l != 1 || runs[0].Name != "app-ci-event" {
+		t.Fatalf("ListForEvent() got %#v, want only app-ci-event", runs)
+	}
+}
+
+func TestRunStatus(t *testing.T) {
+	statusTests := []struct {
+		status corev1.ConditionStatus
+		reason string
+		want   string
+	}{
+		{"", "", "Pending"},
+		{corev1.ConditionUnknown, "Running", "Running"},
+		{corev1.ConditionTrue, "Succeeded", "Succeeded"},
+		{corev1.ConditionFalse, "PipelineRunCancelled", "PipelineRunCancelled"},
+		{corev1.ConditionFalse, "", "Failed"},
+	}
+
+	for _, tt := range statusTests {
+		pr := makePipelineRun("app-ci", "svc-1", "abc123", testStart, tt.status, tt.reason)
+		if got := runStatus(pr); got != tt.want {
+			t.Errorf("runStatus(%q, %q) got %q, want %q", tt.status, tt.reason, got, tt.want)
+		}
+	}
+}
+
+func TestSummariseRunningPipelineRun(t *testing.T) {
+	pr := makePipelineRun("app-ci", "svc-1", "abc123", testStart, corev1.ConditionUnknown, "Running")
+	pr.Status.CompletionTime = nil
+
+	r := summarise(pr, testStart.Add(5*time.Minute))
+
+	if r.Duration != "5m0s" {
+		t.Fatalf("summarise() got duration %v, want %v", r.Duration, "5m0s")
+	}
+}
+
+func TestLogs(t *testing.T) {
+	pr := makePipelineRun("app-ci", "svc-1", "abc123", testStart, corev1.ConditionTrue, "")
+	pr.Status.TaskRuns = map[string]*pipelinev1.PipelineRunTaskRunStatus{
+		"app-ci-build-image": {
+			PipelineTaskName: "build-image",
+			Status: &pipelinev1.TaskRunStatus{
+				TaskRunStatusFields: pipelinev1.TaskRunStatusFields{
+					PodName:   "app-ci-build-image-pod",
+					StartTime: &metav1.Time{Time: testStart.Add(time.Minute)},
+				},
+			},
+		},
+		"app-ci-clone-source": {
+			PipelineTaskName: "clone-source",
+			Status: &pipelinev1.TaskRunStatus{
+				TaskRunStatusFields: pipelinev1.TaskRunStatusFields{
+					PodName:   "app-ci-clone-source-pod",
+					StartTime: &metav1.Time{Time: testStart},
+				},
+			},
+		},
+	}
+	client := &Client{
+		tektonClient: fakeTektonClientset.NewSimpleClientset(pr),
+		kubeClient: fakeKubeClientset.NewSimpleClientset(
+			makePod("app-ci-build-image-pod", "step-build", "step-push"),
+			makePod("app-ci-clone-source-pod", "step-clone"),
+		),
+	}
+	var b bytes.Buffer
+
+	if err := client.Logs(testNamespace, "app-ci", &b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[clone-source : clone]\nfake logs\n[build-image : build]\nfake logs\n[build-image : push]\nfake logs\n"
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("Logs() failed:\n%s", diff)
+	}
+}
+
+func TestLogsWithMissingPipelineRun(t *testing.T) {
+	client := &Client{
+		tektonClient: fakeTektonClientset.NewSimpleClientset(),
+		kubeClient:   fakeKubeClientset.NewSimpleClientset(),
+	}
+
+	err := client.Logs(testNamespace, "app-ci", &bytes.Buffer{})
+
+	if err == nil {
+		t.Fatal("expected an error getting logs for a missing PipelineRun")
+	}
+}
+
+func makePipelineRun(name, svc, commit string, start time.Time, status corev1.ConditionStatus, reason string) *pipelinev1.PipelineRun {
+	pr := &pipelinev1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         testNamespace,
+			CreationTimestamp: metav1.Time{Time: start},
+			Labels: map[string]string{
+				TriggerLabel:                   "app-ci-build-from-push-" + svc,
+				"io.openshift.build.commit.id": commit,
+				triggers.EnvironmentLabel:      testEnv,
+			},
+		},
+		Spec: pipelinev1.PipelineRunSpec{
+			Params: []pipelinev1.Param{
+				makeParam("IMAGE", "quay.io/example/"+svc+":main-"+commit),
+				makeParam("COMMIT_SHA", commit),
+				makeParam("GIT_REF", "main"),
+				makeParam("COMMIT_AUTHOR", "testuser"),
+			},
+		},
+		Status: pipelinev1.PipelineRunStatus{
+			PipelineRunStatusFields: pipelinev1.PipelineRunStatusFields{
+				StartTime:      &metav1.Time{Time: start},
+				CompletionTime: &metav1.Time{Time: start.Add(90 * time.Second)},
+			},
+		},
+	}
+	if status != "" {
+		pr.Status.Status = duckv1beta1.Status{
+			Conditions: duckv1beta1.Conditions{
+				{Type: apis.ConditionSucceeded, Status: status, Reason: reason},
+			},
+		}
+	}
+	return pr
+}
+
+func makeParam(name, value string) pipelinev1.Param {
+	return pipelinev1.Param{
+		Name:  name,
+		Value: pipelinev1.ArrayOrString{Type: pipelinev1.ParamTypeString, StringVal: value},
+	}
+}
+
+func makePod(name string, containers ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+		},
+	}
+	for _, c := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: c})
+	}
+	return pod
+}
+
+func TestFindService(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			{
+				Name: "dev",
+				Apps: []*config.Application{
+					{
+						Name: "app-1",
+						Services: []*config.Service{
+							{Name: "svc-1", SourceURL: "https://github.com/example/svc-1.git"},
+							{Name: "svc-2"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	findTests := []struct {
+		envName     string
+		serviceName string
+		wantErr     string
+	}{
+		{"dev", "svc-1", ""},
+		{"dev", "svc-2", "service svc-2 in environment dev has no source_url, so no CI pipelines are triggered for it"},
+		{"dev", "svc-3", "service svc-3 does not exist in environment dev"},
+		{"stage", "svc-1", "environment stage does not exist"},
+	}
+
+	for _, tt := range findTests {
+		err := findService(m, tt.envName, tt.serviceName)
+		if !test.ErrorMatch(t, tt.wantErr, err) {
+			t.Errorf("findService(%q, %q) got error %v, want %q", tt.envName, tt.serviceName, err, tt.wantErr)
+		}
+	}
+}
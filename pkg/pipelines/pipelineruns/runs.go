@@ -0,0 +1,78 @@
+package pipelineruns
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+// List returns the CI runs for the named service in the environment, most
+// recent first.
+func List(pipelinesFolder, envName, serviceName, commit string) ([]Run, error) {
+	ns, err := cicdNamespaceForService(pipelinesFolder, envName, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.List(ns, envName, serviceName, commit)
+}
+
+// Logs writes the task logs of the most recent CI run for the named service
+// in the environment.
+func Logs(pipelinesFolder, envName, serviceName, commit string, out io.Writer) error {
+	ns, err := cicdNamespaceForService(pipelinesFolder, envName, serviceName)
+	if err != nil {
+		return err
+	}
+	client, err := NewClient()
+	if err != nil {
+		return err
+	}
+	runs, err := client.List(ns, envName, serviceName, commit)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		return fmt.Errorf("no pipeline runs found for service %s in environment %s", serviceName, envName)
+	}
+	return client.Logs(ns, runs[0].Name, out)
+}
+
+func cicdNamespaceForService(pipelinesFolder, envName, serviceName string) (string, error) {
+	manifest, err := config.LoadManifest(ioutils.NewFilesystem(), pipelinesFolder)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse pipelines: %v", err)
+	}
+	if err := findService(manifest, envName, serviceName); err != nil {
+		return "", err
+	}
+	cfg := manifest.GetPipelinesConfig()
+	if cfg == nil {
+		return "", errors.New("failed to find a CI/CD environment in the manifest")
+	}
+	return cfg.Name, nil
+}
+
+func findService(manifest *config.Manifest, envName, serviceName string) error {
+	env := manifest.GetEnvironment(envName)
+	if env == nil {
+		return fmt.Errorf("environment %s does not exist", envName)
+	}
+	for _, app := range env.Apps {
+		for _, svc := range app.Services {
+			if svc.Name == serviceName {
+				if svc.SourceURL == "" {
+					return fmt.Errorf("service %s in environment %s has no source_url, so no CI pipelines are triggered for it", serviceName, envName)
+				}
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("service %s does not exist in environment %s", serviceName, envName)
+}
@@ -0,0 +1,137 @@
+package policies
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// routerPolicyGroupLabel is the label on the namespace of the OpenShift
+	// router.
+	routerPolicyGroupLabel = "network.openshift.io/policy-group"
+	// namespaceNameLabel is the label that Kubernetes applies to every
+	// namespace with the name of the namespace.
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+)
+
+var (
+	resourceQuotaTypeMeta = meta.TypeMeta("ResourceQuota", "v1")
+	limitRangeTypeMeta    = meta.TypeMeta("LimitRange", "v1")
+	networkPolicyTypeMeta = meta.TypeMeta("NetworkPolicy", "networking.k8s.io/v1")
+)
+
+// CreateResourceQuota creates a ResourceQuota with the CPU and memory limits
+// and the number of pods from the quota.
+func CreateResourceQuota(name types.NamespacedName, q *config.Quota) (*corev1.ResourceQuota, error) {
+	hard, err := ResourceList(map[corev1.ResourceName]string{
+		corev1.ResourceLimitsCPU:    q.CPU,
+		corev1.ResourceLimitsMemory: q.Memory,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if q.Pods > 0 {
+		hard[corev1.ResourcePods] = *resource.NewQuantity(int64(q.Pods), resource.DecimalSI)
+	}
+	return &corev1.ResourceQuota{
+		TypeMeta:   resourceQuotaTypeMeta,
+		ObjectMeta: meta.ObjectMeta(name),
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: hard,
+		},
+	}, nil
+}
+
+// CreateLimitRange creates a LimitRange with the default limits and requests
+// for containers.
+func CreateLimitRange(name types.NamespacedName, l *config.Limits) (*corev1.LimitRange, error) {
+	limits, err := ResourceList(map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    l.CPU,
+		corev1.ResourceMemory: l.Memory,
+	})
+	if err != nil {
+		return nil, err
+	}
+	requests, err := ResourceList(map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    l.RequestCPU,
+		corev1.ResourceMemory: l.RequestMemory,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.LimitRange{
+		TypeMeta:   limitRangeTypeMeta,
+		ObjectMeta: meta.ObjectMeta(name),
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{
+					Type:           corev1.LimitTypeContainer,
+					Default:        limits,
+					DefaultRequest: requests,
+				},
+			},
+		},
+	}, nil
+}
+
+// CreateNetworkPolicy creates a NetworkPolicy that denies ingress to all pods
+// in the namespace, except from pods in the same namespace, from the router,
+// and from the CI/CD namespace if one is provided.
+func CreateNetworkPolicy(name types.NamespacedName, cicdNS string) *networkingv1.NetworkPolicy {
+	ingress := []networkingv1.NetworkPolicyIngressRule{
+		{
+			From: []networkingv1.NetworkPolicyPeer{
+				{PodSelector: &metav1.LabelSelector{}},
+			},
+		},
+		{
+			From: []networkingv1.NetworkPolicyPeer{
+				{NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{routerPolicyGroupLabel: "ingress"},
+				}},
+			},
+		},
+	}
+	if cicdNS != "" {
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			From: []networkingv1.NetworkPolicyPeer{
+				{NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{namespaceNameLabel: cicdNS},
+				}},
+			},
+		})
+	}
+	return &networkingv1.NetworkPolicy{
+		TypeMeta:   networkPolicyTypeMeta,
+		ObjectMeta: meta.ObjectMeta(name),
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			Ingress:     ingress,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// ResourceList parses the quantities of the resources, the resources without
+// a quantity are not included.
+func ResourceList(quantities map[corev1.ResourceName]string) (corev1.ResourceList, error) {
+	list := corev1.ResourceList{}
+	for name, v := range quantities {
+		if v == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s quantity %q: %w", name, v, err)
+		}
+		list[name] = q
+	}
+	return list, nil
+}
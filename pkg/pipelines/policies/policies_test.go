@@ -0,0 +1,106 @@
+package policies
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/test"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var quantityComparer = cmp.Comparer(func(x, y resource.Quantity) bool {
+	return x.Cmp(y) == 0
+})
+
+func TestCreateResourceQuota(t *testing.T) {
+	quota, err := CreateResourceQuota(meta.NamespacedName("dev", "dev-quota"), &config.Quota{CPU: "2", Memory: "4Gi", Pods: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &corev1.ResourceQuota{
+		TypeMeta:   resourceQuotaTypeMeta,
+		ObjectMeta: metav1.ObjectMeta{Name: "dev-quota", Namespace: "dev"},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: corev1.ResourceList{
+				corev1.ResourceLimitsCPU:    resource.MustParse("2"),
+				corev1.ResourceLimitsMemory: resource.MustParse("4Gi"),
+				corev1.ResourcePods:         resource.MustParse("10"),
+			},
+		},
+	}
+	if diff := cmp.Diff(want, quota, quantityComparer); diff != "" {
+		t.Fatalf("CreateResourceQuota() failed:\n%s", diff)
+	}
+}
+
+func TestCreateResourceQuotaWithInvalidQuantity(t *testing.T) {
+	_, err := CreateResourceQuota(meta.NamespacedName("dev", "dev-quota"), &config.Quota{CPU: "two"})
+	test.AssertErrorMatch(t, `failed to parse limits.cpu quantity "two"`, err)
+}
+
+func TestCreateLimitRange(t *testing.T) {
+	limits, err := CreateLimitRange(meta.NamespacedName("dev", "dev-limits"), &config.Limits{CPU: "500m", Memory: "512Mi", RequestCPU: "100m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &corev1.LimitRange{
+		TypeMeta:   limitRangeTypeMeta,
+		ObjectMeta: metav1.ObjectMeta{Name: "dev-limits", Namespace: "dev"},
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{
+					Type: corev1.LimitTypeContainer,
+					Default: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("512Mi"),
+					},
+					DefaultRequest: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("100m"),
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, limits, quantityComparer); diff != "" {
+		t.Fatalf("CreateLimitRange() failed:\n%s", diff)
+	}
+}
+
+func TestCreateNetworkPolicy(t *testing.T) {
+	np := CreateNetworkPolicy(meta.NamespacedName("dev", "dev-network-isolation"), "cicd")
+
+	want := &networkingv1.NetworkPolicy{
+		TypeMeta:   networkPolicyTypeMeta,
+		ObjectMeta: metav1.ObjectMeta{Name: "dev-network-isolation", Namespace: "dev"},
+		Spec: networkingv1.NetworkPolicySpec{
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{From: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}},
+				{From: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"network.openshift.io/policy-group": "ingress"},
+				}}}},
+				{From: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"kubernetes.io/metadata.name": "cicd"},
+				}}}},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+	if diff := cmp.Diff(want, np); diff != "" {
+		t.Fatalf("CreateNetworkPolicy() failed:\n%s", diff)
+	}
+}
+
+func TestCreateNetworkPolicyWithoutCICD(t *testing.T) {
+	np := CreateNetworkPolicy(meta.NamespacedName("dev", "dev-network-isolation"), "")
+
+	if l := len(np.Spec.Ingress); l != 2 {
+		t.Fatalf("CreateNetworkPolicy() got %d ingress rules, want 2", l)
+	}
+}
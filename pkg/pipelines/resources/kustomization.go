@@ -1,12 +1,188 @@
 package resources
 
-import "sort"
+import (
+	"encoding/json"
+	"sort"
+)
 
 // Kustomization is a structural representation of the Kustomize file format.
+//
+// Fields that are not represented here are kept in Extra, so that a
+// kustomization survives being read and written back.
 type Kustomization struct {
-	Resources    []string          `json:"resources,omitempty"`
-	Bases        []string          `json:"bases,omitempty"`
-	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+	Resources             []string             `json:"resources,omitempty"`
+	Bases                 []string             `json:"bases,omitempty"`
+	Components            []string             `json:"components,omitempty"`
+	CommonLabels          map[string]string    `json:"commonLabels,omitempty"`
+	Images                []Image              `json:"images,omitempty"`
+	PatchesStrategicMerge []string             `json:"patchesStrategicMerge,omitempty"`
+	ConfigMapGenerator    []ConfigMapGenerator `json:"configMapGenerator,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+// kustomizationFields is used to marshal the fields of a Kustomization
+// without recursing into the custom marshalling.
+type kustomizationFields Kustomization
+
+// MarshalJSON implements the json.Marshaler interface, writing the Extra
+// fields alongside the known fields.
+func (k Kustomization) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(kustomizationFields(k), k.Extra)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, keeping the fields
+// that are not known in Extra.
+func (k *Kustomization) UnmarshalJSON(b []byte) error {
+	var known kustomizationFields
+	if err := json.Unmarshal(b, &known); err != nil {
+		return err
+	}
+	extra, err := unknownFields(b, knownKustomizationFields)
+	if err != nil {
+		return err
+	}
+	*k = Kustomization(known)
+	k.Extra = extra
+	return nil
+}
+
+// marshalWithExtra marshals the known fields, and the extra fields alongside
+// them.
+func marshalWithExtra(known interface{}, extra map[string]interface{}) ([]byte, error) {
+	b, err := json.Marshal(known)
+	if err != nil || len(extra) == 0 {
+		return b, err
+	}
+	fields := map[string]interface{}{}
+	for key, v := range extra {
+		fields[key] = v
+	}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// unknownFields returns the fields in the JSON object that are not in the
+// known fields, or nil if there are none.
+func unknownFields(b []byte, known []string) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for _, key := range known {
+		delete(fields, key)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+var knownKustomizationFields = []string{
+	"resources", "bases", "components", "commonLabels", "images", "patchesStrategicMerge", "configMapGenerator",
+}
+
+// MergeKustomization returns a copy of the existing kustomization with the
+// fields that are generated by kam, the resources, bases and common labels,
+// replaced with the fields from the generated kustomization.
+//
+// The components are only replaced if the generated components are not nil,
+// so that components added to kustomizations that kam doesn't generate
+// components for are kept.
+func MergeKustomization(existing, generated *Kustomization) *Kustomization {
+	merged := *existing
+	merged.Resources = generated.Resources
+	merged.Bases = generated.Bases
+	merged.CommonLabels = generated.CommonLabels
+	if generated.Components != nil {
+		merged.Components = generated.Components
+	}
+	return &merged
+}
+
+// FixBases moves the deprecated bases to the end of the resources, returning
+// true if there were bases to move.
+func (k *Kustomization) FixBases() bool {
+	if len(k.Bases) == 0 {
+		return false
+	}
+	k.Resources = append(k.Resources, k.Bases...)
+	k.Bases = nil
+	return true
+}
+
+// Image overrides the name, tag or digest of the images with the Name.
+//
+// Fields that are not represented here are kept in Extra.
+type Image struct {
+	Name    string `json:"name,omitempty"`
+	NewName string `json:"newName,omitempty"`
+	NewTag  string `json:"newTag,omitempty"`
+	Digest  string `json:"digest,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+type imageFields Image
+
+var knownImageFields = []string{"name", "newName", "newTag", "digest"}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (i Image) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(imageFields(i), i.Extra)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (i *Image) UnmarshalJSON(b []byte) error {
+	var known imageFields
+	if err := json.Unmarshal(b, &known); err != nil {
+		return err
+	}
+	extra, err := unknownFields(b, knownImageFields)
+	if err != nil {
+		return err
+	}
+	*i = Image(known)
+	i.Extra = extra
+	return nil
+}
+
+// ConfigMapGenerator generates a ConfigMap from files and literals.
+//
+// Fields that are not represented here, e.g. the behavior or options, are
+// kept in Extra.
+type ConfigMapGenerator struct {
+	Name     string   `json:"name,omitempty"`
+	Files    []string `json:"files,omitempty"`
+	Literals []string `json:"literals,omitempty"`
+
+	Extra map[string]interface{} `json:"-"`
+}
+
+type configMapGeneratorFields ConfigMapGenerator
+
+var knownConfigMapGeneratorFields = []string{"name", "files", "literals"}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (g ConfigMapGenerator) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(configMapGeneratorFields(g), g.Extra)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (g *ConfigMapGenerator) UnmarshalJSON(b []byte) error {
+	var known configMapGeneratorFields
+	if err := json.Unmarshal(b, &known); err != nil {
+		return err
+	}
+	extra, err := unknownFields(b, knownConfigMapGeneratorFields)
+	if err != nil {
+		return err
+	}
+	*g = ConfigMapGenerator(known)
+	g.Extra = extra
+	return nil
 }
 
 func (k *Kustomization) AddResources(s ...string) {
@@ -25,3 +201,36 @@ func removeDuplicatesAndSort(s []string) []string {
 	sort.Strings(out)
 	return out
 }
+
+// AddPatches adds strategic merge patch files to the kustomization.
+func (k *Kustomization) AddPatches(s ...string) {
+	k.PatchesStrategicMerge = removeDuplicatesAndSort(append(k.PatchesStrategicMerge, s...))
+}
+
+// SetImage adds the image override, replacing any existing override for an
+// image with the same name, the Extra fields of the existing override are
+// kept if the image has none.
+func (k *Kustomization) SetImage(img Image) {
+	for i := range k.Images {
+		if k.Images[i].Name == img.Name {
+			if img.Extra == nil {
+				img.Extra = k.Images[i].Extra
+			}
+			k.Images[i] = img
+			return
+		}
+	}
+	k.Images = append(k.Images, img)
+}
+
+// AddConfigMapFiles adds files to the generator for the named ConfigMap,
+// adding the generator if it doesn't exist.
+func (k *Kustomization) AddConfigMapFiles(name string, files ...string) {
+	for i := range k.ConfigMapGenerator {
+		if k.ConfigMapGenerator[i].Name == name {
+			k.ConfigMapGenerator[i].Files = removeDuplicatesAndSort(append(k.ConfigMapGenerator[i].Files, files...))
+			return
+		}
+	}
+	k.ConfigMapGenerator = append(k.ConfigMapGenerator, ConfigMapGenerator{Name: name, Files: removeDuplicatesAndSort(files)})
+}
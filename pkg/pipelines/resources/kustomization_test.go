@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
 )
 
 func Test_AddResource(t *testing.T) {
@@ -33,3 +34,160 @@ func Test_AddResource_sorts_elements(t *testing.T) {
 		t.Fatalf("failed to sort resources:\n%s", diff)
 	}
 }
+
+func Test_SetImage_replaces_existing_image(t *testing.T) {
+	k := Kustomization{}
+	k.SetImage(Image{Name: "quay.io/example/web", NewTag: "v1"})
+	k.SetImage(Image{Name: "quay.io/example/api", NewTag: "v1"})
+	k.SetImage(Image{Name: "quay.io/example/web", NewTag: "v2"})
+
+	want := []Image{
+		{Name: "quay.io/example/web", NewTag: "v2"},
+		{Name: "quay.io/example/api", NewTag: "v1"},
+	}
+	if diff := cmp.Diff(want, k.Images); diff != "" {
+		t.Fatalf("failed to set image:\n%s", diff)
+	}
+}
+
+func Test_AddConfigMapFiles(t *testing.T) {
+	k := Kustomization{}
+	k.AddConfigMapFiles("web-config", "app.properties")
+	k.AddConfigMapFiles("web-config", "log.properties", "app.properties")
+
+	want := []ConfigMapGenerator{
+		{Name: "web-config", Files: []string{"app.properties", "log.properties"}},
+	}
+	if diff := cmp.Diff(want, k.ConfigMapGenerator); diff != "" {
+		t.Fatalf("failed to add config map files:\n%s", diff)
+	}
+}
+
+func Test_Kustomization_round_trips_unknown_fields(t *testing.T) {
+	data := `bases:
+- ../base
+namespace: shop
+patches:
+- path: replicas.yaml
+`
+	k := Kustomization{}
+	if err := yaml.Unmarshal([]byte(data), &k); err != nil {
+		t.Fatal(err)
+	}
+	want := Kustomization{
+		Bases: []string{"../base"},
+		Extra: map[string]interface{}{
+			"namespace": "shop",
+			"patches":   []interface{}{map[string]interface{}{"path": "replicas.yaml"}},
+		},
+	}
+	if diff := cmp.Diff(want, k); diff != "" {
+		t.Fatalf("failed to unmarshal kustomization:\n%s", diff)
+	}
+
+	b, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(data, string(b)); diff != "" {
+		t.Fatalf("failed to marshal kustomization:\n%s", diff)
+	}
+}
+
+func Test_Kustomization_round_trips_unknown_nested_fields(t *testing.T) {
+	data := `configMapGenerator:
+- behavior: merge
+  envs:
+  - app.env
+  files:
+  - config.yaml
+  name: cfg
+  options:
+    disableNameSuffixHash: true
+images:
+- name: quay.io/example/web
+  newTag: v2
+  tagSuffix: -debug
+resources:
+- ../base
+`
+	k := Kustomization{}
+	if err := yaml.Unmarshal([]byte(data), &k); err != nil {
+		t.Fatal(err)
+	}
+	want := []ConfigMapGenerator{
+		{
+			Name:  "cfg",
+			Files: []string{"config.yaml"},
+			Extra: map[string]interface{}{
+				"behavior": "merge",
+				"envs":     []interface{}{"app.env"},
+				"options":  map[string]interface{}{"disableNameSuffixHash": true},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, k.ConfigMapGenerator); diff != "" {
+		t.Fatalf("failed to unmarshal the config map generators:\n%s", diff)
+	}
+
+	k.AddConfigMapFiles("cfg", "other.yaml")
+	k.SetImage(Image{Name: "quay.io/example/web", NewTag: "v3"})
+	b, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantData := `configMapGenerator:
+- behavior: merge
+  envs:
+  - app.env
+  files:
+  - config.yaml
+  - other.yaml
+  name: cfg
+  options:
+    disableNameSuffixHash: true
+images:
+- name: quay.io/example/web
+  newTag: v3
+  tagSuffix: -debug
+resources:
+- ../base
+`
+	if diff := cmp.Diff(wantData, string(b)); diff != "" {
+		t.Fatalf("failed to marshal kustomization:\n%s", diff)
+	}
+}
+
+func Test_MergeKustomization(t *testing.T) {
+	existing := &Kustomization{
+		Bases:        []string{"../old-base"},
+		CommonLabels: map[string]string{"team": "shop"},
+		Images:       []Image{{Name: "quay.io/example/web", NewTag: "v2"}},
+		Extra:        map[string]interface{}{"namespace": "shop"},
+	}
+	generated := &Kustomization{Bases: []string{"../base"}}
+
+	want := &Kustomization{
+		Bases:  []string{"../base"},
+		Images: []Image{{Name: "quay.io/example/web", NewTag: "v2"}},
+		Extra:  map[string]interface{}{"namespace": "shop"},
+	}
+	if diff := cmp.Diff(want, MergeKustomization(existing, generated)); diff != "" {
+		t.Fatalf("failed to merge kustomization:\n%s", diff)
+	}
+}
+
+func Test_FixBases(t *testing.T) {
+	k := Kustomization{Resources: []string{"deployment.yaml"}, Bases: []string{"../base"}}
+
+	if !k.FixBases() {
+		t.Fatal("FixBases() returned false, want true")
+	}
+	want := Kustomization{Resources: []string{"deployment.yaml", "../base"}}
+	if diff := cmp.Diff(want, k); diff != "" {
+		t.Fatalf("failed to fix bases:\n%s", diff)
+	}
+	if k.FixBases() {
+		t.Fatal("FixBases() returned true with no bases, want false")
+	}
+}
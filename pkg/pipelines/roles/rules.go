@@ -0,0 +1,136 @@
+package roles
+
+import (
+	v1rbac "k8s.io/api/rbac/v1"
+)
+
+var applyVerbs = []string{"get", "create", "patch"}
+
+// updateVerbs are the verbs for the cluster-scoped objects that already exist
+// and are limited by name.
+var updateVerbs = []string{"get", "patch"}
+
+// CICDRules are the rules for the Role in the CI/CD namespace, when the RBAC
+// mode is minimal, they allow the CI dry-run to apply the CI/CD configuration.
+var CICDRules = []v1rbac.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{"configmaps", "secrets", "services", "serviceaccounts"},
+		Verbs:     applyVerbs,
+	},
+	{
+		APIGroups: []string{"rbac.authorization.k8s.io"},
+		Resources: []string{"roles", "rolebindings"},
+		Verbs:     applyVerbs,
+	},
+	{
+		APIGroups: []string{"tekton.dev"},
+		Resources: []string{"tasks", "pipelines"},
+		Verbs:     applyVerbs,
+	},
+	{
+		APIGroups: []string{"triggers.tekton.dev"},
+		Resources: []string{"eventlisteners", "triggerbindings", "triggertemplates"},
+		Verbs:     applyVerbs,
+	},
+	{
+		APIGroups: []string{"route.openshift.io"},
+		Resources: []string{"routes"},
+		Verbs:     applyVerbs,
+	},
+	{
+		APIGroups: []string{"bitnami.com"},
+		Resources: []string{"sealedsecrets"},
+		Verbs:     applyVerbs,
+	},
+}
+
+// ArgoCDRules are the rules for the Role in the Argo CD namespace, when the
+// RBAC mode is minimal, they allow the CI dry-run to apply the Argo CD
+// applications.
+var ArgoCDRules = []v1rbac.PolicyRule{
+	{
+		APIGroups: []string{"argoproj.io"},
+		Resources: []string{"applications", "argocds"},
+		Verbs:     applyVerbs,
+	},
+	{
+		APIGroups: []string{"rbac.authorization.k8s.io"},
+		Resources: []string{"roles", "rolebindings"},
+		Verbs:     applyVerbs,
+	},
+}
+
+// EnvironmentRules are the rules for the Role in each environment namespace,
+// when the RBAC mode is minimal, they allow the CI dry-run to apply the
+// applications and the policies of the environment.
+var EnvironmentRules = []v1rbac.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{"configmaps", "secrets", "services", "serviceaccounts", "resourcequotas", "limitranges"},
+		Verbs:     applyVerbs,
+	},
+	{
+		APIGroups: []string{"apps"},
+		Resources: []string{"deployments", "statefulsets"},
+		Verbs:     applyVerbs,
+	},
+	{
+		APIGroups: []string{"route.openshift.io"},
+		Resources: []string{"routes", "routes/custom-host"},
+		Verbs:     applyVerbs,
+	},
+	{
+		APIGroups: []string{"networking.k8s.io"},
+		Resources: []string{"networkpolicies"},
+		Verbs:     applyVerbs,
+	},
+	{
+		APIGroups: []string{"rbac.authorization.k8s.io"},
+		Resources: []string{"roles", "rolebindings"},
+		Verbs:     applyVerbs,
+	},
+}
+
+// InternalRegistryRules are the rules for the Role in the namespace of the
+// internal registry, when the RBAC mode is minimal, they allow the CI dry-run
+// to apply the binding to the ClusterRole for pushing images.
+func InternalRegistryRules(clusterRoleName string) []v1rbac.PolicyRule {
+	return []v1rbac.PolicyRule{
+		{
+			APIGroups: []string{"rbac.authorization.k8s.io"},
+			Resources: []string{"roles", "rolebindings"},
+			Verbs:     applyVerbs,
+		},
+		{
+			APIGroups:     []string{"rbac.authorization.k8s.io"},
+			Resources:     []string{"clusterroles"},
+			ResourceNames: []string{clusterRoleName},
+			Verbs:         []string{"bind"},
+		},
+	}
+}
+
+// NamespacesRules are the rules for the ClusterRole, when the RBAC mode is
+// minimal, they allow the CI dry-run to apply the namespaces, and the
+// ClusterRole and its binding, and nothing else that is cluster-scoped.
+//
+// The namespaces can only be updated, and Kubernetes doesn't allow the CI
+// dry-run to grant itself the namespaces that are added to the ClusterRole, so
+// an admin must apply the namespace and the ClusterRole of a new environment.
+func NamespacesRules(namespaces []string) []v1rbac.PolicyRule {
+	return []v1rbac.PolicyRule{
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"namespaces"},
+			ResourceNames: namespaces,
+			Verbs:         updateVerbs,
+		},
+		{
+			APIGroups:     []string{"rbac.authorization.k8s.io"},
+			Resources:     []string{"clusterroles", "clusterrolebindings"},
+			ResourceNames: []string{NamespacesClusterRoleName, NamespacesClusterRoleBindingName},
+			Verbs:         updateVerbs,
+		},
+	}
+}
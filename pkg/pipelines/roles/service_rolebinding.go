@@ -20,6 +20,19 @@ const (
 	// ClusterRoleName is the name of the ClusterRole created to allow the
 	// servie account to deploy into different environments.
 	ClusterRoleName = "pipelines-clusterrole"
+
+	// RoleName is the name of the Roles created to allow the service account
+	// to deploy into namespaces, when the RBAC mode is minimal.
+	RoleName = "pipelines-role"
+
+	// NamespacesClusterRoleName is the name of the ClusterRole created to
+	// allow the service account to apply the namespaces, when the RBAC mode is
+	// minimal.
+	NamespacesClusterRoleName = "pipelines-namespaces-clusterrole"
+
+	// NamespacesClusterRoleBindingName is the name of the binding of the
+	// NamespacesClusterRoleName to the service account.
+	NamespacesClusterRoleBindingName = "pipelines-namespaces-clusterrole-binding"
 )
 
 // CreateServiceAccount creates and returns a new ServiceAccount in the provided
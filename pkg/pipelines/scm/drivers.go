@@ -0,0 +1,95 @@
+package scm
+
+import (
+	"net/url"
+	"sort"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
+)
+
+// wellKnownHosts are the hosts that go-scm identifies the driver for without
+// a mapping.
+var wellKnownHosts = map[string]bool{
+	"github.com": true,
+	"gitlab.com": true,
+}
+
+// IsWellKnownHost returns true if the host of the repository URL is a
+// well-known host, e.g. github.com, rather than a host that needs a driver
+// mapping, e.g. an enterprise host.
+func IsWellKnownHost(repoURL string) (bool, error) {
+	host, err := HostnameFromURL(repoURL)
+	if err != nil {
+		return false, err
+	}
+	return wellKnownHosts[host], nil
+}
+
+// DriverResolver resolves the go-scm driver, e.g. github or gitlab, for the
+// hosts of Git repositories.
+//
+// The well-known hosts, and the hosts in the GIT_DRIVERS environment
+// variable, are resolved in addition to the hosts that the resolver is
+// created with. A nil DriverResolver resolves only those hosts.
+//
+// Resolvers are independent of each other, and of the go-scm
+// factory.DefaultIdentifier, so that manifests with different private hosts
+// can be used together.
+type DriverResolver struct {
+	identifier factory.HostDriverIdentifier
+}
+
+// NewDriverResolver creates a DriverResolver for the hosts, a mapping of
+// hostname to driver.
+func NewDriverResolver(hosts map[string]string) *DriverResolver {
+	names := []string{}
+	for k := range hosts {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	mappings := []factory.MappingFunc{}
+	for _, k := range names {
+		mappings = append(mappings, factory.Mapping(k, hosts[k]))
+	}
+	return &DriverResolver{identifier: factory.NewDriverIdentifier(mappings...)}
+}
+
+// Identify returns the driver for the host.
+func (r *DriverResolver) Identify(host string) (string, error) {
+	if r == nil {
+		return factory.NewDriverIdentifier().Identify(host)
+	}
+	return r.identifier.Identify(host)
+}
+
+// NewClient creates a go-scm client for the host of the repository URL, the
+// password of the URL, if any, is used as the OAuth token.
+//
+// SSH URLs are accessed with the https API of the host.
+//
+// This is equivalent to the go-scm factory.FromRepoURL, with the driver
+// resolved by the DriverResolver.
+func (r *DriverResolver) NewClient(repoURL string) (*scm.Client, error) {
+	httpsURL, err := giturl.HTTPS(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(httpsURL)
+	if err != nil {
+		return nil, err
+	}
+	auth := ""
+	if password, ok := u.User.Password(); ok {
+		auth = password
+	}
+	driver, err := r.Identify(u.Host)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/"
+	u.User = nil
+	return factory.NewClient(driver, u.String(), auth)
+}
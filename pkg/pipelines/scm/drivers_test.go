@@ -0,0 +1,138 @@
+package scm
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestDriverResolverIdentify(t *testing.T) {
+	drivers := NewDriverResolver(map[string]string{
+		"github.example.com": "github",
+		"gitlab.example.com": "gitlab",
+	})
+
+	tests := []struct {
+		host    string
+		want    string
+		wantErr string
+	}{
+		{"github.com", "github", ""},
+		{"gitlab.com", "gitlab", ""},
+		{"github.example.com", "github", ""},
+		{"gitlab.example.com", "gitlab", ""},
+		{"example.com", "", "unable to identify driver from hostname: example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(rt *testing.T) {
+			got, err := drivers.Identify(tt.host)
+			if !test.ErrorMatch(rt, tt.wantErr, err) {
+				rt.Fatalf("Identify() got error %v, want %s", err, tt.wantErr)
+			}
+			if got != tt.want {
+				rt.Fatalf("Identify() got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsWellKnownHost(t *testing.T) {
+	tests := []struct {
+		repoURL string
+		want    bool
+	}{
+		{"https://github.com/org/repo.git", true},
+		{"git@gitlab.com:group/subgroup/repo.git", true},
+		{"https://GitHub.com/org/repo.git", true},
+		{"https://github.example.com/org/repo.git", false},
+		{"ssh://git@gitlab.example.com:2222/org/repo.git", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repoURL, func(rt *testing.T) {
+			got, err := IsWellKnownHost(tt.repoURL)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if got != tt.want {
+				rt.Fatalf("IsWellKnownHost() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNilDriverResolverIdentifiesWellKnownHosts(t *testing.T) {
+	var drivers *DriverResolver
+
+	got, err := drivers.Identify("gitlab.com")
+	assertNoError(t, err)
+	if got != "gitlab" {
+		t.Fatalf("Identify() got %q, want gitlab", got)
+	}
+
+	_, err = drivers.Identify("gitlab.example.com")
+	if err == nil {
+		t.Fatal("Identify() identified a private host")
+	}
+}
+
+func TestDriverResolversAreIndependent(t *testing.T) {
+	var wg sync.WaitGroup
+	for _, driver := range []string{"github", "gitlab", "github", "gitlab"} {
+		wg.Add(1)
+		go func(driver string) {
+			defer wg.Done()
+			drivers := NewDriverResolver(map[string]string{"example.com": driver})
+			got, err := GetDriverName("https://example.com/org/repo.git", drivers)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got != driver {
+				t.Errorf("GetDriverName() got %q, want %q", got, driver)
+			}
+		}(driver)
+	}
+	wg.Wait()
+}
+
+func TestNewRepositoryWithPrivateHost(t *testing.T) {
+	drivers := NewDriverResolver(map[string]string{"gitlab.example.com": "gitlab"})
+
+	repo, err := NewRepository("https://gitlab.example.com/org/test.git", drivers)
+	assertNoError(t, err)
+
+	if _, ok := repo.(*repository).spec.(*gitlabSpec); !ok {
+		t.Fatalf("NewRepository() returned %T, want a GitLab repository", repo.(*repository).spec)
+	}
+}
+
+func TestDriverResolverNewClient(t *testing.T) {
+	drivers := NewDriverResolver(map[string]string{"gitlab.example.com": "gitlab"})
+
+	client, err := drivers.NewClient("https://:token@gitlab.example.com/org/test.git")
+	assertNoError(t, err)
+
+	if got := client.Driver.String(); got != "gitlab" {
+		t.Fatalf("NewClient() got a %s client, want gitlab", got)
+	}
+	if got := client.BaseURL.String(); got != "https://gitlab.example.com/" {
+		t.Fatalf("NewClient() got base URL %s, want https://gitlab.example.com/", got)
+	}
+}
+
+func TestDriverResolverNewClientWithSSHURL(t *testing.T) {
+	drivers := NewDriverResolver(map[string]string{"gitlab.example.com": "gitlab"})
+
+	client, err := drivers.NewClient("ssh://git@gitlab.example.com:2222/org/test.git")
+	assertNoError(t, err)
+
+	if got := client.Driver.String(); got != "gitlab" {
+		t.Fatalf("NewClient() got a %s client, want gitlab", got)
+	}
+	if got := client.BaseURL.String(); got != "https://gitlab.example.com/" {
+		t.Fatalf("NewClient() got base URL %s, want https://gitlab.example.com/", got)
+	}
+}
@@ -1,9 +1,13 @@
 package scm
 
 import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"net/http"
 	"net/url"
 	"strings"
 
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
@@ -11,14 +15,19 @@ import (
 const (
 	githubPushEventFilters = "(header.match('X-GitHub-Event', 'push') && body.repository.full_name == '%s')"
 	githubType             = "github"
+	githubPushBinding      = "github-push-binding"
+	githubSSHPushBinding   = "github-ssh-push-binding"
 )
 
 type githubSpec struct {
 	pushBinding string
+	ssh         bool // The repository is cloned with the SSH URL.
 }
 
 func init() {
 	gits[githubType] = newGitHub
+	pushBindings[githubPushBinding] = true
+	pushBindings[githubSSHPushBinding] = true
 }
 
 func newGitHub(rawURL string) (Repository, error) {
@@ -26,7 +35,11 @@ func newGitHub(rawURL string) (Repository, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &repository{url: rawURL, path: path, spec: &githubSpec{pushBinding: "github-push-binding"}}, nil
+	spec := &githubSpec{pushBinding: githubPushBinding}
+	if giturl.IsSSH(rawURL) {
+		spec = &githubSpec{pushBinding: githubSSHPushBinding, ssh: true}
+	}
+	return &repository{url: rawURL, path: path, spec: spec}, nil
 }
 
 func proccessGitHubPath(parsedURL *url.URL) (string, error) {
@@ -47,8 +60,13 @@ func (r *githubSpec) pushBindingName() string {
 }
 
 func (r *githubSpec) pushBindingParams() []triggersv1.Param {
+	cloneURL := "$(body.repository.clone_url)"
+	if r.ssh {
+		cloneURL = "$(body.repository.ssh_url)"
+	}
 	return []triggersv1.Param{
-		createBindingParam("gitrepositoryurl", "$(body.repository.clone_url)"),
+		createBindingParam("gitrepositoryurl", cloneURL),
+		createBindingParam(triggers.GitRepositoryHTTPSURL, "$(body.repository.clone_url)"),
 		createBindingParam("fullname", "$(body.repository.full_name)"),
 		createBindingParam(triggers.GitRef, "$(extensions.ref)"),
 		createBindingParam(triggers.GitCommitID, "$(body.head_commit.id)"),
@@ -69,3 +87,26 @@ func (r *githubSpec) eventInterceptor(secretNamespace, secretName string) (*trig
 	}
 	return eventInterceptorWithSecret(githubType, raw), nil
 }
+
+func (r *githubSpec) pushEventPayload(repoURL, path string, event PushEvent) interface{} {
+	repository := map[string]interface{}{
+		"clone_url": repoURL,
+		"full_name": path,
+	}
+	if r.ssh {
+		repository["clone_url"] = httpsCloneURL(repoURL)
+		repository["ssh_url"] = repoURL
+	}
+	return map[string]interface{}{
+		"ref":         "refs/heads/" + event.Ref,
+		"after":       event.CommitID,
+		"repository":  repository,
+		"head_commit": commitPayload(event),
+	}
+}
+
+func (r *githubSpec) signPushEvent(headers http.Header, body []byte, secret string) {
+	headers.Set("X-GitHub-Event", "push")
+	headers.Set("X-Hub-Signature", "sha1="+hmacHex(sha1.New, secret, body))
+	headers.Set("X-Hub-Signature-256", "sha256="+hmacHex(sha256.New, secret, body))
+}
@@ -1,6 +1,10 @@
 package scm
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -12,7 +16,7 @@ import (
 )
 
 func TestCreatePushBindingForGithub(t *testing.T) {
-	repo, err := NewRepository("http://github.com/org/test")
+	repo, err := NewRepository("http://github.com/org/test", nil)
 	assertNoError(t, err)
 	want := triggersv1.TriggerBinding{
 		TypeMeta: triggers.TriggerBindingTypeMeta,
@@ -26,6 +30,10 @@ func TestCreatePushBindingForGithub(t *testing.T) {
 					Name:  "gitrepositoryurl",
 					Value: "$(body.repository.clone_url)",
 				},
+				{
+					Name:  triggers.GitRepositoryHTTPSURL,
+					Value: "$(body.repository.clone_url)",
+				},
 				{
 					Name:  "fullname",
 					Value: "$(body.repository.full_name)",
@@ -62,8 +70,25 @@ func TestCreatePushBindingForGithub(t *testing.T) {
 	}
 }
 
+func TestCreatePushBindingForGithubSSHURL(t *testing.T) {
+	repo, err := NewRepository("git@github.com:org/test.git", nil)
+	assertNoError(t, err)
+
+	got, name := repo.CreatePushBinding("testns")
+	if name != "github-ssh-push-binding" {
+		t.Fatalf("CreatePushBinding() returned a wrong binding: want %v got %v", "github-ssh-push-binding", name)
+	}
+	want := []triggersv1.Param{
+		{Name: "gitrepositoryurl", Value: "$(body.repository.ssh_url)"},
+		{Name: triggers.GitRepositoryHTTPSURL, Value: "$(body.repository.clone_url)"},
+	}
+	if diff := cmp.Diff(want, got.Spec.Params[:2]); diff != "" {
+		t.Fatalf("CreatePushBinding() failed:\n%s", diff)
+	}
+}
+
 func TestCreateCDTriggersForGithub(t *testing.T) {
-	repo, err := NewRepository("http://github.com/org/test")
+	repo, err := NewRepository("http://github.com/org/test", nil)
 	assertNoError(t, err)
 	rawSecret, err := secretParam("secret", "webhook-secret-key")
 	assertNoError(t, err)
@@ -149,11 +174,21 @@ func TestNewGitHubRepository(t *testing.T) {
 			"",
 			"invalid repository path for github: /foo/bar/test.git",
 		},
+		{
+			"git@github.com:foo/bar.git",
+			"foo/bar",
+			"",
+		},
+		{
+			"ssh://git@github.com/foo/bar.git",
+			"foo/bar",
+			"",
+		},
 	}
 
 	for i, tt := range tests {
 		t.Run(fmt.Sprintf("Test %d", i), func(rt *testing.T) {
-			repo, err := NewRepository(tt.url)
+			repo, err := NewRepository(tt.url, nil)
 			if err != nil {
 				if diff := cmp.Diff(tt.errMsg, err.Error()); diff != "" {
 					rt.Fatalf("repo path errMsg mismatch: \n%s", diff)
@@ -167,3 +202,65 @@ func TestNewGitHubRepository(t *testing.T) {
 		})
 	}
 }
+
+func TestCreatePushEventForGithub(t *testing.T) {
+	repo, err := NewRepository("https://github.com/org/test.git", nil)
+	assertNoError(t, err)
+
+	headers, body, err := repo.CreatePushEvent(testPushEvent, "testing")
+	assertNoError(t, err)
+
+	mac := hmac.New(sha256.New, []byte("testing"))
+	mac.Write(body)
+	wantHeaders := map[string]string{
+		"Content-Type":        "application/json",
+		"X-GitHub-Event":      "push",
+		"X-Hub-Signature-256": "sha256=" + hex.EncodeToString(mac.Sum(nil)),
+	}
+	for k, v := range wantHeaders {
+		if got := headers.Get(k); got != v {
+			t.Errorf("CreatePushEvent() header %s got %q, want %q", k, got, v)
+		}
+	}
+
+	want := map[string]interface{}{
+		"ref":   "refs/heads/main",
+		"after": "6113728f27ae82c7b1a177c8d03f9e96e0adf246",
+		"repository": map[string]interface{}{
+			"clone_url": "https://github.com/org/test.git",
+			"full_name": "org/test",
+		},
+		"head_commit": map[string]interface{}{
+			"id":        "6113728f27ae82c7b1a177c8d03f9e96e0adf246",
+			"message":   "Test commit",
+			"timestamp": "2021-03-01T10:00:00Z",
+			"author": map[string]interface{}{
+				"name": "kam",
+			},
+		},
+	}
+	var got map[string]interface{}
+	assertNoError(t, json.Unmarshal(body, &got))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushEvent() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePushEventForGithubSSHURL(t *testing.T) {
+	repo, err := NewRepository("git@github.com:org/test.git", nil)
+	assertNoError(t, err)
+
+	_, body, err := repo.CreatePushEvent(testPushEvent, "testing")
+	assertNoError(t, err)
+
+	want := map[string]interface{}{
+		"clone_url": "https://github.com/org/test.git",
+		"ssh_url":   "git@github.com:org/test.git",
+		"full_name": "org/test",
+	}
+	var got map[string]interface{}
+	assertNoError(t, json.Unmarshal(body, &got))
+	if diff := cmp.Diff(want, got["repository"]); diff != "" {
+		t.Fatalf("CreatePushEvent() failed:\n%s", diff)
+	}
+}
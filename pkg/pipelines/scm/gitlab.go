@@ -1,9 +1,11 @@
 package scm
 
 import (
+	"net/http"
 	"net/url"
 	"strings"
 
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
@@ -11,14 +13,19 @@ import (
 const (
 	gitlabPushEventFilters = "header.match('X-Gitlab-Event','Push Hook') && body.project.path_with_namespace == '%s'"
 	gitlabType             = "gitlab"
+	gitlabPushBinding      = "gitlab-push-binding"
+	gitlabSSHPushBinding   = "gitlab-ssh-push-binding"
 )
 
 type gitlabSpec struct {
 	pushBinding string
+	ssh         bool // The repository is cloned with the SSH URL.
 }
 
 func init() {
 	gits[gitlabType] = newGitLab
+	pushBindings[gitlabPushBinding] = true
+	pushBindings[gitlabSSHPushBinding] = true
 }
 
 func newGitLab(rawURL string) (Repository, error) {
@@ -26,7 +33,11 @@ func newGitLab(rawURL string) (Repository, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &repository{url: rawURL, path: path, spec: &gitlabSpec{pushBinding: "gitlab-push-binding"}}, nil
+	spec := &gitlabSpec{pushBinding: gitlabPushBinding}
+	if giturl.IsSSH(rawURL) {
+		spec = &gitlabSpec{pushBinding: gitlabSSHPushBinding, ssh: true}
+	}
+	return &repository{url: rawURL, path: path, spec: spec}, nil
 }
 
 func proccessGitLabPath(parsedURL *url.URL) (string, error) {
@@ -46,8 +57,13 @@ func (r *gitlabSpec) pushBindingName() string {
 }
 
 func (r *gitlabSpec) pushBindingParams() []triggersv1.Param {
+	cloneURL := "$(body.project.git_http_url)"
+	if r.ssh {
+		cloneURL = "$(body.project.git_ssh_url)"
+	}
 	return []triggersv1.Param{
-		createBindingParam("gitrepositoryurl", "$(body.project.git_http_url)"),
+		createBindingParam("gitrepositoryurl", cloneURL),
+		createBindingParam(triggers.GitRepositoryHTTPSURL, "$(body.project.git_http_url)"),
 		createBindingParam("fullname", "$(body.project.path_with_namespace)"),
 		createBindingParam(triggers.GitRef, "$(extensions.ref)"),
 		createBindingParam(triggers.GitCommitID, "$(body.after)"),
@@ -68,3 +84,26 @@ func (r *gitlabSpec) eventInterceptor(secretNamespace, secretName string) (*trig
 	}
 	return eventInterceptorWithSecret(gitlabType, raw), nil
 }
+
+func (r *gitlabSpec) pushEventPayload(repoURL, path string, event PushEvent) interface{} {
+	project := map[string]interface{}{
+		"git_http_url":        repoURL,
+		"path_with_namespace": path,
+	}
+	if r.ssh {
+		project["git_http_url"] = httpsCloneURL(repoURL)
+		project["git_ssh_url"] = repoURL
+	}
+	return map[string]interface{}{
+		"object_kind": "push",
+		"ref":         "refs/heads/" + event.Ref,
+		"after":       event.CommitID,
+		"project":     project,
+		"commits":     []interface{}{commitPayload(event)},
+	}
+}
+
+func (r *gitlabSpec) signPushEvent(headers http.Header, body []byte, secret string) {
+	headers.Set("X-Gitlab-Event", "Push Hook")
+	headers.Set("X-Gitlab-Token", secret)
+}
@@ -1,6 +1,7 @@
 package scm
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -26,6 +27,10 @@ func TestCreatePushBindingForGitlab(t *testing.T) {
 					Name:  "gitrepositoryurl",
 					Value: "$(body.project.git_http_url)",
 				},
+				{
+					Name:  triggers.GitRepositoryHTTPSURL,
+					Value: "$(body.project.git_http_url)",
+				},
 				{
 					Name:  "fullname",
 					Value: "$(body.project.path_with_namespace)",
@@ -63,8 +68,25 @@ func TestCreatePushBindingForGitlab(t *testing.T) {
 	}
 }
 
+func TestCreatePushBindingForGitlabSSHURL(t *testing.T) {
+	repo, err := newGitLab("git@gitlab.com:org/subgroup/test.git")
+	assertNoError(t, err)
+
+	got, name := repo.CreatePushBinding("testns")
+	if name != "gitlab-ssh-push-binding" {
+		t.Fatalf("CreatePushBinding() returned a wrong binding: want %v got %v", "gitlab-ssh-push-binding", name)
+	}
+	want := []triggersv1.Param{
+		{Name: "gitrepositoryurl", Value: "$(body.project.git_ssh_url)"},
+		{Name: triggers.GitRepositoryHTTPSURL, Value: "$(body.project.git_http_url)"},
+	}
+	if diff := cmp.Diff(want, got.Spec.Params[:2]); diff != "" {
+		t.Fatalf("CreatePushBinding() failed:\n%s", diff)
+	}
+}
+
 func TestCreateCDTriggersForGitLab(t *testing.T) {
-	repo, err := NewRepository("http://gitlab.com/org/test")
+	repo, err := NewRepository("http://gitlab.com/org/test", nil)
 	assertNoError(t, err)
 	rawSecret, err := secretParam("secret", "webhook-secret-key")
 	assertNoError(t, err)
@@ -154,7 +176,7 @@ func TestNewGitlabRepository(t *testing.T) {
 
 	for i, tt := range tests {
 		t.Run(fmt.Sprintf("Test %d", i), func(rt *testing.T) {
-			repo, err := NewRepository(tt.url)
+			repo, err := NewRepository(tt.url, nil)
 			if err != nil {
 				if diff := cmp.Diff(tt.errMsg, err.Error()); diff != "" {
 					rt.Fatalf("repo path errMsg mismatch: \n%s", diff)
@@ -168,3 +190,66 @@ func TestNewGitlabRepository(t *testing.T) {
 		})
 	}
 }
+
+func TestCreatePushEventForGitlab(t *testing.T) {
+	repo, err := NewRepository("https://gitlab.com/org/test.git", nil)
+	assertNoError(t, err)
+
+	headers, body, err := repo.CreatePushEvent(testPushEvent, "testing")
+	assertNoError(t, err)
+
+	wantHeaders := map[string]string{
+		"Content-Type":   "application/json",
+		"X-Gitlab-Event": "Push Hook",
+		"X-Gitlab-Token": "testing",
+	}
+	for k, v := range wantHeaders {
+		if got := headers.Get(k); got != v {
+			t.Errorf("CreatePushEvent() header %s got %q, want %q", k, got, v)
+		}
+	}
+
+	want := map[string]interface{}{
+		"object_kind": "push",
+		"ref":         "refs/heads/main",
+		"after":       "6113728f27ae82c7b1a177c8d03f9e96e0adf246",
+		"project": map[string]interface{}{
+			"git_http_url":        "https://gitlab.com/org/test.git",
+			"path_with_namespace": "org/test",
+		},
+		"commits": []interface{}{
+			map[string]interface{}{
+				"id":        "6113728f27ae82c7b1a177c8d03f9e96e0adf246",
+				"message":   "Test commit",
+				"timestamp": "2021-03-01T10:00:00Z",
+				"author": map[string]interface{}{
+					"name": "kam",
+				},
+			},
+		},
+	}
+	var got map[string]interface{}
+	assertNoError(t, json.Unmarshal(body, &got))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushEvent() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePushEventForGitlabSSHURL(t *testing.T) {
+	repo, err := NewRepository("git@gitlab.com:org/subgroup/test.git", nil)
+	assertNoError(t, err)
+
+	_, body, err := repo.CreatePushEvent(testPushEvent, "testing")
+	assertNoError(t, err)
+
+	want := map[string]interface{}{
+		"git_http_url":        "https://gitlab.com/org/subgroup/test.git",
+		"git_ssh_url":         "git@gitlab.com:org/subgroup/test.git",
+		"path_with_namespace": "org/subgroup/test",
+	}
+	var got map[string]interface{}
+	assertNoError(t, json.Unmarshal(body, &got))
+	if diff := cmp.Diff(want, got["project"]); diff != "" {
+		t.Fatalf("CreatePushEvent() failed:\n%s", diff)
+	}
+}
@@ -1,6 +1,8 @@
 package scm
 
 import (
+	"net/http"
+
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
 
@@ -16,6 +18,10 @@ type Repository interface {
 	// Create an eventlistener trigger for Push event
 	CreatePushTrigger(name, secretName, secretNs, template string, bindings []string) (triggersv1.EventListenerTrigger, error)
 
+	// Create a synthetic Push event with the fields used by the Push TriggerBinding,
+	// signed with the webhook secret
+	CreatePushEvent(event PushEvent, secret string) (http.Header, []byte, error)
+
 	// Git Repository URL
 	URL() string
 }
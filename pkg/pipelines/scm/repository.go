@@ -1,6 +1,10 @@
 package scm
 
 import (
+	"encoding/json"
+	"net/http"
+	"time"
+
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
@@ -11,7 +15,8 @@ const (
 )
 
 var (
-	gits = make(map[string]func(string) (Repository, error))
+	gits         = make(map[string]func(string) (Repository, error))
+	pushBindings = make(map[string]bool)
 )
 
 type repository struct {
@@ -25,12 +30,23 @@ type triggerSpec interface {
 	pushEventFilters() string
 	eventInterceptor(secretNamespace, secretName string) (*triggersv1.EventInterceptor, error)
 	pushBindingName() string
+	pushEventPayload(repoURL, path string, event PushEvent) interface{}
+	signPushEvent(headers http.Header, body []byte, secret string)
+}
+
+// PushEvent describes the commit in a synthetic Push event.
+type PushEvent struct {
+	Ref       string
+	CommitID  string
+	Message   string
+	Author    string
+	Timestamp time.Time
 }
 
 // NewRepository returns a suitable Repository instance
-// based on the driver name (github,gitlab,etc)
-func NewRepository(url string) (Repository, error) {
-	name, err := GetDriverName(url)
+// based on the driver name (github,gitlab,etc), resolved by the drivers.
+func NewRepository(url string, drivers *DriverResolver) (Repository, error) {
+	name, err := GetDriverName(url, drivers)
 	if err != nil {
 		return nil, err
 	}
@@ -43,6 +59,13 @@ func NewRepository(url string) (Repository, error) {
 	return git(url)
 }
 
+// IsPushBindingName returns true if the binding is the push binding of one
+// of the supported Git providers, e.g. github-push-binding or
+// github-ssh-push-binding.
+func IsPushBindingName(name string) bool {
+	return pushBindings[name]
+}
+
 // CreatePushBinding implements the Repository interface.
 func (r *repository) CreatePushBinding(ns string) (triggersv1.TriggerBinding, string) {
 	return triggersv1.TriggerBinding{
@@ -65,6 +88,18 @@ func (r *repository) CreatePushTrigger(name, secretName, secretNS, template stri
 		eventInterceptorForCEL)
 }
 
+// CreatePushEvent implements the Repository interface.
+func (r *repository) CreatePushEvent(event PushEvent, secret string) (http.Header, []byte, error) {
+	body, err := json.Marshal(r.spec.pushEventPayload(r.url, r.path, event))
+	if err != nil {
+		return nil, nil, err
+	}
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+	r.spec.signPushEvent(headers, body, secret)
+	return headers, body, nil
+}
+
 // URL implements the Repository interface.
 func (r *repository) URL() string {
 	return r.url
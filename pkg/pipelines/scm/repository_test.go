@@ -2,13 +2,14 @@ package scm
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
 
 func TestNewRepositoryGitHub(t *testing.T) {
 	githubURL := "http://github.com/org/test"
-	got, err := NewRepository(githubURL)
+	got, err := NewRepository(githubURL, nil)
 	assertNoError(t, err)
 	want, err := newGitHub(githubURL)
 	assertNoError(t, err)
@@ -19,7 +20,7 @@ func TestNewRepositoryGitHub(t *testing.T) {
 
 func TestNewRepositoryGitLab(t *testing.T) {
 	gitlabURL := "http://gitlab.com/org/test"
-	got, err := NewRepository(gitlabURL)
+	got, err := NewRepository(gitlabURL, nil)
 	assertNoError(t, err)
 	want, err := newGitLab(gitlabURL)
 	assertNoError(t, err)
@@ -28,10 +29,27 @@ func TestNewRepositoryGitLab(t *testing.T) {
 	}
 }
 
+func TestIsPushBindingName(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want bool
+	}{
+		{"github-push-binding", true},
+		{"gitlab-push-binding", true},
+		{"github-ssh-push-binding", true},
+		{"gitlab-ssh-push-binding", true},
+		{"dev-app-binding", false},
+	} {
+		if got := IsPushBindingName(tt.name); got != tt.want {
+			t.Errorf("IsPushBindingName(%q) got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
 func TestNewRepositoryForInvalidRepoType(t *testing.T) {
 	githubURL := "http://test.com/org/test"
 	repoType := "test"
-	_, gotErr := NewRepository(githubURL)
+	_, gotErr := NewRepository(githubURL, nil)
 	if gotErr == nil {
 		t.Fatalf("NewRepository() returned an invalid repository of type: %s", repoType)
 	}
@@ -47,3 +65,11 @@ func assertNoError(t *testing.T, err error) {
 		t.Fatal(err)
 	}
 }
+
+var testPushEvent = PushEvent{
+	Ref:       "main",
+	CommitID:  "6113728f27ae82c7b1a177c8d03f9e96e0adf246",
+	Message:   "Test commit",
+	Author:    "kam",
+	Timestamp: time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC),
+}
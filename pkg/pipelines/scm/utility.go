@@ -1,12 +1,16 @@
 package scm
 
 import (
+	"crypto/hmac"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"net/url"
 	"strings"
+	"time"
 
-	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 )
@@ -83,7 +87,7 @@ func createBindingParam(name, value string) triggersv1.Param {
 }
 
 func processRawURL(rawURL string, processPath func(*url.URL) (string, error)) (string, error) {
-	parsedURL, err := url.Parse(rawURL)
+	parsedURL, err := giturl.Parse(rawURL)
 	if err != nil {
 		return "", err
 	}
@@ -94,6 +98,16 @@ func processRawURL(rawURL string, processPath func(*url.URL) (string, error)) (s
 	return path, nil
 }
 
+// httpsCloneURL returns the https form of an SSH clone URL, the URL is
+// returned unchanged if it can't be parsed.
+func httpsCloneURL(repoURL string) string {
+	u, err := giturl.HTTPS(repoURL)
+	if err != nil {
+		return repoURL
+	}
+	return u
+}
+
 func splitRepositoryPath(parsedURL *url.URL) ([]string, error) {
 	var components []string
 	for _, s := range strings.Split(parsedURL.Path, "/") {
@@ -108,23 +122,24 @@ func splitRepositoryPath(parsedURL *url.URL) ([]string, error) {
 	return components, nil
 }
 
-// GetDriverName gets the driver to be used for this repo url, using the go-scm
-// default identifier.
-func GetDriverName(rawURL string) (string, error) {
+// GetDriverName gets the driver to be used for this repo url, using the
+// drivers to resolve the host.
+func GetDriverName(rawURL string, drivers *DriverResolver) (string, error) {
 	host, err := HostnameFromURL(rawURL)
 	if err != nil {
 		return "", err
 	}
-	return factory.DefaultIdentifier.Identify(host)
+	return drivers.Identify(host)
 }
 
-// HostnameFromURL returns the host from a URL.
+// HostnameFromURL returns the host from a URL, for SSH URLs the SSH port is
+// not included.
 func HostnameFromURL(rawURL string) (string, error) {
-	u, err := url.Parse(rawURL)
+	host, err := giturl.Host(rawURL)
 	if err != nil {
 		return "", err
 	}
-	return strings.ToLower(u.Host), nil
+	return strings.ToLower(host), nil
 }
 
 func secretParam(name, key string) ([]byte, error) {
@@ -161,3 +176,20 @@ func eventInterceptorWithSecret(name string, secretInfo []byte) *triggersv1.Even
 		},
 	}
 }
+
+func commitPayload(event PushEvent) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        event.CommitID,
+		"message":   event.Message,
+		"timestamp": event.Timestamp.Format(time.RFC3339),
+		"author": map[string]interface{}{
+			"name": event.Author,
+		},
+	}
+}
+
+func hmacHex(h func() hash.Hash, secret string, body []byte) string {
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -71,6 +71,8 @@ func TestHostnameFromURL(t *testing.T) {
 		{"https://example.com/example/example.git", "example.com", ""},
 		{"https:/%/", "", "parse \"https:/%/\": invalid URL escape \"%/\""},
 		{"https://GITHUB.COM/test/test.git", "github.com", ""},
+		{"git@github.com:example/example.git", "github.com", ""},
+		{"ssh://git@gitlab.example.com:2222/example/example.git", "gitlab.example.com", ""},
 	}
 
 	for _, tt := range hostTests {
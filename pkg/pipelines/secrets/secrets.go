@@ -49,6 +49,25 @@ func CreateUnsealedBasicAuthSecret(name types.NamespacedName, token string,
 	return createBasicAuthSecret(name, token, opts...)
 }
 
+// CreateUnsealedSSHAuthSecret creates an Unsealed Secret with a SSHAuth type
+// secret, with the private key, and the known hosts if they're provided.
+func CreateUnsealedSSHAuthSecret(name types.NamespacedName, privateKey, knownHosts io.Reader,
+	opts ...meta.ObjectMetaOpt) (*corev1.Secret, error) {
+	secret, err := createSecret(name, corev1.SSHAuthPrivateKey, corev1.SecretTypeSSHAuth, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	secret.ObjectMeta = meta.ObjectMeta(name, opts...)
+	if knownHosts != nil {
+		data, err := ioutil.ReadAll(knownHosts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read known hosts: %v", err)
+		}
+		secret.Data["known_hosts"] = data
+	}
+	return secret, nil
+}
+
 // createOpaqueSecret creates a Kubernetes v1/Secret with the provided name and
 // body, and type Opaque.
 func createOpaqueSecret(name types.NamespacedName, data, secretKey string) (*corev1.Secret, error) {
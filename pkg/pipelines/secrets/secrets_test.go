@@ -114,6 +114,56 @@ func TestBasicAuthSecret(t *testing.T) {
 	}
 }
 
+func TestSSHAuthSecret(t *testing.T) {
+	privateKey := []byte("test-private-key")
+	knownHosts := []byte("github.com ssh-ed25519 AAAA")
+	secret, err := CreateUnsealedSSHAuthSecret(meta.NamespacedName("cicd", "git-host-ssh-auth"),
+		bytes.NewReader(privateKey), bytes.NewReader(knownHosts), meta.AddAnnotations(
+			map[string]string{
+				"tekton.dev/git-0": "github.com",
+			}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &corev1.Secret{
+		TypeMeta: secretTypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "git-host-ssh-auth",
+			Namespace: "cicd",
+			Annotations: map[string]string{
+				"tekton.dev/git-0": "github.com",
+			},
+		},
+		Type: corev1.SecretTypeSSHAuth,
+		Data: map[string][]byte{
+			"ssh-privatekey": privateKey,
+			"known_hosts":    knownHosts,
+		},
+	}
+
+	if diff := cmp.Diff(want, secret); diff != "" {
+		t.Fatalf("CreateUnsealedSSHAuthSecret() failed got\n%s", diff)
+	}
+}
+
+func TestSSHAuthSecretWithoutKnownHosts(t *testing.T) {
+	privateKey := []byte("test-private-key")
+	secret, err := CreateUnsealedSSHAuthSecret(meta.NamespacedName("cicd", "git-host-ssh-auth"),
+		bytes.NewReader(privateKey), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]byte{
+		"ssh-privatekey": privateKey,
+	}
+	if diff := cmp.Diff(want, secret.Data); diff != "" {
+		t.Fatalf("CreateUnsealedSSHAuthSecret() failed got\n%s", diff)
+	}
+}
+
 type errorReader struct {
 	err error
 }
@@ -2,23 +2,29 @@ package pipelines
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/deployment"
 	"github.com/redhat-developer/kam/pkg/pipelines/environments"
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/imagerepo"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/policies"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/roles"
+	"github.com/redhat-developer/kam/pkg/pipelines/routes"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
-	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 	"github.com/spf13/afero"
 	corev1 "k8s.io/api/core/v1"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 // AddServiceOptions control how new services are added to the configuration.
@@ -30,40 +36,120 @@ type AddServiceOptions struct {
 	PipelinesFolderPath string
 	ServiceName         string
 	WebhookSecret       string
+
+	// These configure the Deployment, Service and Route that are generated for
+	// the service, nothing is generated if the Image is not provided.
+	Image         string
+	Port          int
+	Replicas      int
+	Env           []string // Environment variables of the form KEY=VAL.
+	CPU           string
+	Memory        string
+	ReadinessPath string
+	Expose        bool
 }
 
 // AddService is the entry-point from the CLI for adding new services.
 func AddService(o *AddServiceOptions, appFs afero.Fs) error {
-	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	out, err := GenerateService(o, appFs)
 	if err != nil {
 		return err
 	}
-	files, otherResources, err := serviceResources(m, appFs, o)
+	err = WriteOutput(appFs, o.PipelinesFolderPath, out)
 	if err != nil {
 		return err
 	}
-
-	_, err = yaml.WriteResources(appFs, o.PipelinesFolderPath, files)
+	m := out.Resources[pipelinesFile].(*config.Manifest)
+	err = createConfigFolder(m, appFs, o)
 	if err != nil {
-		return err
+		return fmt.Errorf("Failed to create config folder : %v", err)
+	}
+	return nil
+}
+
+// GenerateService generates the pipelines file with the new service, and the
+// resources built from it, without writing them.
+//
+// If no webhook secret is provided for the service, one is generated and
+// recorded in the options, as is the default image repository.
+func GenerateService(o *AddServiceOptions, appFs afero.Fs) (*Output, error) {
+	if err := o.validate(); err != nil {
+		return nil, err
 	}
-	_, err = yaml.WriteResources(appFs, filepath.Join(o.PipelinesFolderPath, ".."), otherResources) // Don't call filepath.ToSlash
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = createConfigFolder(m, appFs, o)
+	files, otherResources, err := serviceResources(m, appFs, o)
 	if err != nil {
-		return fmt.Errorf("Failed to create config folder : %v", err)
+		return nil, err
+	}
+	if giturl.IsSSH(o.GitRepoURL) {
+		sshSecret, err := updateSSHAuthSecret(appFs, m, o.PipelinesFolderPath)
+		if err != nil {
+			return nil, err
+		}
+		otherResources = res.Merge(sshSecret, otherResources)
 	}
 	cfg := m.GetPipelinesConfig()
 	if cfg != nil {
-		base := filepath.ToSlash(filepath.Join(o.PipelinesFolderPath, config.PathForPipelines(cfg), "base"))
-		err = updateKustomization(appFs, base)
+		base := filepath.ToSlash(filepath.Join(m.GetLayout().PathForPipelines(cfg), "base"))
+		k, err := pipelinesKustomization(appFs, o.PipelinesFolderPath, base, files)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		files[filepath.ToSlash(filepath.Join(base, Kustomize))] = k
 	}
-	return nil
+	return newOutput(files, otherResources), nil
+}
+
+// updateSSHAuthSecret returns the SSH auth secret, from the folder that
+// contains the pipelines folder, annotated with the hosts of all the SSH
+// repository URLs in the manifest, so that the pipelines can clone a service
+// on another host.
+//
+// Nothing is returned if there's no unencrypted secret to update.
+func updateSSHAuthSecret(appFs afero.Fs, m *config.Manifest, pipelinesFolderPath string) (res.Resources, error) {
+	path, err := homedir.Expand(pipelinesFolderPath)
+	if err != nil {
+		return nil, err
+	}
+	secretFilename := filepath.Join("secrets", sshAuthSecretName+".yaml")
+	data, err := afero.ReadFile(appFs, filepath.Join(path, "..", secretFilename))
+	if os.IsNotExist(err) {
+		return res.Resources{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the SSH auth secret: %w", err)
+	}
+	secret := &corev1.Secret{}
+	if err := sigsyaml.Unmarshal(data, secret); err != nil {
+		return nil, fmt.Errorf("failed to parse the SSH auth secret: %w", err)
+	}
+	repoURLs := []string{m.GitOpsURL}
+	for _, env := range m.Environments {
+		for _, app := range env.Apps {
+			for _, svc := range app.Services {
+				repoURLs = append(repoURLs, svc.SourceURL)
+			}
+		}
+	}
+	hosts, err := sshHosts(repoURLs...)
+	if err != nil {
+		return nil, err
+	}
+	for k := range secret.Annotations {
+		if strings.HasPrefix(k, sshHostAnnotationPrefix) {
+			delete(secret.Annotations, k)
+		}
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	for k, v := range sshHostAnnotations(hosts) {
+		secret.Annotations[k] = v
+	}
+	return res.Resources{secretFilename: secret}, nil
 }
 
 func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions) (res.Resources, res.Resources, error) {
@@ -88,7 +174,7 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 	if cfg != nil {
 		// add the default pipelines if they're absent
 		if env.Pipelines == nil {
-			repo, err := scm.NewRepository(m.GitOpsURL)
+			repo, err := scm.NewRepository(m.GitOpsURL, m.GetDriverResolver())
 			if err != nil {
 				return nil, nil, err
 			}
@@ -131,9 +217,16 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 			}
 
 			files = res.Merge(resources, files)
+			// The bindings of the service are not changed when it's built, so
+			// the push binding is the one for the provider of the service
+			// repository.
+			svcRepo, err := scm.NewRepository(o.GitRepoURL, m.GetDriverResolver())
+			if err != nil {
+				return nil, nil, err
+			}
 			svc.Pipelines = &config.Pipelines{
 				Integration: &config.TemplateBinding{
-					Bindings: append([]string{bindingName}, env.Pipelines.Integration.Bindings...),
+					Bindings: append([]string{bindingName}, providerBindings(env.Pipelines.Integration.Bindings, svcRepo)...),
 				},
 			}
 		}
@@ -148,14 +241,98 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 		return nil, nil, err
 	}
 
+	if o.Image != "" {
+		configFiles, err := serviceConfigResources(m, o)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = res.Merge(configFiles, files)
+	}
+
 	files[filepath.Base(filepath.Join(o.PipelinesFolderPath, pipelinesFile))] = m // Don't call filepath.ToSlash
-	built, err := buildResources(appFs, m)
+	built, err := buildResources(appFs, m, o.PipelinesFolderPath)
 	if err != nil {
 		return nil, nil, err
 	}
 	return res.Merge(built, files), otherResources, nil
 }
 
+// serviceConfigResources creates the Deployment for the service, and the
+// Service and Route if a port is provided, in the base/config folder of the
+// service.
+func serviceConfigResources(m *config.Manifest, o *AddServiceOptions) (res.Resources, error) {
+	env := m.GetEnvironment(o.EnvName)
+	app := m.GetApplication(o.EnvName, o.AppName)
+	svcBase := filepath.ToSlash(filepath.Join(m.GetLayout().PathForService(app, env, o.ServiceName), "base", "config"))
+
+	opts := []deployment.PodSpecFunc{}
+	if o.Port != 0 {
+		opts = append(opts, deployment.ContainerPort(int32(o.Port)))
+		if o.ReadinessPath != "" {
+			opts = append(opts, deployment.ReadinessProbe(o.ReadinessPath, int32(o.Port)))
+		}
+	}
+	if len(o.Env) > 0 {
+		vars, err := deployment.ParseEnvVars(o.Env)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, deployment.Env(vars))
+	}
+	if o.CPU != "" || o.Memory != "" {
+		r, err := resourceRequirements(o.CPU, o.Memory)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, deployment.Resources(r))
+	}
+	d := deployment.Create(app.Name, env.GetNamespace(), o.ServiceName, o.Image, opts...)
+	if o.Replicas > 0 {
+		d = deployment.Replicas(d, int32(o.Replicas))
+	}
+
+	resources := res.Resources{}
+	filenames := []string{"100-deployment.yaml"}
+	resources[filepath.ToSlash(filepath.Join(svcBase, "100-deployment.yaml"))] = d
+	if o.Port != 0 {
+		containerSvc := createServiceForPort(app.Name, env.GetNamespace(), o.ServiceName, int32(o.Port))
+		resources[filepath.ToSlash(filepath.Join(svcBase, "200-service.yaml"))] = containerSvc
+		filenames = append(filenames, "200-service.yaml")
+		if o.Expose {
+			r, err := routes.NewFromService(containerSvc)
+			if err != nil {
+				return nil, err
+			}
+			resources[filepath.ToSlash(filepath.Join(svcBase, "300-route.yaml"))] = r
+			filenames = append(filenames, "300-route.yaml")
+		}
+	}
+	resources[filepath.ToSlash(filepath.Join(svcBase, Kustomize))] = &res.Kustomization{Resources: filenames}
+	return resources, nil
+}
+
+// resourceRequirements uses the cpu and memory as both the requests and the
+// limits.
+func resourceRequirements(cpu, memory string) (corev1.ResourceRequirements, error) {
+	list, err := policies.ResourceList(map[corev1.ResourceName]string{corev1.ResourceCPU: cpu, corev1.ResourceMemory: memory})
+	if err != nil {
+		return corev1.ResourceRequirements{}, err
+	}
+	return corev1.ResourceRequirements{Requests: list, Limits: list.DeepCopy()}, nil
+}
+
+// validate checks the values that configure the deployment of the service.
+func (o *AddServiceOptions) validate() error {
+	if o.Port < 0 || o.Port > 65535 {
+		return fmt.Errorf("invalid port %d", o.Port)
+	}
+	if o.Replicas < 0 {
+		return fmt.Errorf("invalid number of replicas %d", o.Replicas)
+	}
+	_, err := deployment.ParseEnvVars(o.Env)
+	return err
+}
+
 func createImageRepoResources(m *config.Manifest, cfg *config.PipelinesConfig, env *config.Environment, p *AddServiceOptions) ([]string, res.Resources, string, error) {
 	isInternalRegistry, imageRepo, err := imagerepo.ValidateImageRepo(p.ImageRepo)
 	if err != nil {
@@ -165,12 +342,12 @@ func createImageRepoResources(m *config.Manifest, cfg *config.PipelinesConfig, e
 	resources := res.Resources{}
 	filenames := []string{}
 
-	bindingName, bindingFilename, svcImageBinding := createSvcImageBinding(cfg, env, p.AppName, p.ServiceName, imageRepo, !isInternalRegistry)
+	bindingName, bindingFilename, svcImageBinding := createSvcImageBinding(m.GetLayout(), cfg, env, p.AppName, p.ServiceName, imageRepo, !isInternalRegistry)
 	resources = res.Merge(svcImageBinding, resources)
 	filenames = append(filenames, bindingFilename)
 
 	if isInternalRegistry {
-		files, regRes, err := imagerepo.CreateInternalRegistryResources(cfg,
+		files, regRes, err := imagerepo.CreateInternalRegistryResources(m.GetLayout(), cfg,
 			roles.CreateServiceAccount(meta.NamespacedName(cfg.Name, saName)),
 			imageRepo, m.GitOpsURL)
 		if err != nil {
@@ -195,15 +372,24 @@ func createService(serviceName, url string) *config.Service {
 	}
 }
 
-func updateKustomization(appFs afero.Fs, base string) error {
-	files := res.Resources{}
-	filenames, err := environments.ListFiles(appFs, base)
+// pipelinesKustomization returns the kustomization for the files in the base
+// folder of the pipelines, including the files that are being generated.
+func pipelinesKustomization(appFs afero.Fs, pipelinesFolderPath, base string, files res.Resources) (*res.Kustomization, error) {
+	path, err := homedir.Expand(pipelinesFolderPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	files[Kustomize] = &res.Kustomization{Resources: filenames.Items()}
-	_, err = yaml.WriteResources(appFs, base, files)
-	return err
+	filenames, err := environments.ListFiles(appFs, filepath.Join(path, base))
+	if err != nil {
+		return nil, err
+	}
+	for k := range files {
+		filename := filepath.ToSlash(k)
+		if strings.HasPrefix(filename, base+"/") && filename != base+"/"+Kustomize {
+			filenames[strings.TrimPrefix(filename, base+"/")] = true
+		}
+	}
+	return &res.Kustomization{Resources: filenames.Items()}, nil
 }
 
 func makeSvcImageBindingName(envName, appName, svcName string) string {
@@ -214,14 +400,14 @@ func makeSvcImageBindingFilename(bindingName string) string {
 	return filepath.ToSlash(filepath.Join("05-bindings", bindingName+".yaml"))
 }
 
-func makeImageBindingPath(cfg *config.PipelinesConfig, imageRepoBindingFilename string) string {
-	return filepath.ToSlash(filepath.Join(config.PathForPipelines(cfg), "base", imageRepoBindingFilename))
+func makeImageBindingPath(layout *config.Layout, cfg *config.PipelinesConfig, imageRepoBindingFilename string) string {
+	return filepath.ToSlash(filepath.Join(layout.PathForPipelines(cfg), "base", imageRepoBindingFilename))
 }
 
-func createSvcImageBinding(cfg *config.PipelinesConfig, env *config.Environment, appName, svcName, imageRepo string, isTLSVerify bool) (string, string, res.Resources) {
+func createSvcImageBinding(layout *config.Layout, cfg *config.PipelinesConfig, env *config.Environment, appName, svcName, imageRepo string, isTLSVerify bool) (string, string, res.Resources) {
 	name := makeSvcImageBindingName(env.Name, appName, svcName)
 	filename := makeSvcImageBindingFilename(name)
-	resourceFilePath := makeImageBindingPath(cfg, filename)
+	resourceFilePath := makeImageBindingPath(layout, cfg, filename)
 	return name, filename, res.Resources{resourceFilePath: triggers.CreateImageRepoBinding(cfg.Name, name, imageRepo, strconv.FormatBool(isTLSVerify))}
 }
 
@@ -232,7 +418,7 @@ func createConfigFolder(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions
 	}
 	env := m.GetEnvironment(o.EnvName)
 	app := m.GetApplication(o.EnvName, o.AppName)
-	servicePath := config.PathForService(app, env, o.ServiceName)
+	servicePath := m.GetLayout().PathForService(app, env, o.ServiceName)
 	finalPath := filepath.Join(basePath, servicePath, "base", "config")
 	err = appFs.MkdirAll(finalPath, 0755)
 	if err != nil {
@@ -0,0 +1,246 @@
+package pipelines
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/deployment"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+const deploymentPatchFile = "deployment-patch.yaml"
+
+// ConfigureServiceOptions control how a service is configured for an
+// environment.
+type ConfigureServiceOptions struct {
+	PipelinesFolderPath string
+	EnvName             string
+	ServiceName         string
+	Replicas            int
+	ImageTag            string
+	Env                 []string // Environment variables of the form KEY=VAL.
+	ConfigMapFiles      []string
+}
+
+// ConfigureService is the entry-point from the CLI for configuring a service
+// in an environment.
+//
+// The configuration is written to the overlay for the service, as a patch for
+// the Deployment of the service, an override of the image tag, and a
+// ConfigMap generated from the files.
+func ConfigureService(o *ConfigureServiceOptions, appFs afero.Fs) error {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	svcPath, err := servicePath(m, o.EnvName, o.ServiceName)
+	if err != nil {
+		return err
+	}
+	svcPath = filepath.Join(o.PipelinesFolderPath, svcPath)
+	overlaysPath := filepath.Join(svcPath, "overlays")
+	k, err := readKustomization(appFs, filepath.Join(overlaysPath, Kustomize))
+	if err != nil {
+		return err
+	}
+	if k == nil {
+		k = &res.Kustomization{Resources: []string{"../base"}}
+	}
+
+	files := res.Resources{}
+	if o.Replicas > 0 || len(o.Env) > 0 || o.ImageTag != "" {
+		d, err := findDeployment(appFs, filepath.Join(svcPath, "base", "config"), o.ServiceName)
+		if err != nil {
+			return err
+		}
+		if o.ImageTag != "" {
+			image, _, _ := unstructured.NestedString(d.containers[0], "image")
+			k.SetImage(res.Image{Name: imageName(image), NewTag: o.ImageTag})
+		}
+		if o.Replicas > 0 || len(o.Env) > 0 {
+			patch, err := deploymentPatch(appFs, filepath.Join(overlaysPath, deploymentPatchFile), d, o)
+			if err != nil {
+				return err
+			}
+			files[deploymentPatchFile] = patch
+			k.AddPatches(deploymentPatchFile)
+		}
+	}
+	for _, filename := range o.ConfigMapFiles {
+		data, err := afero.ReadFile(appFs, filename)
+		if err != nil {
+			return fmt.Errorf("failed to read config map file %s: %w", filename, err)
+		}
+		if err := afero.WriteFile(appFs, filepath.Join(overlaysPath, filepath.Base(filename)), data, 0644); err != nil {
+			return fmt.Errorf("failed to write config map file %s: %w", filename, err)
+		}
+		k.AddConfigMapFiles(fmt.Sprintf("%s-config", o.ServiceName), filepath.Base(filename))
+	}
+	files[Kustomize] = k
+	_, err = yaml.WriteResources(appFs, overlaysPath, files)
+	return err
+}
+
+func servicePath(m *config.Manifest, envName, serviceName string) (string, error) {
+	env := m.GetEnvironment(envName)
+	if env == nil {
+		return "", fmt.Errorf("environment %s does not exist", envName)
+	}
+	for _, app := range env.Apps {
+		for _, svc := range app.Services {
+			if svc.Name == serviceName {
+				return m.GetLayout().PathForService(app, env, svc.Name), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("service %s does not exist in environment %s", serviceName, envName)
+}
+
+// readKustomization reads the kustomization in the file, returning nil if the
+// file doesn't exist.
+func readKustomization(fs afero.Fs, filename string) (*res.Kustomization, error) {
+	data, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	k := &res.Kustomization{}
+	if err := sigsyaml.Unmarshal(data, k); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	return k, nil
+}
+
+type serviceDeployment struct {
+	name       string
+	containers []map[string]interface{}
+}
+
+// findDeployment finds the Deployment with the name of the service in the
+// config of the service.
+func findDeployment(fs afero.Fs, configPath, name string) (*serviceDeployment, error) {
+	filenames, err := afero.Glob(fs, filepath.Join(configPath, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		data, err := afero.ReadFile(fs, filename)
+		if err != nil {
+			return nil, err
+		}
+		obj := map[string]interface{}{}
+		if err := sigsyaml.Unmarshal(data, &obj); err != nil {
+			continue
+		}
+		u := unstructured.Unstructured{Object: obj}
+		if u.GetKind() != "Deployment" || u.GetName() != name {
+			continue
+		}
+		containers, _, _ := unstructured.NestedSlice(obj, "spec", "template", "spec", "containers")
+		d := &serviceDeployment{name: name}
+		for _, c := range containers {
+			if cm, ok := c.(map[string]interface{}); ok {
+				d.containers = append(d.containers, cm)
+			}
+		}
+		if len(d.containers) == 0 {
+			return nil, fmt.Errorf("the Deployment %s in %s has no containers", name, filename)
+		}
+		return d, nil
+	}
+	return nil, fmt.Errorf("failed to find the Deployment %s in %s", name, configPath)
+}
+
+// deploymentPatch returns the strategic merge patch for the Deployment, with
+// the changes applied to the existing patch, if there is one.
+func deploymentPatch(fs afero.Fs, filename string, d *serviceDeployment, o *ConfigureServiceOptions) (map[string]interface{}, error) {
+	patch := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": d.name},
+	}
+	data, err := afero.ReadFile(fs, filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := sigsyaml.Unmarshal(data, &patch); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+	}
+	if o.Replicas > 0 {
+		if err := unstructured.SetNestedField(patch, int64(o.Replicas), "spec", "replicas"); err != nil {
+			return nil, err
+		}
+	}
+	if len(o.Env) > 0 {
+		if err := patchEnv(patch, d, o.Env); err != nil {
+			return nil, err
+		}
+	}
+	return patch, nil
+}
+
+func patchEnv(patch map[string]interface{}, d *serviceDeployment, env []string) error {
+	containerName, _, _ := unstructured.NestedString(d.containers[0], "name")
+	containers, _, err := unstructured.NestedSlice(patch, "spec", "template", "spec", "containers")
+	if err != nil {
+		return err
+	}
+	var container map[string]interface{}
+	for _, c := range containers {
+		if cm, ok := c.(map[string]interface{}); ok && cm["name"] == containerName {
+			container = cm
+		}
+	}
+	if container == nil {
+		container = map[string]interface{}{"name": containerName}
+		containers = append(containers, container)
+	}
+	vars, _, err := unstructured.NestedSlice(container, "env")
+	if err != nil {
+		return err
+	}
+	envVars, err := deployment.ParseEnvVars(env)
+	if err != nil {
+		return err
+	}
+	for _, v := range envVars {
+		vars = setEnvVar(vars, v.Name, v.Value)
+	}
+	container["env"] = vars
+	return unstructured.SetNestedSlice(patch, containers, "spec", "template", "spec", "containers")
+}
+
+func setEnvVar(vars []interface{}, name, value string) []interface{} {
+	for _, v := range vars {
+		if vm, ok := v.(map[string]interface{}); ok && vm["name"] == name {
+			vm["value"] = value
+			return vars
+		}
+	}
+	return append(vars, map[string]interface{}{"name": name, "value": value})
+}
+
+// imageName returns the image without the tag or digest.
+func imageName(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
@@ -0,0 +1,156 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestConfigureService(t *testing.T) {
+	fakeFs, gitopsPath := setupServiceForConfigure(t)
+	assertNoError(t, afero.WriteFile(fakeFs, "/config/app.properties", []byte("greeting=hello\n"), 0644))
+
+	err := ConfigureService(&ConfigureServiceOptions{
+		PipelinesFolderPath: gitopsPath,
+		EnvName:             "test-dev",
+		ServiceName:         "web",
+		Replicas:            3,
+		ImageTag:            "v2",
+		Env:                 []string{"FOO=bar"},
+		ConfigMapFiles:      []string{"/config/app.properties"},
+	}, fakeFs)
+	assertNoError(t, err)
+
+	overlaysPath := filepath.Join(gitopsPath, "environments/test-dev/apps/test-app/services/web/overlays")
+	var k res.Kustomization
+	mustReadYAML(t, fakeFs, filepath.Join(overlaysPath, Kustomize), &k)
+	wantKustomization := res.Kustomization{
+		Resources:             []string{"../base"},
+		Images:                []res.Image{{Name: "quay.io/example/web", NewTag: "v2"}},
+		PatchesStrategicMerge: []string{"deployment-patch.yaml"},
+		ConfigMapGenerator:    []res.ConfigMapGenerator{{Name: "web-config", Files: []string{"app.properties"}}},
+	}
+	if diff := cmp.Diff(wantKustomization, k); diff != "" {
+		t.Fatalf("overlay kustomization didn't match:\n%s", diff)
+	}
+
+	wantPatch := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web"},
+		"spec": map[string]interface{}{
+			"replicas": 3.0,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name": "web",
+							"env":  []interface{}{map[string]interface{}{"name": "FOO", "value": "bar"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(wantPatch, mustReadFileAsMap(t, fakeFs, filepath.Join(overlaysPath, "deployment-patch.yaml"))); diff != "" {
+		t.Fatalf("deployment patch didn't match:\n%s", diff)
+	}
+	data, err := afero.ReadFile(fakeFs, filepath.Join(overlaysPath, "app.properties"))
+	assertNoError(t, err)
+	if diff := cmp.Diff("greeting=hello\n", string(data)); diff != "" {
+		t.Fatalf("config map file didn't match:\n%s", diff)
+	}
+}
+
+func TestConfigureServiceUpdatesExistingPatch(t *testing.T) {
+	fakeFs, gitopsPath := setupServiceForConfigure(t)
+	configure := func(o *ConfigureServiceOptions) {
+		t.Helper()
+		o.PipelinesFolderPath = gitopsPath
+		o.EnvName = "test-dev"
+		o.ServiceName = "web"
+		assertNoError(t, ConfigureService(o, fakeFs))
+	}
+
+	configure(&ConfigureServiceOptions{Replicas: 2, Env: []string{"FOO=bar", "LOG_LEVEL=info"}})
+	configure(&ConfigureServiceOptions{Env: []string{"LOG_LEVEL=debug"}})
+
+	patch := mustReadFileAsMap(t, fakeFs, filepath.Join(gitopsPath, "environments/test-dev/apps/test-app/services/web/overlays/deployment-patch.yaml"))
+	spec := patch["spec"].(map[string]interface{})
+	if r := spec["replicas"]; r != 2.0 {
+		t.Fatalf("replicas got %v, want 2", r)
+	}
+	container := spec["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})[0]
+	wantEnv := []interface{}{
+		map[string]interface{}{"name": "FOO", "value": "bar"},
+		map[string]interface{}{"name": "LOG_LEVEL", "value": "debug"},
+	}
+	if diff := cmp.Diff(wantEnv, container.(map[string]interface{})["env"]); diff != "" {
+		t.Fatalf("env didn't match:\n%s", diff)
+	}
+}
+
+func TestConfigureServiceWithUnknownService(t *testing.T) {
+	fakeFs, gitopsPath := setupServiceForConfigure(t)
+
+	err := ConfigureService(&ConfigureServiceOptions{
+		PipelinesFolderPath: gitopsPath,
+		EnvName:             "test-dev",
+		ServiceName:         "api",
+		Replicas:            2,
+	}, fakeFs)
+	test.AssertErrorMatch(t, "service api does not exist in environment test-dev", err)
+}
+
+func TestImageName(t *testing.T) {
+	nameTests := []struct {
+		image string
+		want  string
+	}{
+		{"quay.io/example/web", "quay.io/example/web"},
+		{"quay.io/example/web:v1", "quay.io/example/web"},
+		{"registry.local:5000/example/web", "registry.local:5000/example/web"},
+		{"registry.local:5000/example/web:v1", "registry.local:5000/example/web"},
+		{"quay.io/example/web@sha256:abc", "quay.io/example/web"},
+	}
+
+	for _, tt := range nameTests {
+		if got := imageName(tt.image); got != tt.want {
+			t.Errorf("imageName(%q) got %q, want %q", tt.image, got, tt.want)
+		}
+	}
+}
+
+func setupServiceForConfigure(t *testing.T) (afero.Fs, string) {
+	t.Helper()
+	fakeFs := ioutils.NewMemoryFilesystem()
+	gitopsPath := afero.GetTempDir(fakeFs, "test")
+	b, err := yaml.Marshal(buildManifest(false, false))
+	assertNoError(t, err)
+	assertNoError(t, afero.WriteFile(fakeFs, filepath.Join(gitopsPath, pipelinesFile), b, 0644))
+
+	err = AddService(&AddServiceOptions{
+		AppName:             "test-app",
+		EnvName:             "test-dev",
+		PipelinesFolderPath: gitopsPath,
+		ServiceName:         "web",
+		Image:               "quay.io/example/web:latest",
+		Port:                8080,
+	}, fakeFs)
+	assertNoError(t, err)
+	return fakeFs, gitopsPath
+}
+
+func mustReadYAML(t *testing.T, fs afero.Fs, filename string, v interface{}) {
+	t.Helper()
+	b, err := afero.ReadFile(fs, filename)
+	assertNoError(t, err)
+	assertNoError(t, yaml.Unmarshal(b, v))
+}
@@ -9,14 +9,18 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/deployment"
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/routes"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	"github.com/redhat-developer/kam/test"
 	"github.com/spf13/afero"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 )
@@ -36,13 +40,13 @@ func TestServiceResourcesWithCICD(t *testing.T) {
 	}
 	want := res.Resources{
 		"environments/test-dev/apps/test-app/base/kustomization.yaml": &res.Kustomization{
-			Bases: []string{"../services/test-svc", "../services/test"}},
+			Resources: []string{"../services/test", "../services/test-svc"}},
 		"environments/test-dev/apps/test-app/kustomization.yaml": &res.Kustomization{
-			Bases:        []string{"overlays"},
+			Resources:    []string{"overlays"},
 			CommonLabels: map[string]string{"app.openshift.io/vcs-source": "org/test"},
 		},
 		"environments/test-dev/apps/test-app/overlays/kustomization.yaml": &res.Kustomization{
-			Bases: []string{"../base"}},
+			Resources: []string{"../base"}},
 		"pipelines.yaml": &config.Manifest{
 			Config: &config.Config{
 				Pipelines: &config.PipelinesConfig{
@@ -114,23 +118,88 @@ func TestServiceResourcesWithCICD(t *testing.T) {
 	}
 }
 
+func TestServiceResourcesWithOtherProvider(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := buildManifest(true, false)
+
+	_, _, err := serviceResources(m, fakeFs, &AddServiceOptions{
+		AppName:             "test-app",
+		EnvName:             "test-dev",
+		GitRepoURL:          "https://gitlab.com/org/test.git",
+		PipelinesFolderPath: pipelinesFile,
+		WebhookSecret:       "123",
+		ServiceName:         "test",
+	})
+	assertNoError(t, err)
+
+	want := []string{"test-dev-test-app-test-binding", "gitlab-push-binding"}
+	services := m.GetApplication("test-dev", "test-app").Services
+	svc := services[len(services)-1]
+	if diff := cmp.Diff(want, svc.Pipelines.Integration.Bindings); diff != "" {
+		t.Fatalf("service bindings:\n%s", diff)
+	}
+}
+
+func TestAddServiceUpdatesSSHAuthSecret(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	assertNoError(t, afero.WriteFile(fakeFs, "/home/user/.ssh/id_rsa", []byte("test-private-key"), 0600))
+	assertNoError(t, afero.WriteFile(fakeFs, "/home/user/.ssh/known_hosts", []byte("github.com ssh-ed25519 AAAA"), 0600))
+	params := &BootstrapOptions{
+		Prefix:                "tst-",
+		GitOpsRepoURL:         "git@github.com:my-org/gitops.git",
+		ImageRepo:             "image/repo",
+		GitOpsWebhookSecret:   "123",
+		ServiceRepoURL:        "git@github.com:my-org/http-api.git",
+		ServiceWebhookSecret:  "456",
+		SSHPrivateKeyFilename: "/home/user/.ssh/id_rsa",
+		SSHKnownHostsFilename: "/home/user/.ssh/known_hosts",
+		OutputPath:            "/workspace/gitops",
+	}
+	out, err := GenerateBootstrap(params, fakeFs)
+	assertNoError(t, err)
+	assertNoError(t, WriteOutput(fakeFs, params.OutputPath, out))
+
+	err = AddService(&AddServiceOptions{
+		AppName:             "app-web",
+		EnvName:             "tst-dev",
+		GitRepoURL:          "ssh://git@gitlab.com/my-org/web.git",
+		ImageRepo:           "quay.io/my-org/web",
+		PipelinesFolderPath: params.OutputPath,
+		ServiceName:         "web",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	secret := &corev1.Secret{}
+	mustReadYAML(t, fakeFs, "/workspace/secrets/"+sshAuthSecretName+".yaml", secret)
+	want := map[string]string{
+		"tekton.dev/git-0": "github.com",
+		"tekton.dev/git-1": "gitlab.com",
+	}
+	if diff := cmp.Diff(want, secret.Annotations); diff != "" {
+		t.Fatalf("SSH auth secret annotations:\n%s", diff)
+	}
+	if diff := cmp.Diff("test-private-key", string(secret.Data["ssh-privatekey"])); diff != "" {
+		t.Fatalf("SSH auth secret private key:\n%s", diff)
+	}
+}
+
 func TestServiceResourcesWithArgoCD(t *testing.T) {
 	fakeFs := ioutils.NewMemoryFilesystem()
 	m := buildManifest(false, true)
 
 	want := res.Resources{
 		"environments/test-dev/apps/test-app/base/kustomization.yaml": &res.Kustomization{
-			Bases: []string{
-				"../services/test-svc",
+			Resources: []string{
 				"../services/test",
+				"../services/test-svc",
 			},
 		},
 		"environments/test-dev/apps/test-app/kustomization.yaml": &res.Kustomization{
-			Bases:        []string{"overlays"},
+			Resources:    []string{"overlays"},
 			CommonLabels: map[string]string{"app.openshift.io/vcs-source": "org/test"},
 		},
 		"environments/test-dev/apps/test-app/overlays/kustomization.yaml": &res.Kustomization{
-			Bases: []string{"../base"},
+			Resources: []string{"../base"},
 		},
 		"pipelines.yaml": &config.Manifest{
 			Config: &config.Config{
@@ -188,16 +257,15 @@ func TestServiceResourcesWithoutArgoCD(t *testing.T) {
 	want := res.Resources{
 		"environments/test-dev/apps/test-app/base/kustomization.yaml": &res.Kustomization{
 
-			Bases: []string{"../services/test-svc", "../services/test"}},
+			Resources: []string{"../services/test", "../services/test-svc"}},
 		"environments/test-dev/apps/test-app/kustomization.yaml": &res.Kustomization{
-			Bases:        []string{"overlays"},
+			Resources:    []string{"overlays"},
 			CommonLabels: map[string]string{"app.openshift.io/vcs-source": "org/test"},
 		},
 		"environments/test-dev/apps/test-app/overlays/kustomization.yaml": &res.Kustomization{
-			Bases: []string{"../base"}},
+			Resources: []string{"../base"}},
 		"environments/test-dev/env/base/kustomization.yaml": &res.Kustomization{
-			Resources: []string{"test-dev-environment.yaml"},
-			Bases:     []string{"../../apps/test-app/overlays"},
+			Resources: []string{"test-dev-environment.yaml", "../../apps/test-app/overlays"},
 		},
 		"pipelines.yaml": &config.Manifest{
 			GitOpsURL: "http://github.com/org/test",
@@ -244,19 +312,93 @@ func TestServiceResourcesWithoutArgoCD(t *testing.T) {
 	}
 }
 
+func TestServiceResourcesWithDeployment(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := buildManifest(false, false)
+
+	got, _, err := serviceResources(m, fakeFs, &AddServiceOptions{
+		AppName:             "test-app",
+		EnvName:             "test-dev",
+		PipelinesFolderPath: pipelinesFile,
+		ServiceName:         "test",
+		Image:               "quay.io/example/test:latest",
+		Port:                9090,
+		Replicas:            2,
+		Env:                 []string{"LOG_LEVEL=debug"},
+		ReadinessPath:       "/healthz",
+		Expose:              true,
+	})
+	assertNoError(t, err)
+
+	svc := createServiceForPort("test-app", "test-dev", "test", 9090)
+	route, err := routes.NewFromService(svc)
+	assertNoError(t, err)
+	want := res.Resources{
+		"environments/test-dev/apps/test-app/services/test/base/config/100-deployment.yaml": deployment.Replicas(deployment.Create(
+			"test-app", "test-dev", "test", "quay.io/example/test:latest",
+			deployment.ContainerPort(9090),
+			deployment.ReadinessProbe("/healthz", 9090),
+			deployment.Env([]corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}})), 2),
+		"environments/test-dev/apps/test-app/services/test/base/config/200-service.yaml": svc,
+		"environments/test-dev/apps/test-app/services/test/base/config/300-route.yaml":   route,
+		"environments/test-dev/apps/test-app/services/test/base/config/kustomization.yaml": &res.Kustomization{
+			Resources: []string{"100-deployment.yaml", "200-service.yaml", "300-route.yaml"}},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreMapEntries(func(k string, v interface{}) bool {
+		_, ok := want[k]
+		return !ok
+	})); diff != "" {
+		t.Fatalf("serviceResources() failed: %v", diff)
+	}
+}
+
+func TestServiceResourcesWithInvalidResources(t *testing.T) {
+	m := buildManifest(false, false)
+
+	_, _, err := serviceResources(m, ioutils.NewMemoryFilesystem(), &AddServiceOptions{
+		AppName:             "test-app",
+		EnvName:             "test-dev",
+		PipelinesFolderPath: pipelinesFile,
+		ServiceName:         "test",
+		Image:               "quay.io/example/test:latest",
+		CPU:                 "lots",
+	})
+	test.AssertErrorMatch(t, `failed to parse cpu quantity "lots"`, err)
+}
+
+func TestGenerateServiceWithInvalidDeployment(t *testing.T) {
+	invalidTests := []struct {
+		name    string
+		options *AddServiceOptions
+		wantErr string
+	}{
+		{"invalid port", &AddServiceOptions{Image: "quay.io/example/test:latest", Port: 70000}, "invalid port 70000"},
+		{"negative port", &AddServiceOptions{Image: "quay.io/example/test:latest", Port: -1}, "invalid port -1"},
+		{"invalid replicas", &AddServiceOptions{Image: "quay.io/example/test:latest", Replicas: -1}, "invalid number of replicas -1"},
+		{"invalid env", &AddServiceOptions{Image: "quay.io/example/test:latest", Env: []string{"A"}}, `invalid environment variable "A"`},
+	}
+
+	for _, tt := range invalidTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			_, err := GenerateService(tt.options, ioutils.NewMemoryFilesystem())
+			test.AssertErrorMatch(rt, tt.wantErr, err)
+		})
+	}
+}
+
 func TestAddServiceWithoutApp(t *testing.T) {
 	fakeFs := ioutils.NewMemoryFilesystem()
 	m := buildManifest(false, false)
 	want := res.Resources{
-		"environments/test-dev/apps/new-app/base/kustomization.yaml":     &res.Kustomization{Bases: []string{"../services/test"}},
-		"environments/test-dev/apps/new-app/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/new-app/base/kustomization.yaml":     &res.Kustomization{Resources: []string{"../services/test"}},
+		"environments/test-dev/apps/new-app/overlays/kustomization.yaml": &res.Kustomization{Resources: []string{"../base"}},
 		"environments/test-dev/apps/new-app/kustomization.yaml": &res.Kustomization{
-			Bases:        []string{"overlays"},
+			Resources:    []string{"overlays"},
 			CommonLabels: map[string]string{"app.openshift.io/vcs-source": "org/test"},
 		},
-		"environments/test-dev/apps/new-app/services/test/base/kustomization.yaml":          &res.Kustomization{Bases: []string{"./config"}},
-		"environments/test-dev/apps/new-app/services/test/kustomization.yaml":               &res.Kustomization{Bases: []string{"overlays"}},
-		"environments/test-dev/apps/new-app/services/test/overlays/kustomization.yaml":      &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/new-app/services/test/base/kustomization.yaml":          &res.Kustomization{Resources: []string{"./config"}},
+		"environments/test-dev/apps/new-app/services/test/kustomization.yaml":               &res.Kustomization{Resources: []string{"overlays"}},
+		"environments/test-dev/apps/new-app/services/test/overlays/kustomization.yaml":      &res.Kustomization{Resources: []string{"../base"}},
 		"environments/cicd/base/pipelines/03-secrets/webhook-secret-test-dev-test-svc.yaml": nil,
 		"pipelines.yaml": &config.Manifest{
 			GitOpsURL: "http://github.com/org/test",
@@ -542,15 +684,15 @@ func TestAddServiceWithoutGitRepoUrl(t *testing.T) {
 	fakeFs := ioutils.NewMemoryFilesystem()
 	m := buildManifest(false, false)
 	want := res.Resources{
-		"environments/test-dev/apps/new-app/base/kustomization.yaml":     &res.Kustomization{Bases: []string{"../services/test"}},
-		"environments/test-dev/apps/new-app/overlays/kustomization.yaml": &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/new-app/base/kustomization.yaml":     &res.Kustomization{Resources: []string{"../services/test"}},
+		"environments/test-dev/apps/new-app/overlays/kustomization.yaml": &res.Kustomization{Resources: []string{"../base"}},
 		"environments/test-dev/apps/new-app/kustomization.yaml": &res.Kustomization{
-			Bases:        []string{"overlays"},
+			Resources:    []string{"overlays"},
 			CommonLabels: map[string]string{"app.openshift.io/vcs-source": "org/test"},
 		},
-		"environments/test-dev/apps/new-app/services/test/base/kustomization.yaml":          &res.Kustomization{Bases: []string{"./config"}},
-		"environments/test-dev/apps/new-app/services/test/kustomization.yaml":               &res.Kustomization{Bases: []string{"overlays"}},
-		"environments/test-dev/apps/new-app/services/test/overlays/kustomization.yaml":      &res.Kustomization{Bases: []string{"../base"}},
+		"environments/test-dev/apps/new-app/services/test/base/kustomization.yaml":          &res.Kustomization{Resources: []string{"./config"}},
+		"environments/test-dev/apps/new-app/services/test/kustomization.yaml":               &res.Kustomization{Resources: []string{"overlays"}},
+		"environments/test-dev/apps/new-app/services/test/overlays/kustomization.yaml":      &res.Kustomization{Resources: []string{"../base"}},
 		"environments/cicd/base/pipelines/03-secrets/webhook-secret-test-dev-test-svc.yaml": nil,
 		"pipelines.yaml": &config.Manifest{
 			GitOpsURL: "http://github.com/org/test",
@@ -658,7 +800,7 @@ func TestCreateSvcImageBinding(t *testing.T) {
 	env := &config.Environment{
 		Name: "new-env",
 	}
-	bindingName, bindingFilename, resources := createSvcImageBinding(cfg, env, "newapp", "new-svc", "quay.io/user/app", false)
+	bindingName, bindingFilename, resources := createSvcImageBinding(nil, cfg, env, "newapp", "new-svc", "quay.io/user/app", false)
 	if diff := cmp.Diff(bindingName, "new-env-newapp-new-svc-binding"); diff != "" {
 		t.Errorf("bindingName failed: %v", diff)
 	}
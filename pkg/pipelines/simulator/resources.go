@@ -0,0 +1,90 @@
+package simulator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// resources are the Triggers resources generated for the CI/CD environment.
+//
+// The generated files use the v1alpha1 API, which has the same fields as the
+// v1beta1 API that the Triggers interceptors and templates use.
+type resources struct {
+	eventListener *triggersv1.EventListener
+	bindings      map[string]*triggersv1.TriggerBinding
+	templates     map[string]*triggersv1.TriggerTemplate
+}
+
+func loadResources(fs afero.Fs, basePath string) (*resources, error) {
+	r := &resources{
+		bindings:  map[string]*triggersv1.TriggerBinding{},
+		templates: map[string]*triggersv1.TriggerTemplate{},
+	}
+	err := afero.Walk(fs, basePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".yaml" {
+			return nil
+		}
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+		return r.add(path, data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the Triggers resources from %s: %w", basePath, err)
+	}
+	return r, nil
+}
+
+func (r *resources) add(path string, data []byte) error {
+	var meta metav1.TypeMeta
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	switch meta.Kind {
+	case "EventListener":
+		el := &triggersv1.EventListener{}
+		if err := yaml.Unmarshal(data, el); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		r.eventListener = el
+	case "TriggerBinding":
+		tb := &triggersv1.TriggerBinding{}
+		if err := yaml.Unmarshal(data, tb); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		r.bindings[tb.Name] = tb
+	case "TriggerTemplate":
+		tt := &triggersv1.TriggerTemplate{}
+		if err := yaml.Unmarshal(data, tt); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		r.templates[tt.Name] = tt
+	}
+	return nil
+}
+
+func (r *resources) getTriggerBinding(name string) (*triggersv1.TriggerBinding, error) {
+	tb, ok := r.bindings[name]
+	if !ok {
+		return nil, fmt.Errorf("failed to find TriggerBinding %s", name)
+	}
+	return tb, nil
+}
+
+func (r *resources) getTriggerTemplate(name string) (*triggersv1.TriggerTemplate, error) {
+	tt, ok := r.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("failed to find TriggerTemplate %s", name)
+	}
+	return tt, nil
+}
@@ -0,0 +1,176 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/afero"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors/cel"
+	"github.com/tektoncd/triggers/pkg/template"
+	"go.uber.org/zap"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+)
+
+const celInterceptor = "cel"
+
+// Options configures the event that is simulated.
+type Options struct {
+	PipelinesFolderPath string
+	Payload             []byte
+	Headers             http.Header
+}
+
+// TriggerResult is the outcome of processing the event with a trigger from
+// the EventListener.
+type TriggerResult struct {
+	Name    string `json:"name"`
+	Matched bool   `json:"matched"`
+	// Reason explains why the trigger did not match the event.
+	Reason string `json:"reason,omitempty"`
+	// Skipped are the interceptors that can't be evaluated offline, e.g. those
+	// that validate the webhook signature.
+	Skipped    []string               `json:"skipped,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+	Params     []triggersv1.Param     `json:"params,omitempty"`
+	// Resources are the resources from the TriggerTemplate, with the params
+	// applied, in YAML format.
+	Resources []string `json:"resources,omitempty"`
+}
+
+// Simulate processes the event with each of the triggers in the generated
+// EventListener, as the EventListener would in the cluster, and renders the
+// resources for the triggers that match.
+func Simulate(fs afero.Fs, o *Options) ([]TriggerResult, error) {
+	manifest, err := config.LoadManifest(fs, o.PipelinesFolderPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pipelines: %v", err)
+	}
+	cfg := manifest.GetPipelinesConfig()
+	if cfg == nil {
+		return nil, fmt.Errorf("failed to find a CI/CD environment in the manifest")
+	}
+	res, err := loadResources(fs, filepath.Join(o.PipelinesFolderPath, manifest.GetLayout().PathForPipelines(cfg), "base"))
+	if err != nil {
+		return nil, err
+	}
+	if res.eventListener == nil {
+		return nil, fmt.Errorf("failed to find an EventListener in the CI/CD environment %s", cfg.Name)
+	}
+
+	s := &simulator{
+		namespace:   cfg.Name,
+		resources:   res,
+		interceptor: cel.NewInterceptor(emptySecretLister(), zap.NewNop().Sugar()),
+	}
+	results := []TriggerResult{}
+	for i := range res.eventListener.Spec.Triggers {
+		results = append(results, s.processTrigger(&res.eventListener.Spec.Triggers[i], o.Payload, o.Headers))
+	}
+	return results, nil
+}
+
+type simulator struct {
+	namespace   string
+	resources   *resources
+	interceptor *cel.Interceptor
+}
+
+func (s *simulator) processTrigger(t *triggersv1.EventListenerTrigger, payload []byte, headers http.Header) TriggerResult {
+	result := TriggerResult{Name: t.Name}
+	// Triggers that refer to a Trigger resource, rather than having an inline
+	// template, can't be resolved from the EventListener.
+	if t.Template == nil {
+		result.Reason = "the trigger has no template, only triggers with an inline template can be simulated"
+		return result
+	}
+	extensions := map[string]interface{}{}
+	for _, i := range t.Interceptors {
+		if i.Ref.Name != celInterceptor {
+			result.Skipped = append(result.Skipped, i.Ref.Name)
+			continue
+		}
+		params, err := interceptorParams(i.Params)
+		if err != nil {
+			result.Reason = err.Error()
+			return result
+		}
+		resp := s.interceptor.Process(context.Background(), &triggersv1.InterceptorRequest{
+			Body:              string(payload),
+			Header:            headers,
+			Extensions:        extensions,
+			InterceptorParams: params,
+			Context: &triggersv1.TriggerContext{
+				TriggerID: fmt.Sprintf("namespaces/%s/triggers/%s", s.namespace, t.Name),
+			},
+		})
+		if !resp.Continue {
+			result.Reason = resp.Status.Message
+			return result
+		}
+		for k, v := range resp.Extensions {
+			extensions[k] = v
+		}
+	}
+	result.Matched = true
+	result.Extensions = extensions
+
+	rt, err := template.ResolveTrigger(triggersv1.Trigger{
+		Spec: triggersv1.TriggerSpec{
+			Bindings: t.Bindings,
+			Template: *t.Template,
+		},
+	}, s.resources.getTriggerBinding, getClusterTriggerBinding, s.resources.getTriggerTemplate)
+	if err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+	params, err := template.ResolveParams(rt, payload, headers, extensions)
+	if err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	result.Params = params
+	for _, r := range template.ResolveResources(rt.TriggerTemplate, params) {
+		b, err := yaml.JSONToYAML(r)
+		if err != nil {
+			result.Reason = fmt.Sprintf("failed to render resource: %v", err)
+			return result
+		}
+		result.Resources = append(result.Resources, string(b))
+	}
+	return result
+}
+
+// interceptorParams converts the params to the form that interceptors receive
+// them in from the EventListener.
+func interceptorParams(params []triggersv1.InterceptorParams) (map[string]interface{}, error) {
+	converted := map[string]interface{}{}
+	for _, p := range params {
+		var v interface{}
+		if err := json.Unmarshal(p.Value.Raw, &v); err != nil {
+			return nil, fmt.Errorf("failed to parse interceptor param %s: %v", p.Name, err)
+		}
+		converted[p.Name] = v
+	}
+	return converted, nil
+}
+
+func getClusterTriggerBinding(name string) (*triggersv1.ClusterTriggerBinding, error) {
+	return nil, fmt.Errorf("ClusterTriggerBinding %s is not supported", name)
+}
+
+// emptySecretLister is used because the secrets referenced by the
+// interceptors are not available offline.
+func emptySecretLister() corev1lister.SecretLister {
+	return corev1lister.NewSecretLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}))
+}
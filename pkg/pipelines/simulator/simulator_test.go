@@ -0,0 +1,201 @@
+package simulator
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+const (
+	testGitOpsURL = "https://github.com/org/gitops.git"
+	testCommitID  = "6113728f27ae82c7b1a177c8d03f9e96e0adf246"
+)
+
+func TestSimulateWithMatchingEvent(t *testing.T) {
+	fs := makeTestResources(t)
+	headers, payload := makePushEvent(t, testGitOpsURL)
+
+	results, err := Simulate(fs, &Options{PipelinesFolderPath: "/gitops", Payload: payload, Headers: headers})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l := len(results); l != 1 {
+		t.Fatalf("Simulate() got %d results, want 1", l)
+	}
+	got := results[0]
+	if !got.Matched {
+		t.Fatalf("Simulate() trigger did not match: %s", got.Reason)
+	}
+	if diff := cmp.Diff([]string{"github"}, got.Skipped); diff != "" {
+		t.Errorf("Simulate() skipped interceptors:\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]interface{}{"ref": "main"}, got.Extensions); diff != "" {
+		t.Errorf("Simulate() extensions:\n%s", diff)
+	}
+	wantParams := []triggersv1.Param{
+		{Name: "fullname", Value: "org/gitops"},
+		{Name: "gitrepositoryhttpsurl", Value: testGitOpsURL},
+		{Name: "gitrepositoryurl", Value: testGitOpsURL},
+		{Name: triggers.GitCommitAuthor, Value: "kam"},
+		{Name: triggers.GitCommitDate, Value: "0001-01-01T00:00:00Z"},
+		{Name: triggers.GitCommitID, Value: testCommitID},
+		{Name: triggers.GitCommitMessage, Value: "Test commit"},
+		{Name: triggers.GitRef, Value: "main"},
+	}
+	if diff := cmp.Diff(wantParams, got.Params); diff != "" {
+		t.Errorf("Simulate() params:\n%s", diff)
+	}
+	if l := len(got.Resources); l != 1 {
+		t.Fatalf("Simulate() got %d resources, want 1", l)
+	}
+	for _, want := range []string{"kind: PipelineRun", "value: " + testCommitID, "value: " + testGitOpsURL} {
+		if !strings.Contains(got.Resources[0], want) {
+			t.Errorf("Simulate() resource does not contain %q:\n%s", want, got.Resources[0])
+		}
+	}
+}
+
+func TestSimulateWithEventForAnotherRepository(t *testing.T) {
+	fs := makeTestResources(t)
+	headers, payload := makePushEvent(t, "https://github.com/org/other.git")
+
+	results, err := Simulate(fs, &Options{PipelinesFolderPath: "/gitops", Payload: payload, Headers: headers})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := results[0]
+	if got.Matched {
+		t.Fatal("Simulate() trigger matched an event for another repository")
+	}
+	if !strings.Contains(got.Reason, "did not return true") {
+		t.Fatalf("Simulate() got reason %q", got.Reason)
+	}
+	if got.Resources != nil {
+		t.Fatalf("Simulate() rendered resources for a trigger that didn't match")
+	}
+}
+
+func TestSimulateWithTriggerRef(t *testing.T) {
+	fs := makeTestResources(t)
+	elPath := "/gitops/config/tst-cicd/base/07-eventlisteners/cicd-event-listener.yaml"
+	repo, err := scm.NewRepository(testGitOpsURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	el, err := eventlisteners.Generate(repo, "tst-cicd", "pipeline", "gitops-webhook-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	el.Spec.Triggers = append(el.Spec.Triggers, triggersv1alpha1.EventListenerTrigger{Name: "referenced", TriggerRef: "other-trigger"})
+	if err := yaml.MarshalItemToFile(fs, elPath, el); err != nil {
+		t.Fatal(err)
+	}
+	headers, payload := makePushEvent(t, testGitOpsURL)
+
+	results, err := Simulate(fs, &Options{PipelinesFolderPath: "/gitops", Payload: payload, Headers: headers})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l := len(results); l != 2 {
+		t.Fatalf("Simulate() got %d results, want 2", l)
+	}
+	if !results[0].Matched {
+		t.Fatalf("Simulate() trigger did not match: %s", results[0].Reason)
+	}
+	got := results[1]
+	if got.Matched {
+		t.Fatal("Simulate() matched a trigger without a template")
+	}
+	want := "the trigger has no template, only triggers with an inline template can be simulated"
+	if got.Reason != want {
+		t.Fatalf("Simulate() got reason %q, want %q", got.Reason, want)
+	}
+}
+
+func TestSimulateWithMissingEventListener(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	writeManifest(t, fs)
+	if err := fs.MkdirAll("/gitops/config/tst-cicd/base", 0755); err != nil {
+		t.Fatal(err)
+	}
+	headers, payload := makePushEvent(t, testGitOpsURL)
+
+	_, err := Simulate(fs, &Options{PipelinesFolderPath: "/gitops", Payload: payload, Headers: headers})
+
+	want := "failed to find an EventListener in the CI/CD environment tst-cicd"
+	if err == nil || err.Error() != want {
+		t.Fatalf("Simulate() got error %v, want %s", err, want)
+	}
+}
+
+func makeTestResources(t *testing.T) afero.Fs {
+	t.Helper()
+	fs := ioutils.NewMemoryFilesystem()
+	writeManifest(t, fs)
+	repo, err := scm.NewRepository(testGitOpsURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	el, err := eventlisteners.Generate(repo, "tst-cicd", "pipeline", "gitops-webhook-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	binding, _ := repo.CreatePushBinding("tst-cicd")
+	basePath := filepath.Join("/gitops", "config", "tst-cicd", "base")
+	_, err = yaml.WriteResources(fs, basePath, map[string]interface{}{
+		"05-bindings/github-push-binding.yaml":           binding,
+		"06-templates/ci-dryrun-from-push-template.yaml": triggers.CreateCIDryRunTemplate("tst-cicd", "pipeline"),
+		"07-eventlisteners/cicd-event-listener.yaml":     el,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fs
+}
+
+func writeManifest(t *testing.T, fs afero.Fs) {
+	t.Helper()
+	m := &config.Manifest{
+		GitOpsURL: testGitOpsURL,
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "tst-cicd"},
+		},
+	}
+	if err := yaml.MarshalItemToFile(fs, "/gitops/pipelines.yaml", m); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func makePushEvent(t *testing.T, repoURL string) (http.Header, []byte) {
+	t.Helper()
+	repo, err := scm.NewRepository(repoURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers, body, err := repo.CreatePushEvent(scm.PushEvent{
+		Ref:      "main",
+		CommitID: testCommitID,
+		Message:  "Test commit",
+		Author:   "kam",
+	}, "testing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return headers, body
+}
@@ -1,20 +1,27 @@
 package pipelines
 
 import (
-	"fmt"
 	"path/filepath"
 
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
 
 type tektonBuilder struct {
 	files      res.Resources
 	gitOpsRepo string
-	triggers   []v1alpha1.EventListenerTrigger
+	drivers    *scm.DriverResolver
+	cicd       *config.PipelinesConfig
+	cicdPath   string
+	// pushBindings are the names of the push bindings that are deployed with
+	// the pipelines, the push binding for the GitOps repository is created
+	// when it's bootstrapped.
+	pushBindings map[string]bool
+	triggers     []v1alpha1.EventListenerTrigger
 }
 
 func buildEventListenerResources(gitOpsRepo string, m *config.Manifest) (res.Resources, error) {
@@ -26,8 +33,20 @@ func buildEventListenerResources(gitOpsRepo string, m *config.Manifest) (res.Res
 		return nil, nil
 	}
 	files := make(res.Resources)
-	tb := &tektonBuilder{files: files, gitOpsRepo: gitOpsRepo}
-	triggers, err := createTriggersForCICD(tb.gitOpsRepo, cfg)
+	drivers := m.GetDriverResolver()
+	repo, err := scm.NewRepository(gitOpsRepo, drivers)
+	if err != nil {
+		return nil, err
+	}
+	tb := &tektonBuilder{
+		files:        files,
+		gitOpsRepo:   gitOpsRepo,
+		drivers:      drivers,
+		cicd:         cfg,
+		cicdPath:     m.GetLayout().PathForPipelines(cfg),
+		pushBindings: map[string]bool{repo.PushBindingName(): true},
+	}
+	triggers, err := createTriggersForCICD(tb.gitOpsRepo, cfg, tb.drivers)
 	if err != nil {
 		return nil, err
 	}
@@ -36,8 +55,7 @@ func buildEventListenerResources(gitOpsRepo string, m *config.Manifest) (res.Res
 	if err != nil {
 		return nil, err
 	}
-	cicdPath := config.PathForPipelines(cfg)
-	files[getEventListenerPath(cicdPath)] = eventlisteners.CreateELFromTriggers(cfg.Name, saName, tb.triggers)
+	files[getEventListenerPath(tb.cicdPath)] = eventlisteners.CreateELFromTriggers(cfg.Name, saName, tb.triggers)
 	return files, nil
 }
 
@@ -45,26 +63,55 @@ func (tb *tektonBuilder) Service(app *config.Application, env *config.Environmen
 	if svc.SourceURL == "" {
 		return nil
 	}
-	repo, err := scm.NewRepository(svc.SourceURL)
+	repo, err := scm.NewRepository(svc.SourceURL, tb.drivers)
 	if err != nil {
 		return err
 	}
 	pipelines := getPipelines(env, svc, repo)
+	tb.addPushBinding(repo, pipelines.Integration.Bindings)
 	ciTrigger, err := repo.CreatePushTrigger(triggerName(svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings)
 	if err != nil {
 		return err
 	}
+	// The environment is bound so that the CI PipelineRuns are labelled
+	// with it, and can be listed for a service in a specific environment.
+	ciTrigger.Bindings = append(ciTrigger.Bindings, triggers.CreateEnvironmentBinding(env.Name))
 	tb.triggers = append(tb.triggers, ciTrigger)
+	if previous := svc.Webhook.PreviousSecret; previous != nil {
+		previousTrigger, err := repo.CreatePushTrigger(previousTriggerName(triggerName(svc.Name)), previous.Name, previous.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings)
+		if err != nil {
+			return err
+		}
+		previousTrigger.Bindings = append(previousTrigger.Bindings, triggers.CreateEnvironmentBinding(env.Name))
+		tb.triggers = append(tb.triggers, previousTrigger)
+	}
 	return nil
 }
 
+// addPushBinding adds the push binding for the provider of the repository, if
+// the bindings use it, and it's not already deployed, e.g. for a GitLab
+// service in a manifest with a GitHub GitOps repository.
+func (tb *tektonBuilder) addPushBinding(repo scm.Repository, bindings []string) {
+	name := repo.PushBindingName()
+	if tb.pushBindings[name] || !containsString(bindings, name) {
+		return
+	}
+	binding, _ := repo.CreatePushBinding(tb.cicd.Name)
+	tb.files[getPushBindingPath(tb.cicdPath, name)] = binding
+	tb.pushBindings[name] = true
+}
+
 func getEventListenerPath(cicdPath string) string {
 	return filepath.ToSlash(filepath.Join(cicdPath, "base", eventListenerPath))
 }
 
-func createTriggersForCICD(gitOpsRepo string, cfg *config.PipelinesConfig) ([]v1alpha1.EventListenerTrigger, error) {
+func getPushBindingPath(cicdPath, name string) string {
+	return filepath.ToSlash(filepath.Join(cicdPath, "base", "05-bindings", name+".yaml"))
+}
+
+func createTriggersForCICD(gitOpsRepo string, cfg *config.PipelinesConfig, drivers *scm.DriverResolver) ([]v1alpha1.EventListenerTrigger, error) {
 	triggers := []v1alpha1.EventListenerTrigger{}
-	repo, err := scm.NewRepository(gitOpsRepo)
+	repo, err := scm.NewRepository(gitOpsRepo, drivers)
 	if err != nil {
 		return []v1alpha1.EventListenerTrigger{}, err
 	}
@@ -73,13 +120,28 @@ func createTriggersForCICD(gitOpsRepo string, cfg *config.PipelinesConfig) ([]v1
 		return []v1alpha1.EventListenerTrigger{}, err
 	}
 	triggers = append(triggers, ciTrigger)
+	if cfg.Webhook != nil && cfg.Webhook.PreviousSecret != nil {
+		previous := cfg.Webhook.PreviousSecret
+		previousTrigger, err := repo.CreatePushTrigger(previousTriggerName("ci-dryrun-from-push"), previous.Name, previous.Namespace, "ci-dryrun-from-push-template", []string{repo.PushBindingName()})
+		if err != nil {
+			return []v1alpha1.EventListenerTrigger{}, err
+		}
+		triggers = append(triggers, previousTrigger)
+	}
 	return triggers, nil
 }
 
+// getPipelines returns the pipelines for the service, the push bindings of
+// other Git providers in the pipelines of the environment, e.g. the
+// github-push-binding, are replaced with the push binding for the provider of
+// the service repository.
+//
+// The bindings in the pipelines of the service are used as they are.
 func getPipelines(env *config.Environment, svc *config.Service, r scm.Repository) *config.Pipelines {
 	pipelines := defaultPipelines(r)
 	if env.Pipelines != nil {
 		pipelines = clonePipelines(env.Pipelines)
+		pipelines.Integration.Bindings = providerBindings(pipelines.Integration.Bindings, r)
 	}
 	if svc.Pipelines != nil {
 		if len(svc.Pipelines.Integration.Bindings) > 0 {
@@ -92,6 +154,17 @@ func getPipelines(env *config.Environment, svc *config.Service, r scm.Repository
 	return pipelines
 }
 
+func providerBindings(bindings []string, r scm.Repository) []string {
+	updated := []string{}
+	for _, v := range bindings {
+		if scm.IsPushBindingName(v) {
+			v = r.PushBindingName()
+		}
+		updated = append(updated, v)
+	}
+	return updated
+}
+
 func clonePipelines(p *config.Pipelines) *config.Pipelines {
 	return &config.Pipelines{
 		Integration: &config.TemplateBinding{
@@ -102,5 +175,20 @@ func clonePipelines(p *config.Pipelines) *config.Pipelines {
 }
 
 func triggerName(svc string) string {
-	return fmt.Sprintf("app-ci-build-from-push-%s", svc)
+	return eventlisteners.ServiceTriggerName(svc)
+}
+
+// previousTriggerName is the name of the trigger that accepts hooks signed
+// with the previous secret while a rotated webhook secret is rolled out.
+func previousTriggerName(name string) string {
+	return name + "-previous"
+}
+
+func containsString(items []string, s string) bool {
+	for _, v := range items {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
 
@@ -81,6 +82,102 @@ func TestBuildEventListenerWithNoGitOpsURL(t *testing.T) {
 	}
 }
 
+func TestBuildEventListenerWithPreviousWebhookSecrets(t *testing.T) {
+	svc := testService()
+	svc.Webhook.PreviousSecret = &config.Secret{Name: "webhook-secret-dev-previous", Namespace: "test-cicd"}
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name: "test-cicd",
+				Webhook: &config.Webhook{
+					PreviousSecret: &config.Secret{Name: "gitops-webhook-secret-previous", Namespace: "test-cicd"},
+				},
+			},
+		},
+		Environments: []*config.Environment{
+			testEnv(svc, "dev"),
+		},
+		GitOpsURL: testRepoName,
+	}
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	gitOpsRepo, err := scm.NewRepository(testRepoName, nil)
+	assertNoError(t, err)
+	svcRepo, err := scm.NewRepository(svc.SourceURL, nil)
+	assertNoError(t, err)
+	pipelines := getPipelines(m.Environments[0], svc, svcRepo)
+	wantTriggers := []triggersv1.EventListenerTrigger{}
+	for _, tt := range []struct {
+		repo                    scm.Repository
+		name, secret, namespace string
+		template                string
+		bindings                []string
+	}{
+		{gitOpsRepo, "ci-dryrun-from-push", "gitops-webhook-secret", "test-cicd", "ci-dryrun-from-push-template", []string{gitOpsRepo.PushBindingName()}},
+		{gitOpsRepo, "ci-dryrun-from-push-previous", "gitops-webhook-secret-previous", "test-cicd", "ci-dryrun-from-push-template", []string{gitOpsRepo.PushBindingName()}},
+		{svcRepo, "app-ci-build-from-push-" + svc.Name, svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings},
+		{svcRepo, "app-ci-build-from-push-" + svc.Name + "-previous", "webhook-secret-dev-previous", "test-cicd", pipelines.Integration.Template, pipelines.Integration.Bindings},
+	} {
+		trigger, err := tt.repo.CreatePushTrigger(tt.name, tt.secret, tt.namespace, tt.template, tt.bindings)
+		assertNoError(t, err)
+		if tt.repo == svcRepo {
+			trigger.Bindings = append(trigger.Bindings, triggers.CreateEnvironmentBinding(m.Environments[0].Name))
+		}
+		wantTriggers = append(wantTriggers, trigger)
+	}
+	want := res.Resources{
+		getEventListenerPath("config/test-cicd"): eventlisteners.CreateELFromTriggers("test-cicd", saName, wantTriggers),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("resources didn't match:%s\n", diff)
+	}
+}
+
+func TestBuildEventListenerWithMixedGitProviders(t *testing.T) {
+	svc := testService()
+	svc.SourceURL = "https://gitlab.example.com/org/test.git"
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name: "test-cicd",
+			},
+			Git: &config.GitConfig{
+				Drivers: map[string]string{"gitlab.example.com": "gitlab"},
+			},
+		},
+		Environments: []*config.Environment{
+			{
+				Name:      "test-dev",
+				Pipelines: &config.Pipelines{Integration: &config.TemplateBinding{Template: "app-ci-template", Bindings: []string{"dev-binding", "github-push-binding"}}},
+				Apps: []*config.Application{
+					{Name: "test-app", Services: []*config.Service{svc}},
+				},
+			},
+		},
+		GitOpsURL: testRepoName,
+	}
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	drivers := m.GetDriverResolver()
+	svcRepo, err := scm.NewRepository(svc.SourceURL, drivers)
+	assertNoError(t, err)
+	cicdTriggers, err := createTriggersForCICD(testRepoName, m.GetPipelinesConfig(), drivers)
+	assertNoError(t, err)
+	svcTrigger, err := svcRepo.CreatePushTrigger("app-ci-build-from-push-test-svc", svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, "app-ci-template", []string{"dev-binding", "gitlab-push-binding"})
+	assertNoError(t, err)
+	svcTrigger.Bindings = append(svcTrigger.Bindings, triggers.CreateEnvironmentBinding("test-dev"))
+	pushBinding, _ := svcRepo.CreatePushBinding("test-cicd")
+	want := res.Resources{
+		getEventListenerPath("config/test-cicd"):                     eventlisteners.CreateELFromTriggers("test-cicd", saName, append(cicdTriggers, svcTrigger)),
+		"config/test-cicd/base/05-bindings/gitlab-push-binding.yaml": pushBinding,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("resources didn't match:%s\n", diff)
+	}
+}
+
 func TestGetPipelines(t *testing.T) {
 	tests := []struct {
 		desc string
@@ -146,6 +243,48 @@ func TestGetPipelines(t *testing.T) {
 				},
 			},
 		},
+		{
+			"Push bindings of other providers are replaced",
+			&config.Environment{
+				Name: "test-env",
+				Pipelines: &config.Pipelines{
+					Integration: &config.TemplateBinding{
+						Template: "env-ci-template",
+						Bindings: []string{"env-ci-binding", "gitlab-push-binding"},
+					},
+				},
+			},
+			&config.Service{
+				Name: "test-service",
+			},
+			&config.Pipelines{
+				Integration: &config.TemplateBinding{
+					Template: "env-ci-template",
+					Bindings: []string{"env-ci-binding", "github-push-binding"},
+				},
+			},
+		},
+		{
+			"Push bindings in the service are not replaced",
+			&config.Environment{
+				Name:      "test-env",
+				Pipelines: testPipelines("env"),
+			},
+			&config.Service{
+				Name: "test-service",
+				Pipelines: &config.Pipelines{
+					Integration: &config.TemplateBinding{
+						Bindings: []string{"svc-ci-binding", "gitlab-push-binding"},
+					},
+				},
+			},
+			&config.Pipelines{
+				Integration: &config.TemplateBinding{
+					Template: "env-ci-template",
+					Bindings: []string{"svc-ci-binding", "gitlab-push-binding"},
+				},
+			},
+		},
 		{
 			"Only override the template in the service",
 			&config.Environment{
@@ -174,7 +313,7 @@ func TestGetPipelines(t *testing.T) {
 			if test.env.Pipelines != nil {
 				envPipelines = clonePipelines(test.env.Pipelines)
 			}
-			repo, _ := scm.NewRepository("https://github.com/foo/bar")
+			repo, _ := scm.NewRepository("https://github.com/foo/bar", nil)
 			got := getPipelines(test.env, test.svc, repo)
 			if diff := cmp.Diff(test.want, got); diff != "" {
 				rt.Errorf("getPipelines() failed:\n%v", diff)
@@ -187,22 +326,23 @@ func TestGetPipelines(t *testing.T) {
 }
 
 func fakeTriggers(t *testing.T, m *config.Manifest, gitOpsRepo string) []triggersv1.EventListenerTrigger {
-	triggers := []triggersv1.EventListenerTrigger{}
+	elTriggers := []triggersv1.EventListenerTrigger{}
 	cfg := m.GetPipelinesConfig()
-	cicdTriggers, err := createTriggersForCICD(gitOpsRepo, cfg)
+	cicdTriggers, err := createTriggersForCICD(gitOpsRepo, cfg, nil)
 	assertNoError(t, err)
-	triggers = append(triggers, cicdTriggers...)
+	elTriggers = append(elTriggers, cicdTriggers...)
 	for _, env := range m.Environments {
 		svc := testService()
-		repo, err := scm.NewRepository(svc.SourceURL)
+		repo, err := scm.NewRepository(svc.SourceURL, nil)
 		assertNoError(t, err)
 		pipelines := getPipelines(env, svc, repo)
 		devCITrigger, err := repo.CreatePushTrigger(fmt.Sprintf("app-ci-build-from-push-%s", svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings)
 		assertNoError(t, err)
-		triggers = append(triggers, devCITrigger)
+		devCITrigger.Bindings = append(devCITrigger.Bindings, triggers.CreateEnvironmentBinding(env.Name))
+		elTriggers = append(elTriggers, devCITrigger)
 	}
 
-	return triggers
+	return elTriggers
 }
 
 func testService() *config.Service {
@@ -25,6 +25,15 @@ func CreateImageRepoBinding(ns, bindingName, imageRepo, tlsVerify string) trigge
 	}
 }
 
+// CreateEnvironmentBinding returns an EventListener binding that binds the
+// environment param of the CI template to the name of the environment.
+func CreateEnvironmentBinding(envName string) *triggersv1.EventListenerBinding {
+	return &triggersv1.EventListenerBinding{
+		Name:  EnvironmentParam,
+		Value: &envName,
+	}
+}
+
 func createBindingParam(name, value string) triggersv1.Param {
 	return triggersv1.Param{
 		Name:  name,
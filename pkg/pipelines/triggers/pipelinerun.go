@@ -29,13 +29,17 @@ func createDevCIPipelineRun(saName string) pipelinev1.PipelineRun {
 	return pipelinev1.PipelineRun{
 		TypeMeta: pipelineRunTypeMeta,
 		ObjectMeta: meta.ObjectMeta(
-			meta.NamespacedName("", "app-ci-$(uid)")),
+			meta.NamespacedName("", "app-ci-$(uid)"),
+			meta.AddLabels(map[string]string{
+				GitCommitID:      "$(tt.params." + GitCommitID + ")",
+				EnvironmentLabel: "$(tt.params." + EnvironmentParam + ")",
+			})),
 		Spec: pipelinev1.PipelineRunSpec{
 			ServiceAccountName: saName,
 			PipelineRef:        createPipelineRef("app-ci-pipeline"),
 			Params: []pipelinev1.Param{
 				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
-				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("GIT_REPO", "$(tt.params."+GitRepositoryHTTPSURL+")"),
 				createPipelineBindingParam("TLSVERIFY", "$(tt.params.tlsVerify)"),
 				createPipelineBindingParam("BUILD_EXTRA_ARGS", "$(tt.params.build_extra_args)"),
 				createPipelineBindingParam("IMAGE", "$(tt.params.imageRepo):$(tt.params."+GitRef+")-$(tt.params."+GitCommitID+")"),
@@ -85,7 +89,7 @@ func createCIPipelineRun(saName string) pipelinev1.PipelineRun {
 			Resources:          createResources(),
 			Params: []pipelinev1.Param{
 				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
-				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("GIT_REPO", "$(tt.params."+GitRepositoryHTTPSURL+")"),
 				createPipelineBindingParam("COMMIT_SHA", "$(tt.params.io.openshift.build.commit.id)"),
 			},
 		},
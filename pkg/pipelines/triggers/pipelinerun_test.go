@@ -37,7 +37,11 @@ func TestCreateDevCIPipelineRun(t *testing.T) {
 	want := pipelinev1.PipelineRun{
 		TypeMeta: pipelineRunTypeMeta,
 		ObjectMeta: meta.ObjectMeta(
-			meta.NamespacedName("", "app-ci-$(uid)")),
+			meta.NamespacedName("", "app-ci-$(uid)"),
+			meta.AddLabels(map[string]string{
+				"io.openshift.build.commit.id": "$(tt.params.io.openshift.build.commit.id)",
+				"kam.openshift.io/environment": "$(tt.params.environment)",
+			})),
 		Spec: pipelinev1.PipelineRunSpec{
 			ServiceAccountName: sName,
 			PipelineRef:        createPipelineRef("app-ci-pipeline"),
@@ -56,7 +60,7 @@ func TestCreateDevCIPipelineRun(t *testing.T) {
 			},
 			Params: []pipelinev1.Param{
 				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
-				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("GIT_REPO", "$(tt.params."+GitRepositoryHTTPSURL+")"),
 				createPipelineBindingParam("TLSVERIFY", "$(tt.params.tlsVerify)"),
 				createPipelineBindingParam("BUILD_EXTRA_ARGS", "$(tt.params.build_extra_args)"),
 				createPipelineBindingParam("IMAGE", "$(tt.params.imageRepo):$(tt.params."+GitRef+")-$(tt.params."+GitCommitID+")"),
@@ -101,7 +105,7 @@ func TestCreateStageCIPipelineRun(t *testing.T) {
 			Resources:          createResources(),
 			Params: []v1beta1.Param{
 				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
-				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("GIT_REPO", "$(tt.params."+GitRepositoryHTTPSURL+")"),
 				createPipelineBindingParam("COMMIT_SHA", "$(tt.params.io.openshift.build.commit.id)"),
 			},
 		},
@@ -26,6 +26,16 @@ const (
 	// GitCommitDate is a label representing the commit timestamp for this
 	// build.
 	GitCommitDate = "io.openshift.build.commit.date"
+	// EnvironmentLabel is a label representing the environment of the service
+	// that the CI PipelineRun was started for.
+	EnvironmentLabel = "kam.openshift.io/environment"
+	// EnvironmentParam is the template param for the environment of the
+	// service, it's bound by the service's trigger.
+	EnvironmentParam = "environment"
+	// GitRepositoryHTTPSURL is the template param for the https URL of the
+	// repository, it's bound for the commit status even when the repository
+	// is cloned with an SSH URL.
+	GitRepositoryHTTPSURL = "gitrepositoryhttpsurl"
 )
 
 // GenerateTemplates will return a slice of trigger templates
@@ -73,10 +83,12 @@ func CreateDevCIBuildPRTemplate(ns, saName string) triggersv1.TriggerTemplate {
 				createTemplateParamSpec(GitCommitAuthor, "The name of the github user handle that made the commit"),
 				createTemplateParamSpec(GitCommitMessage, "The commit message"),
 				createTemplateParamSpec("gitrepositoryurl", "The git repository URL."),
+				createTemplateParamSpec(GitRepositoryHTTPSURL, "The https URL of the git repository."),
 				createTemplateParamSpec("fullname", "The repository name for this PullRequest."),
 				createTemplateParamSpec("imageRepo", "The repository to push built images to."),
 				createTemplateParamSpec("tlsVerify", "Enable image repository TLS certification verification."),
 				createTemplateParamSpec("build_extra_args", "Extra parameters passed for the push command when pushing images."),
+				createTemplateParamSpecDefault(EnvironmentParam, "The environment of the service.", ""),
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
@@ -124,6 +136,7 @@ func CreateCIDryRunTemplate(ns, saName string) triggersv1.TriggerTemplate {
 				createTemplateParamSpecDefault(GitRef, "The git revision", "master"),
 				createTemplateParamSpec(GitCommitID, "The specific commit SHA"),
 				createTemplateParamSpec("gitrepositoryurl", "The git repository url"),
+				createTemplateParamSpec(GitRepositoryHTTPSURL, "The https URL of the git repository"),
 				createTemplateParamSpec("fullname", "The repository name for this PullRequest"),
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
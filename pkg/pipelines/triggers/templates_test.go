@@ -76,6 +76,10 @@ func TestCreateDevCIBuildPRTemplate(t *testing.T) {
 					Name:        "gitrepositoryurl",
 					Description: "The git repository URL.",
 				},
+				{
+					Name:        GitRepositoryHTTPSURL,
+					Description: "The https URL of the git repository.",
+				},
 				{
 					Name:        "fullname",
 					Description: "The repository name for this PullRequest.",
@@ -92,6 +96,11 @@ func TestCreateDevCIBuildPRTemplate(t *testing.T) {
 					Name:        "build_extra_args",
 					Description: "Extra parameters passed for the push command when pushing images.",
 				},
+				{
+					Name:        "environment",
+					Description: "The environment of the service.",
+					Default:     strPtr(""),
+				},
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
@@ -161,6 +170,7 @@ func TestCreateCIDryRunTemplate(t *testing.T) {
 				{Name: GitRef, Description: "The git revision", Default: strPtr("master")},
 				{Name: "io.openshift.build.commit.id", Description: "The specific commit SHA"},
 				{Name: "gitrepositoryurl", Description: "The git repository url"},
+				{Name: GitRepositoryHTTPSURL, Description: "The https URL of the git repository"},
 				{Name: "fullname", Description: "The repository name for this PullRequest"},
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
@@ -0,0 +1,183 @@
+package pipelines
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/afero"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+// UpgradeOptions is a struct that provides the flags for upgrading a GitOps
+// repository.
+type UpgradeOptions struct {
+	PipelinesFolderPath string
+}
+
+// MigrationResult records the changes made by a migration.
+type MigrationResult struct {
+	Version     int      `json:"version"`
+	Description string   `json:"description"`
+	Files       []string `json:"files,omitempty"`
+}
+
+// UpgradeSummary describes the changes made to upgrade the GitOps repository
+// to the latest manifest version.
+type UpgradeSummary struct {
+	FromVersion int               `json:"fromVersion"`
+	ToVersion   int               `json:"toVersion"`
+	Migrations  []MigrationResult `json:"migrations"`
+	// Regenerated are the files that were rebuilt from the upgraded manifest.
+	Regenerated []string `json:"regenerated,omitempty"`
+}
+
+// migration upgrades the manifest, and the files generated from it, from the
+// previous version to its version.
+//
+// A migration returns the paths of the files that it changed, relative to the
+// pipelines folder. Migrations are applied again if the upgrade fails, so they
+// must not fail when applied to files that they've already changed.
+type migration struct {
+	version     int
+	description string
+	migrate     func(fs afero.Fs, pipelinesFolderPath string, m *config.Manifest) ([]string, error)
+}
+
+// migrations are the registered migrations, in version order, the last
+// migration must upgrade to the config.LatestVersion.
+var migrations = []migration{
+	{
+		version:     2,
+		description: "Regenerate the app-ci TriggerTemplate to label PipelineRuns with their commit",
+		migrate:     regenerateAppCITemplate,
+	},
+	{
+		version:     3,
+		description: "Replace the deprecated bases with resources in the kustomizations",
+		migrate:     fixKustomizationBases,
+	},
+}
+
+// UpgradeManifest applies the migrations for versions after the manifest's
+// version, and rebuilds the resources from the upgraded manifest.
+func UpgradeManifest(o *UpgradeOptions, appFs afero.Fs) (*UpgradeSummary, error) {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return nil, err
+	}
+	summary := &UpgradeSummary{
+		FromVersion: m.GetVersion(),
+		ToVersion:   config.LatestVersion,
+		Migrations:  []MigrationResult{},
+	}
+	for _, mig := range migrations {
+		if mig.version <= m.GetVersion() {
+			continue
+		}
+		files, err := mig.migrate(appFs, o.PipelinesFolderPath, m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upgrade to version %d: %w", mig.version, err)
+		}
+		summary.Migrations = append(summary.Migrations, MigrationResult{Version: mig.version, Description: mig.description, Files: files})
+		m.Version = mig.version
+	}
+	if len(summary.Migrations) == 0 {
+		return summary, nil
+	}
+
+	regenerated, err := writeManifestAndResources(m, appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(regenerated)
+	summary.Regenerated = regenerated
+	return summary, nil
+}
+
+// regenerateAppCITemplate replaces the app-ci TriggerTemplate that was written
+// by bootstrap, so that the PipelineRuns are labelled with the commit ID.
+func regenerateAppCITemplate(fs afero.Fs, pipelinesFolderPath string, m *config.Manifest) ([]string, error) {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return nil, nil
+	}
+	path := filepath.Join(m.GetLayout().PathForPipelines(cfg), "base", appCIPushTemplatePath)
+	exists, err := afero.Exists(fs, filepath.Join(pipelinesFolderPath, path))
+	if err != nil || !exists {
+		return nil, err
+	}
+	template := triggers.CreateDevCIBuildPRTemplate(cfg.Name, saName)
+	if err := yaml.MarshalItemToFile(fs, filepath.Join(pipelinesFolderPath, path), template); err != nil {
+		return nil, err
+	}
+	return []string{filepath.ToSlash(path)}, nil
+}
+
+// fixKustomizationBases moves the deprecated bases to the resources in all the
+// kustomizations in the pipelines folder, including those that weren't
+// generated by kam.
+//
+// The kustomizations are edited as unstructured maps so that the fields that
+// kam doesn't know about are kept, and kustomizations without bases are left
+// unchanged.
+func fixKustomizationBases(fs afero.Fs, pipelinesFolderPath string, m *config.Manifest) ([]string, error) {
+	var fixed []string
+	err := afero.Walk(fs, pipelinesFolderPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Name() != Kustomize {
+			return nil
+		}
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+		k := map[string]interface{}{}
+		if err := sigsyaml.Unmarshal(data, &k); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if !moveBasesToResources(k) {
+			return nil
+		}
+		if err := yaml.MarshalItemToFile(fs, path, k); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(pipelinesFolderPath, path)
+		if err != nil {
+			return err
+		}
+		fixed = append(fixed, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(fixed)
+	return fixed, nil
+}
+
+// moveBasesToResources appends the bases to the end of the resources in the
+// unstructured kustomization, returning false if there are no bases.
+func moveBasesToResources(k map[string]interface{}) bool {
+	bases, ok := k["bases"].([]interface{})
+	if !ok || len(bases) == 0 {
+		return false
+	}
+	resources, _ := k["resources"].([]interface{})
+	k["resources"] = append(resources, bases...)
+	delete(k, "bases")
+	return true
+}
@@ -0,0 +1,145 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+)
+
+func TestUpgradeManifest(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := buildManifest(true, true)
+	m.Version = 1
+	outputPath := writeTestManifest(t, fakeFs, m)
+	templatePath := filepath.Join(outputPath, "config", "cicd", "base", appCIPushTemplatePath)
+	assertNoError(t, afero.WriteFile(fakeFs, templatePath, []byte("kind: TriggerTemplate\n"), 0644))
+
+	summary, err := UpgradeManifest(&UpgradeOptions{PipelinesFolderPath: outputPath}, fakeFs)
+	assertNoError(t, err)
+
+	want := []MigrationResult{
+		{
+			Version:     2,
+			Description: migrations[0].description,
+			Files:       []string{"config/cicd/base/" + appCIPushTemplatePath},
+		},
+		{
+			Version:     3,
+			Description: migrations[1].description,
+		},
+	}
+	if diff := cmp.Diff(want, summary.Migrations); diff != "" {
+		t.Fatalf("migrations didn't match:\n%s", diff)
+	}
+	if summary.FromVersion != 1 || summary.ToVersion != config.LatestVersion {
+		t.Fatalf("got upgrade from %d to %d, want from 1 to %d", summary.FromVersion, summary.ToVersion, config.LatestVersion)
+	}
+	b, err := afero.ReadFile(fakeFs, templatePath)
+	assertNoError(t, err)
+	if !strings.Contains(string(b), triggers.GitCommitID+": $(tt.params."+triggers.GitCommitID+")") {
+		t.Fatalf("template was not regenerated:\n%s", b)
+	}
+	if got := readTestManifest(t, fakeFs, outputPath).Version; got != config.LatestVersion {
+		t.Fatalf("got manifest version %d, want %d", got, config.LatestVersion)
+	}
+}
+
+func TestUpgradeManifestWithLatestVersion(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := buildManifest(true, true)
+	m.Version = config.LatestVersion
+	outputPath := writeTestManifest(t, fakeFs, m)
+
+	summary, err := UpgradeManifest(&UpgradeOptions{PipelinesFolderPath: outputPath}, fakeFs)
+	assertNoError(t, err)
+
+	if l := len(summary.Migrations); l != 0 {
+		t.Fatalf("got %d migrations, want 0", l)
+	}
+	if summary.Regenerated != nil {
+		t.Fatalf("files were regenerated for a manifest at the latest version: %v", summary.Regenerated)
+	}
+}
+
+func TestMigrationsAreOrdered(t *testing.T) {
+	for i, m := range migrations {
+		if m.version != i+2 {
+			t.Errorf("migration %d upgrades to version %d, want %d", i, m.version, i+2)
+		}
+	}
+	if last := migrations[len(migrations)-1].version; last != config.LatestVersion {
+		t.Fatalf("the last migration upgrades to version %d, want %d", last, config.LatestVersion)
+	}
+}
+
+func TestUpgradeManifestFixesKustomizationBases(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	m := buildManifest(false, false)
+	m.Version = 2
+	outputPath := writeTestManifest(t, fakeFs, m)
+	configFile := filepath.Join(outputPath, "environments/test-dev/apps/test-app/services/test-svc/base/config", Kustomize)
+	assertNoError(t, afero.WriteFile(fakeFs, configFile, []byte(`bases:
+- ../../../shared
+resources:
+- deployment.yaml
+namePrefix: test-
+configMapGenerator:
+- name: cfg
+  behavior: merge
+  envs:
+  - app.env
+  options:
+    disableNameSuffixHash: true
+`), 0644))
+	unchangedFile := filepath.Join(outputPath, "environments/test-dev/apps/test-app/services/test-svc/base/extra", Kustomize)
+	unchanged := []byte(`# kustomization without bases
+resources: [service.yaml]
+namePrefix: test-
+`)
+	assertNoError(t, afero.WriteFile(fakeFs, unchangedFile, unchanged, 0644))
+
+	summary, err := UpgradeManifest(&UpgradeOptions{PipelinesFolderPath: outputPath}, fakeFs)
+	assertNoError(t, err)
+
+	want := []MigrationResult{
+		{
+			Version:     3,
+			Description: migrations[1].description,
+			Files:       []string{"environments/test-dev/apps/test-app/services/test-svc/base/config/" + Kustomize},
+		},
+	}
+	if diff := cmp.Diff(want, summary.Migrations); diff != "" {
+		t.Fatalf("migrations didn't match:\n%s", diff)
+	}
+	wantKustomization := map[string]interface{}{
+		"resources":  []interface{}{"deployment.yaml", "../../../shared"},
+		"namePrefix": "test-",
+		"configMapGenerator": []interface{}{
+			map[string]interface{}{
+				"name":     "cfg",
+				"behavior": "merge",
+				"envs":     []interface{}{"app.env"},
+				"options":  map[string]interface{}{"disableNameSuffixHash": true},
+			},
+		},
+	}
+	if diff := cmp.Diff(wantKustomization, mustReadFileAsMap(t, fakeFs, configFile)); diff != "" {
+		t.Fatalf("kustomization didn't match:\n%s", diff)
+	}
+	data, err := afero.ReadFile(fakeFs, unchangedFile)
+	assertNoError(t, err)
+	if diff := cmp.Diff(string(unchanged), string(data)); diff != "" {
+		t.Fatalf("kustomization without bases was changed:\n%s", diff)
+	}
+	overlaysFile := filepath.Join(outputPath, "environments/test-dev/apps/test-app/services/test-svc/overlays", Kustomize)
+	if diff := cmp.Diff(map[string]interface{}{"resources": []interface{}{"../base"}}, mustReadFileAsMap(t, fakeFs, overlaysFile)); diff != "" {
+		t.Fatalf("regenerated kustomization didn't match:\n%s", diff)
+	}
+}
@@ -1,14 +1,20 @@
 package pipelines
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/spf13/afero"
 )
@@ -28,13 +34,24 @@ func BootstrapRepository(o *BootstrapOptions, f clientFactory, e executor, appFs
 		return nil
 	}
 
-	u, err := url.Parse(o.GitOpsRepoURL)
+	// The repository is created with the API of the host, which is accessed
+	// with https for SSH URLs.
+	httpsURL, err := giturl.HTTPS(o.GitOpsRepoURL)
 	if err != nil {
 		return fmt.Errorf("failed to parse GitOps repo URL %q: %w", o.GitOpsRepoURL, err)
 	}
-	parts := strings.Split(u.Path, "/")
-	org := parts[1]
-	repoName := strings.TrimSuffix(strings.Join(parts[2:], "/"), ".git")
+	u, err := url.Parse(httpsURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse GitOps repo URL %q: %w", o.GitOpsRepoURL, err)
+	}
+	// The org is every part of the path before the repository name, GitLab
+	// projects can be in nested subgroups e.g. group/subgroup/team/repo.
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 {
+		return fmt.Errorf("failed to get the org and repository name from %q", o.GitOpsRepoURL)
+	}
+	org := strings.Join(parts[:len(parts)-1], "/")
+	repoName := strings.TrimSuffix(parts[len(parts)-1], ".git")
 	u.User = url.UserPassword("", o.GitHostAccessToken)
 
 	client, err := f(u.String())
@@ -59,7 +76,7 @@ func BootstrapRepository(o *BootstrapOptions, f clientFactory, e executor, appFs
 		Namespace:   org,
 		Name:        repoName,
 	}
-	created, _, err := client.Repositories.Create(context.Background(), ri)
+	created, err := createRepository(ctx, client, ri)
 	if err != nil {
 		repo := fmt.Sprintf("%s/%s", org, repoName)
 		if org == "" {
@@ -76,6 +93,112 @@ func BootstrapRepository(o *BootstrapOptions, f clientFactory, e executor, appFs
 	return err
 }
 
+func createRepository(ctx context.Context, client *scm.Client, ri *scm.RepositoryInput) (*scm.Repository, error) {
+	if client.Driver == scm.DriverGitlab && ri.Namespace != "" {
+		return createGitLabProject(ctx, client, ri)
+	}
+	created, _, err := client.Repositories.Create(ctx, ri)
+	return created, err
+}
+
+// createGitLabProject creates a project in the GitLab group, which can be a
+// nested subgroup e.g. group/subgroup/team.
+//
+// The go-scm GitLab driver searches for the namespace by name, which doesn't
+// identify subgroups, so the ID of the namespace is resolved from its full
+// path.
+func createGitLabProject(ctx context.Context, client *scm.Client, ri *scm.RepositoryInput) (*scm.Repository, error) {
+	namespace := struct {
+		ID int `json:"id"`
+	}{}
+	err := gitlabRequest(ctx, client, "GET", "api/v4/namespaces/"+url.PathEscape(ri.Namespace), nil, &namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the namespace %q: %w", ri.Namespace, err)
+	}
+	visibility := "public"
+	if ri.Private {
+		visibility = "private"
+	}
+	in := map[string]interface{}{
+		"name":         ri.Name,
+		"description":  ri.Description,
+		"namespace_id": namespace.ID,
+		"visibility":   visibility,
+	}
+	project := struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		HTTPURLToRepo     string `json:"http_url_to_repo"`
+		SSHURLToRepo      string `json:"ssh_url_to_repo"`
+	}{}
+	if err := gitlabRequest(ctx, client, "POST", "api/v4/projects", in, &project); err != nil {
+		return nil, err
+	}
+	return &scm.Repository{
+		Namespace: ri.Namespace,
+		Name:      ri.Name,
+		FullName:  project.PathWithNamespace,
+		Clone:     project.HTTPURLToRepo,
+		CloneSSH:  project.SSHURLToRepo,
+	}, nil
+}
+
+func gitlabRequest(ctx context.Context, client *scm.Client, method, path string, in, out interface{}) error {
+	req := &scm.Request{Method: method, Path: path, Header: http.Header{}}
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Body = bytes.NewReader(b)
+	}
+	res, err := client.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.Status >= 300 {
+		return gitlabError(res)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// gitlabError returns the error for a failed GitLab API request, with the
+// message in the response, e.g. the validation errors for a project.
+func gitlabError(res *scm.Response) error {
+	body := struct {
+		Message interface{} `json:"message"`
+		Error   string      `json:"error"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return errors.New(http.StatusText(res.Status))
+	}
+	message := body.Error
+	switch m := body.Message.(type) {
+	case string:
+		message = m
+	case map[string]interface{}:
+		// Validation errors are a list of messages for each field e.g.
+		// {"name": ["has already been taken"]}.
+		fields := []string{}
+		for k, v := range m {
+			values, ok := v.([]interface{})
+			if !ok {
+				values = []interface{}{v}
+			}
+			for _, value := range values {
+				fields = append(fields, fmt.Sprintf("%s %v", k, value))
+			}
+		}
+		sort.Strings(fields)
+		message = strings.Join(fields, ", ")
+	}
+	if message == "" {
+		return errors.New(http.StatusText(res.Status))
+	}
+	return fmt.Errorf("%s: %s", http.StatusText(res.Status), message)
+}
+
 func pushRepository(o *BootstrapOptions, remote string, e executor, appFs afero.Fs) error {
 	if exists, _ := ioutils.IsExisting(appFs, filepath.Join(o.OutputPath, ".git")); exists {
 		if err := appFs.RemoveAll(filepath.Join(o.OutputPath, ".git")); err != nil {
@@ -104,7 +227,11 @@ func pushRepository(o *BootstrapOptions, remote string, e executor, appFs afero.
 }
 
 func repoURL(u string) (string, error) {
-	parsed, err := url.Parse(u)
+	httpsURL, err := giturl.HTTPS(u)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %q: %w", u, err)
+	}
+	parsed, err := url.Parse(httpsURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse %q: %w", u, err)
 	}
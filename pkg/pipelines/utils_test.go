@@ -2,7 +2,11 @@ package pipelines
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -12,6 +16,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/test"
 )
@@ -53,6 +58,126 @@ func TestBootstrapRepository_with_org(t *testing.T) {
 	assertRepositoryCreated(t, fakeData, "testing", "test-repo")
 }
 
+func TestBootstrapRepository_with_ssh_url(t *testing.T) {
+	token := "this-is-a-test-token"
+	factory, fakeData := newMockClientFactory(t, token)
+	fakeData.CurrentUser = scm.User{Login: "test-user"}
+
+	err := BootstrapRepository(
+		&BootstrapOptions{
+			GitOpsRepoURL:      "git@example.com:testing/test-repo.git",
+			GitHostAccessToken: token,
+		},
+		factory,
+		newMockExecutor(),
+		ioutils.NewMemoryFilesystem(),
+	)
+	assertNoError(t, err)
+	assertRepositoryCreated(t, fakeData, "testing", "test-repo")
+}
+
+func TestBootstrapRepository_with_gitlab_subgroup(t *testing.T) {
+	token := "this-is-a-test-token"
+	var created map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "GET /api/v4/user":
+			fmt.Fprint(w, `{"id": 1, "username": "test-user"}`)
+		case "GET /api/v4/namespaces/group%2Fsubgroup%2Fteam":
+			fmt.Fprint(w, `{"id": 42, "full_path": "group/subgroup/team"}`)
+		case "POST /api/v4/projects":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Error(err)
+			}
+			fmt.Fprint(w, `{"path_with_namespace": "group/subgroup/team/test-repo", "ssh_url_to_repo": "git@gitlab.example.com:group/subgroup/team/test-repo.git"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	f := func(repoURL string) (*scm.Client, error) {
+		return factory.NewClient("gitlab", ts.URL, token)
+	}
+	e := newMockExecutor()
+
+	err := BootstrapRepository(
+		&BootstrapOptions{
+			GitOpsRepoURL:      "https://gitlab.example.com/group/subgroup/team/test-repo.git",
+			GitHostAccessToken: token,
+			OutputPath:         "/tmp",
+		},
+		f,
+		e,
+		ioutils.NewMemoryFilesystem(),
+	)
+	assertNoError(t, err)
+
+	want := map[string]interface{}{
+		"name":         "test-repo",
+		"description":  defaultRepoDescription,
+		"namespace_id": float64(42),
+		"visibility":   "private",
+	}
+	if diff := cmp.Diff(want, created); diff != "" {
+		t.Fatalf("failed to create the project:\n%s", diff)
+	}
+	remote := e.executed[4]
+	if diff := cmp.Diff([]string{"remote", "add", "origin", "git@gitlab.example.com:group/subgroup/team/test-repo.git"}, remote.Args); diff != "" {
+		t.Fatalf("failed to push to the project:\n%s", diff)
+	}
+}
+
+func TestBootstrapRepository_with_gitlab_error(t *testing.T) {
+	errorTests := []struct {
+		status  int
+		body    string
+		wantErr string
+	}{
+		{http.StatusBadRequest, `{"message": {"name": ["has already been taken"], "path": ["has already been taken"]}}`, "Bad Request: name has already been taken, path has already been taken"},
+		{http.StatusForbidden, `{"message": "403 Forbidden"}`, "Forbidden: 403 Forbidden"},
+		{http.StatusUnauthorized, `{"error": "invalid_token"}`, "Unauthorized: invalid_token"},
+		{http.StatusMultipleChoices, ``, "Multiple Choices"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.wantErr, func(rt *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.Method + " " + r.URL.EscapedPath() {
+				case "GET /api/v4/user":
+					fmt.Fprint(w, `{"id": 1, "username": "test-user"}`)
+				case "GET /api/v4/namespaces/group%2Fsubgroup":
+					fmt.Fprint(w, `{"id": 42, "full_path": "group/subgroup"}`)
+				case "POST /api/v4/projects":
+					w.WriteHeader(tt.status)
+					fmt.Fprint(w, tt.body)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer ts.Close()
+			f := func(repoURL string) (*scm.Client, error) {
+				return factory.NewClient("gitlab", ts.URL, "this-is-a-test-token")
+			}
+
+			err := BootstrapRepository(
+				&BootstrapOptions{
+					GitOpsRepoURL:      "https://gitlab.example.com/group/subgroup/test-repo.git",
+					GitHostAccessToken: "this-is-a-test-token",
+					OutputPath:         "/tmp",
+				},
+				f,
+				newMockExecutor(),
+				ioutils.NewMemoryFilesystem(),
+			)
+			if !test.ErrorMatch(rt, tt.wantErr, err) {
+				rt.Fatalf("got error %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestBootstrapRepository_with_no_access_token(t *testing.T) {
 	token := "this-is-a-test-token"
 	factory, fakeData := newMockClientFactory(t, token)
@@ -216,6 +341,8 @@ func TestRepoURL(t *testing.T) {
 	}{
 		{"https://github.com/my-org/my-repo.git", "https://github.com"},
 		{"https://gl.example.com/my-org/my-repo.git", "https://gl.example.com"},
+		{"git@github.com:my-org/my-repo.git", "https://github.com"},
+		{"ssh://git@gl.example.com:2222/my-org/my-repo.git", "https://gl.example.com"},
 	}
 
 	for _, tt := range urlTests {
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	Classname string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the report as JUnit XML, with a test case for each
+// checked path, and a failure for each issue found for the path.
+func (r *Report) WriteJUnit(out io.Writer) error {
+	suite := junitTestSuite{Name: "kam validate", Tests: len(r.Checked)}
+	for _, path := range r.Checked {
+		tc := junitTestCase{Name: path, Classname: "kam.validate"}
+		for _, i := range r.IssuesFor(path) {
+			msg := i.Message
+			if i.Object != "" {
+				msg = fmt.Sprintf("%s: %s", i.Object, i.Message)
+			}
+			tc.Failures = append(tc.Failures, junitFailure{Type: i.Check, Message: msg})
+		}
+		if len(tc.Failures) > 0 {
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, "\n")
+	return err
+}
@@ -0,0 +1,74 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	routev1 "github.com/openshift/api/route/v1"
+	pipelinev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/kustomize/api/resource"
+
+	argoprojv1 "github.com/redhat-developer/kam/pkg/pipelines/argocd/v1alpha1"
+)
+
+var scheme = runtime.NewScheme()
+
+// argoCDTypes are the Argo CD types, these are not registered in the scheme,
+// as they don't implement runtime.Object.
+var argoCDTypes = map[schema.GroupVersionKind]func() interface{}{
+	{Group: "argoproj.io", Version: "v1alpha1", Kind: "Application"}: func() interface{} { return &argoprojv1.Application{} },
+}
+
+func init() {
+	for _, add := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		routev1.Install,
+		pipelinev1alpha1.AddToScheme,
+		pipelinev1.AddToScheme,
+		triggersv1alpha1.AddToScheme,
+		triggersv1.AddToScheme,
+	} {
+		if err := add(scheme); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// parseObject decodes the object into the type for its kind, failing if the
+// kind is unknown, or if the object has fields that the type doesn't have.
+func parseObject(r *resource.Resource) error {
+	gvk := r.GetGvk()
+	k := schema.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind}
+	obj, err := newObject(k)
+	if err != nil {
+		return err
+	}
+	data, err := r.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(obj); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", k.Kind, err)
+	}
+	return nil
+}
+
+func newObject(gvk schema.GroupVersionKind) (interface{}, error) {
+	if f, ok := argoCDTypes[gvk]; ok {
+		return f(), nil
+	}
+	obj, err := scheme.New(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("unknown kind %s in %s", gvk.Kind, gvk.GroupVersion())
+	}
+	return obj, nil
+}
@@ -0,0 +1,217 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mkmik/multierror"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resource"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+)
+
+const (
+	// ManifestCheck reports errors in the manifest.
+	ManifestCheck = "manifest"
+	// KustomizeCheck reports kustomizations that fail to build.
+	KustomizeCheck = "kustomize"
+	// SchemaCheck reports objects that don't parse as a known type.
+	SchemaCheck = "schema"
+	// NamespaceCheck reports objects outside of the namespace of their
+	// environment.
+	NamespaceCheck = "namespace"
+
+	kustomizationFile = "kustomization.yaml"
+)
+
+// Options configures the validation.
+type Options struct {
+	PipelinesFolderPath string
+}
+
+// Issue is a problem found in the GitOps repository.
+//
+// The Path is the path of the manifest, or the kustomization that was built,
+// relative to the pipelines folder.
+type Issue struct {
+	Check   string `json:"check"`
+	Path    string `json:"path"`
+	Object  string `json:"object,omitempty"`
+	Message string `json:"message"`
+}
+
+// Report is the result of validating a GitOps repository.
+type Report struct {
+	// Checked are the paths of the manifest and the kustomizations that were
+	// validated, relative to the pipelines folder.
+	Checked []string `json:"checked"`
+	Issues  []Issue  `json:"issues"`
+}
+
+// IssuesFor returns the issues found for a checked path.
+func (r *Report) IssuesFor(path string) []Issue {
+	issues := []Issue{}
+	for _, i := range r.Issues {
+		if i.Path == path {
+			issues = append(issues, i)
+		}
+	}
+	return issues
+}
+
+// kustomizeTarget is a kustomization to build, if the namespace is not empty,
+// the namespaced objects must be in the namespace.
+type kustomizeTarget struct {
+	path      string
+	namespace string
+}
+
+// Validate validates the manifest in the pipelines folder, and builds the
+// kustomizations for the environments, apps, services and configuration, and
+// checks the objects that they generate.
+func Validate(fs afero.Fs, o *Options) (*Report, error) {
+	report := &Report{Checked: []string{config.PipelinesFile}, Issues: []Issue{}}
+	m, err := config.LoadManifest(fs, o.PipelinesFolderPath)
+	if err != nil {
+		for _, err := range multierror.Split(err) {
+			report.Issues = append(report.Issues, Issue{Check: ManifestCheck, Path: config.PipelinesFile, Message: err.Error()})
+		}
+		// The manifest is decoded without validation, so that the
+		// kustomizations can be checked.
+		m, err = config.ParsePipelinesFolder(fs, o.PipelinesFolderPath)
+		if err != nil {
+			return report, nil
+		}
+	}
+
+	kfs, err := copyToKustomizeFs(fs, o.PipelinesFolderPath)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := kustomizeTargets(fs, o.PipelinesFolderPath, m)
+	if err != nil {
+		return nil, err
+	}
+	k := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	for _, t := range targets {
+		report.Checked = append(report.Checked, t.path)
+		built, err := k.Run(kfs, filepath.Join("/", t.path))
+		if err != nil {
+			report.Issues = append(report.Issues, Issue{Check: KustomizeCheck, Path: t.path, Message: err.Error()})
+			continue
+		}
+		for _, r := range built.Resources() {
+			report.Issues = append(report.Issues, checkObject(t, r)...)
+		}
+	}
+	return report, nil
+}
+
+func checkObject(t kustomizeTarget, r *resource.Resource) []Issue {
+	issues := []Issue{}
+	gvk := r.GetGvk()
+	object := fmt.Sprintf("%s/%s", gvk.Kind, r.GetName())
+	if err := parseObject(r); err != nil {
+		issues = append(issues, Issue{Check: SchemaCheck, Path: t.path, Object: object, Message: err.Error()})
+	}
+	if t.namespace != "" && gvk.IsNamespaceableKind() && r.GetNamespace() != "" && r.GetNamespace() != t.namespace {
+		issues = append(issues, Issue{
+			Check:   NamespaceCheck,
+			Path:    t.path,
+			Object:  object,
+			Message: fmt.Sprintf("namespace %q is not the namespace %q of the environment", r.GetNamespace(), t.namespace),
+		})
+	}
+	return issues
+}
+
+// kustomizeTargets returns the kustomizations that exist for the
+// environments, apps and services in the manifest, and for the configuration.
+//
+// Configuration is built from the overlays, if the folder has overlays.
+func kustomizeTargets(fs afero.Fs, pipelinesFolderPath string, m *config.Manifest) ([]kustomizeTarget, error) {
+	layout := m.GetLayout()
+	candidates := []kustomizeTarget{}
+	for _, env := range m.Environments {
+		candidates = append(candidates, kustomizeTarget{path: filepath.Join(layout.PathForEnvironment(env), "env", "overlays"), namespace: env.GetNamespace()})
+		for _, app := range env.Apps {
+			candidates = append(candidates, kustomizeTarget{path: filepath.Join(layout.PathForApplication(env, app), "overlays"), namespace: env.GetNamespace()})
+			for _, svc := range app.Services {
+				candidates = append(candidates, kustomizeTarget{path: filepath.Join(layout.PathForService(app, env, svc.Name), "overlays"), namespace: env.GetNamespace()})
+			}
+		}
+	}
+	configPath := filepath.Dir(layout.PathForArgoCD())
+	configDirs, err := afero.ReadDir(fs, filepath.Join(pipelinesFolderPath, configPath))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, dir := range configDirs {
+		if !dir.IsDir() {
+			continue
+		}
+		path := filepath.Join(configPath, dir.Name())
+		overlays, err := hasKustomization(fs, filepath.Join(pipelinesFolderPath, path, "overlays"))
+		if err != nil {
+			return nil, err
+		}
+		if overlays {
+			path = filepath.Join(path, "overlays")
+		}
+		candidates = append(candidates, kustomizeTarget{path: path})
+	}
+
+	targets := []kustomizeTarget{}
+	for _, c := range candidates {
+		exists, err := hasKustomization(fs, filepath.Join(pipelinesFolderPath, c.path))
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			c.path = filepath.ToSlash(c.path)
+			targets = append(targets, c)
+		}
+	}
+	sort.SliceStable(targets, func(i, j int) bool { return targets[i].path < targets[j].path })
+	return targets, nil
+}
+
+func hasKustomization(fs afero.Fs, path string) (bool, error) {
+	return afero.Exists(fs, filepath.Join(path, kustomizationFile))
+}
+
+// copyToKustomizeFs copies the pipelines folder to the root of an in-memory
+// filesystem, for kustomize to build from.
+func copyToKustomizeFs(fs afero.Fs, pipelinesFolderPath string) (filesys.FileSystem, error) {
+	kfs := filesys.MakeFsInMemory()
+	err := afero.Walk(fs, pipelinesFolderPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(pipelinesFolderPath, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if rel != "." && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return kfs.MkdirAll(filepath.Join("/", rel))
+		}
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+		return kfs.WriteFile(filepath.Join("/", rel), data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the pipelines folder %s: %w", pipelinesFolderPath, err)
+	}
+	return kfs, nil
+}
@@ -0,0 +1,187 @@
+package validator
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+const testManifest = `environments:
+- name: dev
+config:
+  argocd:
+    namespace: argocd
+`
+
+const testNamespace = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: dev
+`
+
+const testDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: dev
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: quay.io/example/web
+`
+
+const testApplication = `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: dev-env
+  namespace: argocd
+spec:
+  destination:
+    namespace: dev
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: environments/dev/env/overlays
+    repoURL: https://github.com/org/gitops.git
+`
+
+func TestValidate(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	writeFiles(t, fs, map[string]string{
+		"pipelines.yaml": testManifest,
+		"environments/dev/env/base/kustomization.yaml":     "resources:\n- namespace.yaml\n- deployment.yaml\n",
+		"environments/dev/env/base/namespace.yaml":         testNamespace,
+		"environments/dev/env/base/deployment.yaml":        testDeployment,
+		"environments/dev/env/overlays/kustomization.yaml": "bases:\n- ../base\n",
+		"config/argocd/kustomization.yaml":                 "resources:\n- dev-env-app.yaml\n",
+		"config/argocd/dev-env-app.yaml":                   testApplication,
+	})
+
+	report, err := Validate(fs, &Options{PipelinesFolderPath: "/gitops"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Report{
+		Checked: []string{"pipelines.yaml", "config/argocd", "environments/dev/env/overlays"},
+		Issues:  []Issue{},
+	}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Fatalf("Validate() failed:\n%s", diff)
+	}
+}
+
+func TestValidateWithIssues(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	writeFiles(t, fs, map[string]string{
+		"pipelines.yaml": testManifest,
+		"environments/dev/env/base/kustomization.yaml":     "resources:\n- deployment.yaml\n- widget.yaml\n",
+		"environments/dev/env/base/deployment.yaml":        strings.Replace(strings.Replace(testDeployment, "replicas", "replica", 1), "namespace: dev", "namespace: stage", 1),
+		"environments/dev/env/base/widget.yaml":            "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: test\n",
+		"environments/dev/env/overlays/kustomization.yaml": "bases:\n- ../base\n",
+		"config/argocd/kustomization.yaml":                 "resources:\n- missing.yaml\n",
+	})
+
+	report, err := Validate(fs, &Options{PipelinesFolderPath: "/gitops"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Issue{
+		{Check: KustomizeCheck, Path: "config/argocd"},
+		{Check: SchemaCheck, Path: "environments/dev/env/overlays", Object: "Deployment/web", Message: `failed to parse Deployment: json: unknown field "replica"`},
+		{Check: NamespaceCheck, Path: "environments/dev/env/overlays", Object: "Deployment/web", Message: `namespace "stage" is not the namespace "dev" of the environment`},
+		{Check: SchemaCheck, Path: "environments/dev/env/overlays", Object: "Widget/test", Message: "unknown kind Widget in example.com/v1"},
+	}
+	if diff := cmp.Diff(want, report.Issues, cmpopts.IgnoreFields(Issue{}, "Message"), cmpopts.SortSlices(func(x, y Issue) bool {
+		return x.Path+x.Object+x.Check < y.Path+y.Object+y.Check
+	})); diff != "" {
+		t.Fatalf("Validate() issues failed:\n%s", diff)
+	}
+	for _, w := range want[1:] {
+		if !containsMessage(report.Issues, w.Message) {
+			t.Errorf("Validate() did not report %q", w.Message)
+		}
+	}
+}
+
+func TestValidateWithInvalidManifest(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	writeFiles(t, fs, map[string]string{
+		"pipelines.yaml": "environments:\n- name: dev_env\n",
+	})
+
+	report, err := Validate(fs, &Options{PipelinesFolderPath: "/gitops"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l := len(report.Issues); l != 1 {
+		t.Fatalf("Validate() got %d issues, want 1", l)
+	}
+	if got := report.Issues[0]; got.Check != ManifestCheck || !strings.Contains(got.Message, `invalid name "dev_env"`) {
+		t.Fatalf("Validate() got issue %#v", got)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	report := &Report{
+		Checked: []string{"pipelines.yaml", "environments/dev/env/overlays"},
+		Issues: []Issue{
+			{Check: SchemaCheck, Path: "environments/dev/env/overlays", Object: "Widget/test", Message: "unknown kind Widget in example.com/v1"},
+		},
+	}
+	var b bytes.Buffer
+
+	if err := report.WriteJUnit(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="kam validate" tests="2" failures="1">
+    <testcase name="pipelines.yaml" classname="kam.validate"></testcase>
+    <testcase name="environments/dev/env/overlays" classname="kam.validate">
+      <failure type="schema" message="Widget/test: unknown kind Widget in example.com/v1"></failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("WriteJUnit() failed:\n%s", diff)
+	}
+}
+
+func containsMessage(issues []Issue, msg string) bool {
+	for _, i := range issues {
+		if i.Message == msg {
+			return true
+		}
+	}
+	return false
+}
+
+func writeFiles(t *testing.T, fs afero.Fs, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		if err := afero.WriteFile(fs, filepath.Join("/gitops", name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/pipelineruns"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+)
+
+const (
+	deliveryTimeout  = 30 * time.Second
+	pollInterval     = 2 * time.Second
+	maxResponseBytes = 4096
+)
+
+// TestEventOptions configures the synthetic push event that is sent to the
+// EventListener.
+type TestEventOptions struct {
+	AccessToken           string
+	PipelinesFolderPath   string
+	ServiceName           *QualifiedServiceName
+	IsCICD                bool
+	Ref                   string
+	InsecureSkipTLSVerify bool
+	// Wait is how long to wait for a PipelineRun to be created for the
+	// event, if zero, the PipelineRuns are not checked.
+	Wait time.Duration
+}
+
+// DeliveryResult is the response of the EventListener to a test event, and
+// the PipelineRuns that were created for it.
+type DeliveryResult struct {
+	ListenerURL  string   `json:"listenerURL"`
+	Commit       string   `json:"commit"`
+	StatusCode   int      `json:"statusCode"`
+	Response     string   `json:"response"`
+	EventID      string   `json:"eventID,omitempty"`
+	PipelineRuns []string `json:"pipelineRuns"`
+}
+
+// Accepted returns true if the EventListener accepted the event.
+func (r *DeliveryResult) Accepted() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// SendTestEvent sends a push event for the head commit of the ref, signed with
+// the webhook secret, to the EventListener, as the Git host would.
+func SendTestEvent(o *TestEventOptions) (*DeliveryResult, error) {
+	webhook, err := newWebhookInfo(o.AccessToken, o.PipelinesFolderPath, o.ServiceName, o.IsCICD)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := getWebhookSecret(webhook.clusterResource, webhook.cicdNamepace, o.IsCICD, o.ServiceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook secret: %v", err)
+	}
+	ref := strings.TrimPrefix(o.Ref, "refs/heads/")
+	commit, err := webhook.repository.FindCommit(ref)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := scm.NewRepository(webhook.gitRepoURL, webhook.drivers)
+	if err != nil {
+		return nil, err
+	}
+	headers, body, err := repo.CreatePushEvent(scm.PushEvent{
+		Ref:       ref,
+		CommitID:  commit.Sha,
+		Message:   commit.Message,
+		Author:    commit.Author.Name,
+		Timestamp: commit.Author.Date,
+	}, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create push event: %v", err)
+	}
+
+	result, err := deliver(newHTTPClient(o.InsecureSkipTLSVerify), webhook.listenerURL, headers, body)
+	if err != nil {
+		return nil, err
+	}
+	result.Commit = commit.Sha
+	if o.Wait == 0 || !result.Accepted() || result.EventID == "" {
+		return result, nil
+	}
+	client, err := pipelineruns.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	result.PipelineRuns, err = waitForPipelineRuns(func() ([]pipelineruns.Run, error) {
+		return client.ListForEvent(webhook.cicdNamepace, result.EventID)
+	}, o.Wait, pollInterval)
+	return result, err
+}
+
+// deliver posts the event to the EventListener and reads the ID that it
+// assigned to the event from the response.
+func deliver(client *http.Client, listenerURL string, headers http.Header, body []byte) (*DeliveryResult, error) {
+	req, err := http.NewRequest(http.MethodPost, listenerURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = headers
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send event to %s: %w", listenerURL, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from %s: %w", listenerURL, err)
+	}
+
+	result := &DeliveryResult{
+		ListenerURL:  listenerURL,
+		StatusCode:   resp.StatusCode,
+		Response:     strings.TrimSpace(string(respBody)),
+		PipelineRuns: []string{},
+	}
+	var parsed struct {
+		EventID string `json:"eventID"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err == nil {
+		result.EventID = parsed.EventID
+	}
+	return result, nil
+}
+
+// waitForPipelineRuns polls until PipelineRuns are listed or the timeout
+// expires, if none are created the EventListener filtered out the event.
+func waitForPipelineRuns(list func() ([]pipelineruns.Run, error), timeout, interval time.Duration) ([]string, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		runs, err := list()
+		if err != nil {
+			return nil, err
+		}
+		if len(runs) > 0 || !time.Now().Before(deadline) {
+			names := make([]string, len(runs))
+			for i := range runs {
+				names[i] = runs[i].Name
+			}
+			return names, nil
+		}
+		time.Sleep(interval)
+	}
+}
+
+func newHTTPClient(insecureSkipTLSVerify bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if insecureSkipTLSVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &http.Client{Transport: transport, Timeout: deliveryTimeout}
+}
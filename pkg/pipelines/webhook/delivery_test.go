@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/pipelineruns"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+)
+
+func TestDeliver(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Header.Get("X-GitHub-Event") != "push" || !validSignature(r.Header.Get("X-Hub-Signature-256"), body, "testing") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintln(w, `{"eventListener":"cicd-event-listener","namespace":"tst-cicd","eventID":"abcde"}`)
+	}))
+	defer ts.Close()
+	headers, body := makeTestEvent(t, "testing")
+
+	result, err := deliver(ts.Client(), ts.URL, headers, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &DeliveryResult{
+		ListenerURL:  ts.URL,
+		StatusCode:   http.StatusAccepted,
+		Response:     `{"eventListener":"cicd-event-listener","namespace":"tst-cicd","eventID":"abcde"}`,
+		EventID:      "abcde",
+		PipelineRuns: []string{},
+	}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Fatalf("deliver() failed:\n%s", diff)
+	}
+	if !result.Accepted() {
+		t.Fatal("deliver() result was not accepted")
+	}
+}
+
+func TestDeliverWithWrongSecret(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !validSignature(r.Header.Get("X-Hub-Signature-256"), body, "testing") {
+			http.Error(w, "payload signature check failed", http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+	headers, body := makeTestEvent(t, "wrong")
+
+	result, err := deliver(ts.Client(), ts.URL, headers, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Accepted() || result.StatusCode != http.StatusForbidden {
+		t.Fatalf("deliver() got status %d, want %d", result.StatusCode, http.StatusForbidden)
+	}
+	if result.Response != "payload signature check failed" {
+		t.Fatalf("deliver() got response %q", result.Response)
+	}
+}
+
+func TestDeliverWithNoListener(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	listenerURL := ts.URL
+	ts.Close()
+	headers, body := makeTestEvent(t, "testing")
+
+	_, err := deliver(http.DefaultClient, listenerURL, headers, body)
+
+	if err == nil {
+		t.Fatal("expected an error delivering to a closed listener")
+	}
+}
+
+func TestWaitForPipelineRuns(t *testing.T) {
+	calls := 0
+	list := func() ([]pipelineruns.Run, error) {
+		calls++
+		if calls < 3 {
+			return nil, nil
+		}
+		return []pipelineruns.Run{{Name: "app-ci-pipeline-run-abcde"}}, nil
+	}
+
+	names, err := waitForPipelineRuns(list, time.Second, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"app-ci-pipeline-run-abcde"}, names); diff != "" {
+		t.Fatalf("waitForPipelineRuns() failed:\n%s", diff)
+	}
+}
+
+func TestWaitForPipelineRunsTimesOut(t *testing.T) {
+	list := func() ([]pipelineruns.Run, error) {
+		return nil, nil
+	}
+
+	names, err := waitForPipelineRuns(list, 5*time.Millisecond, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(names) != 0 {
+		t.Fatalf("waitForPipelineRuns() got %v, want no PipelineRuns", names)
+	}
+}
+
+func makeTestEvent(t *testing.T, secret string) (http.Header, []byte) {
+	t.Helper()
+	repo, err := scm.NewRepository("https://github.com/org/test.git", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers, body, err := repo.CreatePushEvent(scm.PushEvent{Ref: "main", CommitID: "6113728f27ae82c7b1a177c8d03f9e96e0adf246"}, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return headers, body
+}
+
+func validSignature(signature string, body []byte, secret string) bool {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal([]byte(signature), []byte("sha256="+hex.EncodeToString(mac.Sum(nil))))
+}
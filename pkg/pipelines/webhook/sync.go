@@ -0,0 +1,492 @@
+package webhook
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/accesstoken"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/git"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	kamscm "github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+)
+
+// Action is a change to be made to a webhook.
+type Action string
+
+const (
+	// ActionCreate creates a missing webhook.
+	ActionCreate Action = "create"
+	// ActionUpdate updates an existing webhook.
+	ActionUpdate Action = "update"
+	// ActionDelete deletes a webhook that is no longer needed.
+	ActionDelete Action = "delete"
+
+	// syncStateFile records the repositories that webhooks were synchronised
+	// to, relative to the CI/CD configuration directory, so that webhooks can
+	// be deleted from repositories that are removed from the manifest, and the
+	// webhook of each owner, so that they're matched with the same webhooks.
+	syncStateFile = "webhooks.yaml"
+)
+
+// SyncOptions is the configuration for synchronising webhooks.
+type SyncOptions struct {
+	// AccessToken is used for the repositories on the host of the GitOps
+	// repository, the tokens for the repositories on other hosts are read
+	// from the keyring or the environment.
+	AccessToken         string
+	PipelinesFolderPath string
+	// RemoveFrom is a list of repository URLs that are no longer in the
+	// manifest, kam-owned webhooks are deleted from these.
+	//
+	// Repositories that were previously synchronised are recorded, these
+	// don't need to be provided.
+	RemoveFrom []string
+	// UpdateSecrets updates all the kam-owned webhooks with the current
+	// secret, Git hosts don't return secrets so changes can't be detected.
+	UpdateSecrets bool
+}
+
+// SyncChange is a planned change to a webhook in a repository.
+type SyncChange struct {
+	Action  Action `json:"action"`
+	RepoURL string `json:"repository"`
+	ID      string `json:"id,omitempty"`
+	Owner   string `json:"owner,omitempty"`
+	Reason  string `json:"reason"`
+
+	secret string
+}
+
+// SyncPlan is the set of changes needed to make the webhooks in the
+// repositories match the manifest.
+type SyncPlan struct {
+	ListenerURL string       `json:"listenerURL"`
+	Changes     []SyncChange `json:"changes"`
+
+	repositories map[string]hookRepository
+	// accessTokenHost is the host of the GitOps repository, that the access
+	// token of the syncer is used for.
+	accessTokenHost string
+	fs              afero.Fs
+	statePath       string
+	// synced are the repositories that are recorded once the plan is applied.
+	synced []string
+	// hooks are the webhooks that are unchanged by the plan, the created and
+	// updated webhooks are recorded with them once the plan is applied.
+	hooks []syncedHook
+}
+
+// syncState is the record of the repositories that webhooks were
+// synchronised to, and the webhooks of the owners in them.
+type syncState struct {
+	Repositories []string     `json:"repositories"`
+	Hooks        []syncedHook `json:"hooks,omitempty"`
+}
+
+// syncedHook records the ID of the webhook for an owner in a repository.
+type syncedHook struct {
+	Repository string `json:"repository"`
+	Owner      string `json:"owner"`
+	ID         string `json:"id"`
+}
+
+// hookRepository is the part of git.Repository used to synchronise webhooks.
+type hookRepository interface {
+	ListHooks() ([]*scm.Hook, error)
+	CreateWebhook(listenerURL, secret string) (string, error)
+	UpdateWebhook(id, listenerURL, secret string) (string, error)
+	DeleteWebhooks(ids []string) ([]string, error)
+}
+
+// desiredHook is a webhook that the manifest requires.
+type desiredHook struct {
+	repoURL         string
+	owner           string
+	secretName      string
+	secretNamespace string
+}
+
+type syncer struct {
+	resources           *resources
+	accessToken         string
+	fs                  afero.Fs
+	pipelinesFolderPath string
+	newRepository       func(rawURL, token string) (hookRepository, error)
+}
+
+// PlanSync compares the webhooks in the GitOps repository and every service
+// source repository with the manifest, and returns the changes needed to
+// make them match.
+func PlanSync(o *SyncOptions) (*SyncPlan, error) {
+	fs := ioutils.NewFilesystem()
+	manifest, err := config.LoadManifest(fs, o.PipelinesFolderPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pipelines: %v", err)
+	}
+	clusterResources, err := newResources()
+	if err != nil {
+		return nil, err
+	}
+	s := &syncer{
+		resources:           clusterResources,
+		accessToken:         o.AccessToken,
+		fs:                  fs,
+		pipelinesFolderPath: o.PipelinesFolderPath,
+		newRepository:       newGitRepository(manifest.GetDriverResolver()),
+	}
+	return s.plan(manifest, o.RemoveFrom, o.UpdateSecrets)
+}
+
+// Apply makes the changes in the plan, and records the repositories that
+// the webhooks were synchronised to.
+func (p *SyncPlan) Apply() error {
+	hooks := append([]syncedHook{}, p.hooks...)
+	for i, c := range p.Changes {
+		repo := p.repositories[c.RepoURL]
+		switch c.Action {
+		case ActionCreate:
+			id, err := repo.CreateWebhook(p.ListenerURL, c.secret)
+			if err != nil {
+				return fmt.Errorf("failed to create webhook in %s: %v", c.RepoURL, err)
+			}
+			p.Changes[i].ID = id
+			hooks = append(hooks, syncedHook{Repository: c.RepoURL, Owner: c.Owner, ID: id})
+		case ActionUpdate:
+			id, err := repo.UpdateWebhook(c.ID, p.ListenerURL, c.secret)
+			if err != nil {
+				return fmt.Errorf("failed to update webhook in %s: %v", c.RepoURL, err)
+			}
+			p.Changes[i].ID = id
+			hooks = append(hooks, syncedHook{Repository: c.RepoURL, Owner: c.Owner, ID: id})
+		case ActionDelete:
+			if _, err := repo.DeleteWebhooks([]string{c.ID}); err != nil {
+				return fmt.Errorf("failed to delete webhook in %s: %v", c.RepoURL, err)
+			}
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool {
+		if hooks[i].Repository != hooks[j].Repository {
+			return hooks[i].Repository < hooks[j].Repository
+		}
+		return hooks[i].Owner < hooks[j].Owner
+	})
+	return writeSyncState(p.fs, p.statePath, syncState{Repositories: p.synced, Hooks: hooks})
+}
+
+func (s *syncer) plan(manifest *config.Manifest, removeFrom []string, updateSecrets bool) (*SyncPlan, error) {
+	cfg := manifest.GetPipelinesConfig()
+	if cfg == nil {
+		return nil, fmt.Errorf("failed to find a CI/CD environment in the manifest")
+	}
+	listenerURL, err := getListenerURL(s.resources, cfg.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event listener URL: %v", err)
+	}
+	plan := &SyncPlan{
+		ListenerURL:  listenerURL,
+		Changes:      []SyncChange{},
+		repositories: map[string]hookRepository{},
+		fs:           s.fs,
+		statePath:    filepath.Join(s.pipelinesFolderPath, manifest.GetLayout().PathForPipelines(cfg), syncStateFile),
+	}
+	if manifest.GitOpsURL != "" {
+		plan.accessTokenHost, err = accesstoken.HostFromURL(manifest.GitOpsURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	desired := desiredHooks(manifest, cfg.Name)
+	for _, repoURL := range removeFrom {
+		if _, ok := desired[repoURL]; ok {
+			return nil, fmt.Errorf("repository %s is still used in the manifest", repoURL)
+		}
+	}
+	previous, err := readSyncState(s.fs, plan.statePath)
+	if err != nil {
+		return nil, err
+	}
+	removed := removedRepositories(desired, previous.Repositories, removeFrom)
+	plan.synced = sortedKeys(desired)
+
+	for _, repoURL := range plan.synced {
+		owned, err := s.ownedHooks(plan, repoURL, cfg.Name)
+		if err != nil {
+			return nil, err
+		}
+		hooks := desired[repoURL]
+		matched, unmatched := matchHooks(repoURL, hooks, owned, previous.Hooks)
+		for _, want := range hooks {
+			secret, err := s.resources.getWebhookSecret(want.secretNamespace, want.secretName, eventlisteners.WebhookSecretKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get webhook secret for %s: %v", want.owner, err)
+			}
+			hook, ok := matched[want.owner]
+			if !ok {
+				plan.Changes = append(plan.Changes, SyncChange{Action: ActionCreate, RepoURL: repoURL, Owner: want.owner, Reason: "no webhook delivers to the listener", secret: secret})
+				continue
+			}
+			if reasons := hookDifferences(hook, listenerURL, updateSecrets); len(reasons) > 0 {
+				plan.Changes = append(plan.Changes, SyncChange{Action: ActionUpdate, RepoURL: repoURL, ID: hook.ID, Owner: want.owner, Reason: strings.Join(reasons, ", "), secret: secret})
+				continue
+			}
+			plan.hooks = append(plan.hooks, syncedHook{Repository: repoURL, Owner: want.owner, ID: hook.ID})
+		}
+		for _, extra := range unmatched {
+			plan.Changes = append(plan.Changes, SyncChange{Action: ActionDelete, RepoURL: repoURL, ID: extra.ID, Reason: "more webhooks than services using the repository"})
+		}
+	}
+
+	for _, repoURL := range removed {
+		owned, err := s.ownedHooks(plan, repoURL, cfg.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range owned {
+			plan.Changes = append(plan.Changes, SyncChange{Action: ActionDelete, RepoURL: repoURL, ID: h.ID, Reason: "repository is no longer in the manifest"})
+		}
+	}
+	return plan, nil
+}
+
+// matchHooks matches the owners of the desired webhooks in the repository with
+// the owned webhooks, and returns the owned webhooks that aren't matched.
+//
+// Owners are matched with the webhook that was recorded for them when the
+// webhooks were last synchronised, and the remaining owners with the
+// remaining webhooks in order.
+func matchHooks(repoURL string, desired []desiredHook, owned []*scm.Hook, previous []syncedHook) (map[string]*scm.Hook, []*scm.Hook) {
+	byID := map[string]*scm.Hook{}
+	for _, h := range owned {
+		byID[h.ID] = h
+	}
+	matched := map[string]*scm.Hook{}
+	claimed := map[string]bool{}
+	for _, h := range previous {
+		hook, ok := byID[h.ID]
+		if h.Repository != repoURL || !ok || claimed[h.ID] {
+			continue
+		}
+		matched[h.Owner] = hook
+		claimed[h.ID] = true
+	}
+	unmatched := []*scm.Hook{}
+	for _, h := range owned {
+		if !claimed[h.ID] {
+			unmatched = append(unmatched, h)
+		}
+	}
+	for _, want := range desired {
+		if _, ok := matched[want.owner]; ok || len(unmatched) == 0 {
+			continue
+		}
+		matched[want.owner] = unmatched[0]
+		unmatched = unmatched[1:]
+	}
+	return matched, unmatched
+}
+
+// ownedHooks returns the webhooks in the repository that were created by kam.
+//
+// The webhooks that already deliver to the listener are first, so that they're
+// kept for the services using the repository, and the webhooks that deliver to
+// previous hosts of the listener are updated or deleted.
+func (s *syncer) ownedHooks(plan *SyncPlan, repoURL, cicdNamespace string) ([]*scm.Hook, error) {
+	token, err := s.token(plan, repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to use access-token from keyring/env-var for %s: %v, please pass a valid token to --git-host-access-token", repoURL, err)
+	}
+	repo, err := s.newRepository(repoURL, token)
+	if err != nil {
+		return nil, err
+	}
+	plan.repositories[repoURL] = repo
+	hooks, err := repo.ListHooks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks in %s: %v", repoURL, err)
+	}
+	owned := []*scm.Hook{}
+	for _, h := range hooks {
+		if isOwnedHook(h.Target, plan.ListenerURL, cicdNamespace) {
+			owned = append(owned, h)
+		}
+	}
+	sort.SliceStable(owned, func(i, j int) bool {
+		return owned[i].Target == plan.ListenerURL && owned[j].Target != plan.ListenerURL
+	})
+	return owned, nil
+}
+
+// token returns the access token for the host of the repository, the
+// access token of the syncer is only used for the host of the GitOps
+// repository, as services can be on other Git hosts.
+func (s *syncer) token(plan *SyncPlan, repoURL string) (string, error) {
+	host, err := accesstoken.HostFromURL(repoURL)
+	if err != nil {
+		return "", err
+	}
+	if s.accessToken != "" && (plan.accessTokenHost == "" || plan.accessTokenHost == host) {
+		return s.accessToken, nil
+	}
+	return accesstoken.GetAccessToken(repoURL)
+}
+
+// desiredHooks returns the webhooks that the manifest requires, grouped by
+// repository URL.
+func desiredHooks(m *config.Manifest, cicdNamespace string) map[string][]desiredHook {
+	desired := map[string][]desiredHook{}
+	if m.GitOpsURL != "" {
+		desired[m.GitOpsURL] = append(desired[m.GitOpsURL], desiredHook{
+			repoURL:         m.GitOpsURL,
+			owner:           "cicd",
+			secretName:      eventlisteners.GitOpsWebhookSecret,
+			secretNamespace: cicdNamespace,
+		})
+	}
+	for _, env := range m.Environments {
+		for _, app := range env.Apps {
+			for _, svc := range app.Services {
+				if svc.SourceURL == "" {
+					continue
+				}
+				hook := desiredHook{
+					repoURL:         svc.SourceURL,
+					owner:           env.Name + "/" + svc.Name,
+					secretName:      secrets.MakeServiceWebhookSecretName(env.Name, svc.Name),
+					secretNamespace: cicdNamespace,
+				}
+				if svc.Webhook != nil && svc.Webhook.Secret != nil {
+					hook.secretName = svc.Webhook.Secret.Name
+					if svc.Webhook.Secret.Namespace != "" {
+						hook.secretNamespace = svc.Webhook.Secret.Namespace
+					}
+				}
+				desired[svc.SourceURL] = append(desired[svc.SourceURL], hook)
+			}
+		}
+	}
+	for _, hooks := range desired {
+		sort.Slice(hooks, func(i, j int) bool {
+			return hooks[i].owner < hooks[j].owner
+		})
+	}
+	return desired
+}
+
+// isOwnedHook returns true if the webhook target is the listener, or is a
+// previous host of the listener route in the CI/CD namespace.
+func isOwnedHook(target, listenerURL, cicdNamespace string) bool {
+	if target == listenerURL {
+		return true
+	}
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(parsed.Hostname(), eventlisteners.GitOpsWebhookEventListenerRouteName+"-"+cicdNamespace+".")
+}
+
+// hookDifferences returns the reasons that the webhook needs updating.
+func hookDifferences(hook *scm.Hook, listenerURL string, updateSecret bool) []string {
+	reasons := []string{}
+	if hook.Target != listenerURL {
+		reasons = append(reasons, fmt.Sprintf("target is %s", hook.Target))
+	}
+	// Drivers that don't report events can't be compared.
+	if len(hook.Events) > 0 && !hasRequiredEvents(hook.Events) {
+		reasons = append(reasons, fmt.Sprintf("events are %s", strings.Join(hook.Events, ", ")))
+	}
+	if updateSecret {
+		reasons = append(reasons, "secret update requested")
+	}
+	return reasons
+}
+
+// hasRequiredEvents returns true if the events include push and pull (or
+// merge) requests, the names vary between Git hosts.
+func hasRequiredEvents(events []string) bool {
+	push, pullRequest := false, false
+	for _, e := range events {
+		switch e {
+		case "push":
+			push = true
+		case "pull_request", "merge":
+			pullRequest = true
+		}
+	}
+	return push && pullRequest
+}
+
+func newGitRepository(drivers *kamscm.DriverResolver) func(rawURL, token string) (hookRepository, error) {
+	return func(rawURL, token string) (hookRepository, error) {
+		return git.NewRepository(rawURL, token, drivers)
+	}
+}
+
+// removedRepositories returns the repositories that webhooks were previously
+// synchronised to, or that were explicitly provided, that are no longer in
+// the manifest.
+func removedRepositories(desired map[string][]desiredHook, previous, removeFrom []string) []string {
+	removed := []string{}
+	seen := map[string]bool{}
+	for _, repoURL := range append(previous, removeFrom...) {
+		if _, ok := desired[repoURL]; ok || seen[repoURL] {
+			continue
+		}
+		seen[repoURL] = true
+		removed = append(removed, repoURL)
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// readSyncState returns the repositories and webhooks that were previously
+// synchronised, there are none if the state hasn't been recorded.
+func readSyncState(fs afero.Fs, path string) (*syncState, error) {
+	state := &syncState{}
+	exists, err := afero.Exists(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return state, nil
+	}
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if err := yaml.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return state, nil
+}
+
+func writeSyncState(fs afero.Fs, path string, state syncState) error {
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := afero.WriteFile(fs, path, data, 0644); err != nil {
+		return fmt.Errorf("failed to record synchronised repositories in %s: %v", path, err)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string][]desiredHook) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,395 @@
+package webhook
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenkins-x/go-scm/scm"
+	routev1 "github.com/openshift/api/route/v1"
+	fakeRouteClientset "github.com/openshift/client-go/route/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeKubeClientset "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/test"
+)
+
+const (
+	testGitOpsURL     = "https://github.com/example/gitops.git"
+	testServiceURL    = "https://github.com/example/taxi.git"
+	testRemovedURL    = "https://github.com/example/removed.git"
+	testListenerURL   = "https://gitops-webhook-event-listener-route-tst-cicd.apps.new.example.com"
+	testStaleURL      = "https://gitops-webhook-event-listener-route-tst-cicd.apps.old.example.com"
+	testSyncStatePath = "/pipelines/config/tst-cicd/webhooks.yaml"
+)
+
+func TestPlanSync(t *testing.T) {
+	repos := map[string]*fakeHookRepository{
+		testGitOpsURL: newFakeHookRepository(
+			&scm.Hook{ID: "1", Target: testListenerURL, Events: []string{"push", "pull_request"}},
+			&scm.Hook{ID: "2", Target: "https://ci.example.com/hook", Events: []string{"push"}},
+		),
+		testServiceURL: newFakeHookRepository(
+			&scm.Hook{ID: "3", Target: testStaleURL, Events: []string{"push", "pull_request"}},
+			&scm.Hook{ID: "4", Target: testListenerURL, Events: []string{"push"}},
+			&scm.Hook{ID: "5", Target: testStaleURL},
+		),
+		testRemovedURL: newFakeHookRepository(
+			&scm.Hook{ID: "6", Target: testStaleURL},
+		),
+	}
+	s := makeSyncer(repos)
+
+	plan, err := s.plan(testSyncManifest(), []string{testRemovedURL}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []SyncChange{
+		{Action: ActionUpdate, RepoURL: testServiceURL, ID: "4", Owner: "dev/taxi", Reason: "events are push", secret: "dev-taxi-secret"},
+		{Action: ActionUpdate, RepoURL: testServiceURL, ID: "3", Owner: "stage/taxi", Reason: "target is " + testStaleURL, secret: "stage-taxi-secret"},
+		{Action: ActionDelete, RepoURL: testServiceURL, ID: "5", Reason: "more webhooks than services using the repository"},
+		{Action: ActionDelete, RepoURL: testRemovedURL, ID: "6", Reason: "repository is no longer in the manifest"},
+	}
+	if diff := cmp.Diff(want, plan.Changes, cmp.AllowUnexported(SyncChange{})); diff != "" {
+		t.Fatalf("plan() failed:\n%s", diff)
+	}
+}
+
+func TestPlanSyncCreatesMissingHooks(t *testing.T) {
+	repos := map[string]*fakeHookRepository{
+		testGitOpsURL:  newFakeHookRepository(),
+		testServiceURL: newFakeHookRepository(&scm.Hook{ID: "1", Target: testListenerURL}),
+	}
+	s := makeSyncer(repos)
+
+	plan, err := s.plan(testSyncManifest(), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []SyncChange{
+		{Action: ActionCreate, RepoURL: testGitOpsURL, Owner: "cicd", Reason: "no webhook delivers to the listener", secret: "gitops-secret"},
+		{Action: ActionUpdate, RepoURL: testServiceURL, ID: "1", Owner: "dev/taxi", Reason: "secret update requested", secret: "dev-taxi-secret"},
+		{Action: ActionCreate, RepoURL: testServiceURL, Owner: "stage/taxi", Reason: "no webhook delivers to the listener", secret: "stage-taxi-secret"},
+	}
+	if diff := cmp.Diff(want, plan.Changes, cmp.AllowUnexported(SyncChange{})); diff != "" {
+		t.Fatalf("plan() failed:\n%s", diff)
+	}
+}
+
+func TestPlanSyncUsesTokensForEachHost(t *testing.T) {
+	gitLabURL := "https://gitlab.com/example/bus.git"
+	defer os.Unsetenv("GITLAB_COM_TOKEN")
+	os.Setenv("GITLAB_COM_TOKEN", "gitlab-token")
+
+	repos := map[string]*fakeHookRepository{
+		testGitOpsURL:  newFakeHookRepository(),
+		testServiceURL: newFakeHookRepository(),
+		gitLabURL:      newFakeHookRepository(),
+	}
+	s := makeSyncer(repos)
+	tokens := map[string]string{}
+	newRepository := s.newRepository
+	s.newRepository = func(rawURL, token string) (hookRepository, error) {
+		tokens[rawURL] = token
+		return newRepository(rawURL, token)
+	}
+	m := testSyncManifest()
+	m.Environments[1].Apps[0].Services[0].SourceURL = gitLabURL
+
+	_, err := s.plan(m, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		testGitOpsURL:  "test-token",
+		testServiceURL: "test-token",
+		gitLabURL:      "gitlab-token",
+	}
+	if diff := cmp.Diff(want, tokens); diff != "" {
+		t.Fatalf("plan() used the wrong tokens:\n%s", diff)
+	}
+}
+
+func TestApplySyncPlan(t *testing.T) {
+	repos := map[string]*fakeHookRepository{
+		testGitOpsURL: newFakeHookRepository(),
+		testServiceURL: newFakeHookRepository(
+			&scm.Hook{ID: "3", Target: testStaleURL},
+			&scm.Hook{ID: "4", Target: testListenerURL},
+			&scm.Hook{ID: "5", Target: testStaleURL},
+		),
+	}
+	s := makeSyncer(repos)
+	plan, err := s.plan(testSyncManifest(), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := plan.Apply(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]fakeHook{
+		testGitOpsURL:  {{target: testListenerURL, secret: "gitops-secret"}},
+		testServiceURL: {{target: testListenerURL}, {target: testListenerURL, secret: "stage-taxi-secret"}},
+	}
+	for repoURL, hooks := range want {
+		if diff := cmp.Diff(hooks, repos[repoURL].hooks(), cmp.AllowUnexported(fakeHook{}), cmpopts.SortSlices(func(a, b fakeHook) bool { return a.secret < b.secret })); diff != "" {
+			t.Errorf("Apply() failed for %s:\n%s", repoURL, diff)
+		}
+	}
+	synced, err := readSyncState(s.fs, testSyncStatePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantState := &syncState{
+		Repositories: []string{testGitOpsURL, testServiceURL},
+		Hooks: []syncedHook{
+			{Repository: testGitOpsURL, Owner: "cicd", ID: "101"},
+			{Repository: testServiceURL, Owner: "dev/taxi", ID: "4"},
+			{Repository: testServiceURL, Owner: "stage/taxi", ID: "3"},
+		},
+	}
+	if diff := cmp.Diff(wantState, synced); diff != "" {
+		t.Fatalf("Apply() recorded the wrong state:\n%s", diff)
+	}
+}
+
+func TestPlanSyncMatchesRecordedHooks(t *testing.T) {
+	repos := map[string]*fakeHookRepository{
+		testGitOpsURL: newFakeHookRepository(&scm.Hook{ID: "1", Target: testListenerURL}),
+		testServiceURL: newFakeHookRepository(
+			&scm.Hook{ID: "2", Target: testListenerURL},
+			&scm.Hook{ID: "3", Target: testListenerURL},
+			&scm.Hook{ID: "4", Target: testStaleURL},
+		),
+	}
+	s := makeSyncer(repos)
+	state := syncState{
+		Repositories: []string{testGitOpsURL, testServiceURL},
+		Hooks: []syncedHook{
+			{Repository: testGitOpsURL, Owner: "cicd", ID: "1"},
+			{Repository: testServiceURL, Owner: "dev/taxi", ID: "4"},
+			{Repository: testServiceURL, Owner: "stage/taxi", ID: "3"},
+		},
+	}
+	if err := writeSyncState(s.fs, testSyncStatePath, state); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := s.plan(testSyncManifest(), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []SyncChange{
+		{Action: ActionUpdate, RepoURL: testServiceURL, ID: "4", Owner: "dev/taxi", Reason: "target is " + testStaleURL, secret: "dev-taxi-secret"},
+		{Action: ActionDelete, RepoURL: testServiceURL, ID: "2", Reason: "more webhooks than services using the repository"},
+	}
+	if diff := cmp.Diff(want, plan.Changes, cmp.AllowUnexported(SyncChange{})); diff != "" {
+		t.Fatalf("plan() failed:\n%s", diff)
+	}
+}
+
+func TestPlanSyncRemovesPreviouslySyncedRepositories(t *testing.T) {
+	repos := map[string]*fakeHookRepository{
+		testGitOpsURL:  newFakeHookRepository(&scm.Hook{ID: "1", Target: testListenerURL}),
+		testServiceURL: newFakeHookRepository(&scm.Hook{ID: "2", Target: testListenerURL}, &scm.Hook{ID: "3", Target: testListenerURL}),
+		testRemovedURL: newFakeHookRepository(&scm.Hook{ID: "4", Target: testListenerURL}, &scm.Hook{ID: "5", Target: "https://ci.example.com/hook"}),
+	}
+	s := makeSyncer(repos)
+	if err := writeSyncState(s.fs, testSyncStatePath, syncState{Repositories: []string{testGitOpsURL, testRemovedURL, testServiceURL}}); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := s.plan(testSyncManifest(), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []SyncChange{
+		{Action: ActionDelete, RepoURL: testRemovedURL, ID: "4", Reason: "repository is no longer in the manifest"},
+	}
+	if diff := cmp.Diff(want, plan.Changes, cmp.AllowUnexported(SyncChange{})); diff != "" {
+		t.Fatalf("plan() failed:\n%s", diff)
+	}
+}
+
+func TestPlanSyncWithRemovedRepositoryInManifest(t *testing.T) {
+	s := makeSyncer(map[string]*fakeHookRepository{})
+
+	_, err := s.plan(testSyncManifest(), []string{testServiceURL}, false)
+
+	test.AssertErrorMatch(t, "repository .* is still used in the manifest", err)
+}
+
+func TestIsOwnedHook(t *testing.T) {
+	ownedTests := []struct {
+		target string
+		want   bool
+	}{
+		{testListenerURL, true},
+		{testStaleURL, true},
+		{"http://gitops-webhook-event-listener-route-tst-cicd.apps.example.com/", true},
+		{"https://gitops-webhook-event-listener-route-other-cicd.apps.example.com", false},
+		{"https://ci.example.com/hook", false},
+	}
+
+	for _, tt := range ownedTests {
+		if got := isOwnedHook(tt.target, testListenerURL, "tst-cicd"); got != tt.want {
+			t.Errorf("isOwnedHook(%q) got %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestHasRequiredEvents(t *testing.T) {
+	eventTests := []struct {
+		events []string
+		want   bool
+	}{
+		{[]string{"push", "pull_request"}, true},
+		{[]string{"merge", "push", "tag"}, true},
+		{[]string{"push"}, false},
+		{[]string{"pull_request"}, false},
+	}
+
+	for _, tt := range eventTests {
+		if got := hasRequiredEvents(tt.events); got != tt.want {
+			t.Errorf("hasRequiredEvents(%v) got %v, want %v", tt.events, got, tt.want)
+		}
+	}
+}
+
+type fakeHook struct {
+	target string
+	secret string
+}
+
+type fakeHookRepository struct {
+	nextID  int
+	scmHook []*scm.Hook
+	secrets map[string]string
+}
+
+func newFakeHookRepository(hooks ...*scm.Hook) *fakeHookRepository {
+	return &fakeHookRepository{nextID: 100, scmHook: hooks, secrets: map[string]string{}}
+}
+
+func (r *fakeHookRepository) ListHooks() ([]*scm.Hook, error) {
+	return r.scmHook, nil
+}
+
+func (r *fakeHookRepository) CreateWebhook(listenerURL, secret string) (string, error) {
+	r.nextID++
+	id := fmt.Sprint(r.nextID)
+	r.scmHook = append(r.scmHook, &scm.Hook{ID: id, Target: listenerURL, Events: []string{"push", "pull_request"}})
+	r.secrets[id] = secret
+	return id, nil
+}
+
+func (r *fakeHookRepository) UpdateWebhook(id, listenerURL, secret string) (string, error) {
+	for _, h := range r.scmHook {
+		if h.ID == id {
+			h.Target = listenerURL
+			h.Events = []string{"push", "pull_request"}
+			r.secrets[id] = secret
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("unknown webhook %s", id)
+}
+
+func (r *fakeHookRepository) DeleteWebhooks(ids []string) ([]string, error) {
+	for _, id := range ids {
+		for i, h := range r.scmHook {
+			if h.ID == id {
+				r.scmHook = append(r.scmHook[:i], r.scmHook[i+1:]...)
+				break
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (r *fakeHookRepository) hooks() []fakeHook {
+	hooks := []fakeHook{}
+	for _, h := range r.scmHook {
+		hooks = append(hooks, fakeHook{target: h.Target, secret: r.secrets[h.ID]})
+	}
+	return hooks
+}
+
+func makeSyncer(repos map[string]*fakeHookRepository) *syncer {
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gitops-webhook-event-listener-route",
+			Namespace: testNamespace,
+		},
+		Spec: routev1.RouteSpec{
+			Host: "gitops-webhook-event-listener-route-tst-cicd.apps.new.example.com",
+			TLS:  &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge},
+		},
+	}
+	secret := func(name, value string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+			Data:       map[string][]byte{"webhook-secret-key": []byte(value)},
+		}
+	}
+	return &syncer{
+		resources: fakeNewResources(
+			fakeRouteClientset.NewSimpleClientset(route).RouteV1(),
+			fakeKubeClientset.NewSimpleClientset(
+				secret("gitops-webhook-secret", "gitops-secret"),
+				secret("webhook-secret-dev-taxi", "dev-taxi-secret"),
+				secret("webhook-secret-stage-taxi", "stage-taxi-secret"),
+			)),
+		accessToken:         "test-token",
+		fs:                  ioutils.NewMemoryFilesystem(),
+		pipelinesFolderPath: "/pipelines",
+		newRepository: func(rawURL, token string) (hookRepository, error) {
+			r, ok := repos[rawURL]
+			if !ok {
+				return nil, fmt.Errorf("unknown repository %s", rawURL)
+			}
+			return r, nil
+		},
+	}
+}
+
+func testSyncManifest() *config.Manifest {
+	taxi := func(env string) *config.Environment {
+		return &config.Environment{
+			Name: env,
+			Apps: []*config.Application{
+				{
+					Name: "app-taxi",
+					Services: []*config.Service{
+						{
+							Name:      "taxi",
+							SourceURL: testServiceURL,
+							Webhook: &config.Webhook{
+								Secret: &config.Secret{Name: "webhook-secret-" + env + "-taxi", Namespace: testNamespace},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	return &config.Manifest{
+		GitOpsURL: testGitOpsURL,
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: testNamespace},
+		},
+		Environments: []*config.Environment{taxi("dev"), taxi("stage")},
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
 	"github.com/redhat-developer/kam/pkg/pipelines/git"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
 )
 
@@ -16,6 +17,7 @@ type webhookInfo struct {
 	clusterResource *resources
 	repository      *git.Repository
 	gitRepoURL      string
+	drivers         *scm.DriverResolver
 	cicdNamepace    string
 	listenerURL     string
 	accessToken     string
@@ -75,6 +77,52 @@ func List(accessToken, pipelinesFile string, serviceName *QualifiedServiceName,
 	return webhook.list()
 }
 
+// UpdateSecret updates the webhooks on the target Git Repository that match
+// the listener address to use a new secret.
+// It returns the IDs of the updated webhooks.
+func UpdateSecret(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool, secret string) ([]string, error) {
+	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, isCICD)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := webhook.list()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("no webhook found for the event listener, create one with 'kam webhook create'")
+	}
+
+	updated := []string{}
+	for _, id := range ids {
+		newID, err := webhook.repository.UpdateWebhook(id, webhook.listenerURL, secret)
+		if err != nil {
+			return updated, err
+		}
+		updated = append(updated, newID)
+	}
+	return updated, nil
+}
+
+// CurrentSecret returns the webhook secret that is currently used on the
+// cluster for the GitOps repository or the service.
+func CurrentSecret(pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) (string, error) {
+	manifest, err := config.LoadManifest(ioutils.NewFilesystem(), pipelinesFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse pipelines: %v", err)
+	}
+	cfg := manifest.GetPipelinesConfig()
+	if cfg == nil {
+		return "", errors.New("failed to find a CI/CD environment in the manifest")
+	}
+	clusterResources, err := newResources()
+	if err != nil {
+		return "", err
+	}
+	return getWebhookSecret(clusterResources, cfg.Name, isCICD, serviceName)
+}
+
 func newWebhookInfo(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, isCICD bool) (*webhookInfo, error) {
 	manifest, err := config.LoadManifest(ioutils.NewFilesystem(), pipelinesFile)
 	if err != nil {
@@ -107,11 +155,12 @@ func newWebhookInfo(accessToken, pipelinesFile string, serviceName *QualifiedSer
 			return nil, fmt.Errorf("unable to use access-token from keyring/env-var: %v, please pass a valid token to --save-token-keyring", err)
 		}
 	}
-	repository, err := git.NewRepository(gitRepoURL, accessToken)
+	drivers := manifest.GetDriverResolver()
+	repository, err := git.NewRepository(gitRepoURL, accessToken, drivers)
 	if err != nil {
 		return nil, err
 	}
-	return &webhookInfo{clusterResources, repository, gitRepoURL, cicdNamepace, listenerURL, accessToken, serviceName, isCICD}, nil
+	return &webhookInfo{clusterResources, repository, gitRepoURL, drivers, cicdNamepace, listenerURL, accessToken, serviceName, isCICD}, nil
 }
 
 func (w *webhookInfo) exists() (bool, error) {
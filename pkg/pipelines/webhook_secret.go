@@ -0,0 +1,155 @@
+package pipelines
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+// RotateWebhookSecretOptions control how webhook secrets are rotated.
+type RotateWebhookSecretOptions struct {
+	PipelinesFolderPath string
+	EnvName             string
+	ServiceName         string
+	IsCICD              bool
+	// PreviousSecret is the secret being replaced, if provided, hooks signed
+	// with it are accepted until it's dropped.
+	PreviousSecret string
+}
+
+// RotateWebhookSecret generates a new webhook secret for the GitOps repository
+// or a service, and writes it to the secrets folder.
+//
+// It returns the new secret, so that the webhooks can be updated.
+func RotateWebhookSecret(o *RotateWebhookSecretOptions, appFs afero.Fs) (string, error) {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return "", err
+	}
+	hook, current, err := webhookForRotation(m, o.EnvName, o.ServiceName, o.IsCICD)
+	if err != nil {
+		return "", err
+	}
+	newSecret, err := secrets.GenerateString(webhookSecretLength)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %v", err)
+	}
+
+	otherResources := res.Resources{}
+	if err := addWebhookSecret(otherResources, current, newSecret); err != nil {
+		return "", err
+	}
+	hook.PreviousSecret = nil
+	if o.PreviousSecret != "" {
+		previous := &config.Secret{Name: current.Name + "-previous", Namespace: current.Namespace}
+		if err := addWebhookSecret(otherResources, previous, o.PreviousSecret); err != nil {
+			return "", err
+		}
+		hook.PreviousSecret = previous
+	}
+	if o.IsCICD && hook.PreviousSecret == nil {
+		m.Config.Pipelines.Webhook = nil
+	}
+
+	if _, err := writeManifestAndResources(m, appFs, o.PipelinesFolderPath); err != nil {
+		return "", err
+	}
+	_, err = yaml.WriteResources(appFs, filepath.Join(o.PipelinesFolderPath, ".."), otherResources) // Don't call filepath.ToSlash
+	if err != nil {
+		return "", err
+	}
+	return newSecret, nil
+}
+
+// DropPreviousWebhookSecret stops accepting hooks signed with the previous
+// secret of the GitOps repository or a service.
+func DropPreviousWebhookSecret(o *RotateWebhookSecretOptions, appFs afero.Fs) error {
+	m, err := config.LoadManifest(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	hook, _, err := webhookForRotation(m, o.EnvName, o.ServiceName, o.IsCICD)
+	if err != nil {
+		return err
+	}
+	if hook.PreviousSecret == nil {
+		return fmt.Errorf("no previous webhook secret to drop")
+	}
+	hook.PreviousSecret = nil
+	if o.IsCICD {
+		m.Config.Pipelines.Webhook = nil
+	}
+	_, err = writeManifestAndResources(m, appFs, o.PipelinesFolderPath)
+	return err
+}
+
+// webhookForRotation returns the webhook configuration to change in the
+// manifest, and the current secret for the webhook.
+func webhookForRotation(m *config.Manifest, envName, serviceName string, isCICD bool) (*config.Webhook, *config.Secret, error) {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return nil, nil, fmt.Errorf("failed to find a CI/CD environment in the manifest")
+	}
+	if isCICD {
+		if cfg.Webhook == nil {
+			cfg.Webhook = &config.Webhook{}
+		}
+		return cfg.Webhook, &config.Secret{Name: eventlisteners.GitOpsWebhookSecret, Namespace: cfg.Name}, nil
+	}
+	env := m.GetEnvironment(envName)
+	if env == nil {
+		return nil, nil, fmt.Errorf("environment %s does not exist", envName)
+	}
+	for _, app := range env.Apps {
+		for _, svc := range app.Services {
+			if svc.Name != serviceName {
+				continue
+			}
+			if svc.SourceURL == "" {
+				return nil, nil, fmt.Errorf("service %s in environment %s has no source_url, so it has no webhook", serviceName, envName)
+			}
+			if svc.Webhook == nil || svc.Webhook.Secret == nil {
+				svc.Webhook = &config.Webhook{
+					Secret: &config.Secret{
+						Name:      secrets.MakeServiceWebhookSecretName(envName, serviceName),
+						Namespace: cfg.Name,
+					},
+				}
+			}
+			return svc.Webhook, svc.Webhook.Secret, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("service %s does not exist in environment %s", serviceName, envName)
+}
+
+func addWebhookSecret(files res.Resources, name *config.Secret, value string) error {
+	secret, err := secrets.CreateUnsealedSecret(meta.NamespacedName(name.Namespace, name.Name), value, eventlisteners.WebhookSecretKey)
+	if err != nil {
+		return err
+	}
+	files[filepath.ToSlash(filepath.Join("secrets", name.Name+".yaml"))] = secret
+	return nil
+}
+
+// writeManifestAndResources writes the manifest, and the resources built from
+// it, and returns the paths of the written files.
+func writeManifestAndResources(m *config.Manifest, appFs afero.Fs, pipelinesFolderPath string) ([]string, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	files := res.Resources{}
+	files[filepath.Base(filepath.Join(pipelinesFolderPath, pipelinesFile))] = m // Don't call filepath.ToSlash
+	built, err := buildResources(appFs, m, pipelinesFolderPath)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.WriteResources(appFs, pipelinesFolderPath, res.Merge(built, files))
+}
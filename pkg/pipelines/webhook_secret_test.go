@@ -0,0 +1,166 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestRotateWebhookSecretForService(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	outputPath := writeTestManifest(t, fakeFs, buildManifest(true, true))
+
+	secret, err := RotateWebhookSecret(&RotateWebhookSecretOptions{
+		PipelinesFolderPath: outputPath,
+		EnvName:             "test-dev",
+		ServiceName:         "test-svc",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	if l := len(secret); l != webhookSecretLength {
+		t.Fatalf("got a secret of length %d, want %d", l, webhookSecretLength)
+	}
+	assertWebhookSecret(t, fakeFs, filepath.Join(outputPath, "..", "secrets", "webhook-secret-test-dev-test-svc.yaml"), secret)
+	m := readTestManifest(t, fakeFs, outputPath)
+	want := &config.Webhook{
+		Secret: &config.Secret{Name: "webhook-secret-test-dev-test-svc", Namespace: "cicd"},
+	}
+	if diff := cmp.Diff(want, m.Environments[0].Apps[0].Services[0].Webhook); diff != "" {
+		t.Fatalf("webhook configuration didn't match:\n%s", diff)
+	}
+}
+
+func TestRotateWebhookSecretKeepingPrevious(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	outputPath := writeTestManifest(t, fakeFs, buildManifest(true, true))
+
+	secret, err := RotateWebhookSecret(&RotateWebhookSecretOptions{
+		PipelinesFolderPath: outputPath,
+		EnvName:             "test-dev",
+		ServiceName:         "test-svc",
+		PreviousSecret:      "old-secret",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	assertWebhookSecret(t, fakeFs, filepath.Join(outputPath, "..", "secrets", "webhook-secret-test-dev-test-svc.yaml"), secret)
+	assertWebhookSecret(t, fakeFs, filepath.Join(outputPath, "..", "secrets", "webhook-secret-test-dev-test-svc-previous.yaml"), "old-secret")
+	m := readTestManifest(t, fakeFs, outputPath)
+	want := &config.Webhook{
+		Secret:         &config.Secret{Name: "webhook-secret-test-dev-test-svc", Namespace: "cicd"},
+		PreviousSecret: &config.Secret{Name: "webhook-secret-test-dev-test-svc-previous", Namespace: "cicd"},
+	}
+	if diff := cmp.Diff(want, m.Environments[0].Apps[0].Services[0].Webhook); diff != "" {
+		t.Fatalf("webhook configuration didn't match:\n%s", diff)
+	}
+
+	err = DropPreviousWebhookSecret(&RotateWebhookSecretOptions{
+		PipelinesFolderPath: outputPath,
+		EnvName:             "test-dev",
+		ServiceName:         "test-svc",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	m = readTestManifest(t, fakeFs, outputPath)
+	if m.Environments[0].Apps[0].Services[0].Webhook.PreviousSecret != nil {
+		t.Fatal("previous secret was not dropped")
+	}
+}
+
+func TestRotateWebhookSecretForCICD(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	outputPath := writeTestManifest(t, fakeFs, buildManifest(true, true))
+
+	secret, err := RotateWebhookSecret(&RotateWebhookSecretOptions{
+		PipelinesFolderPath: outputPath,
+		IsCICD:              true,
+		PreviousSecret:      "old-secret",
+	}, fakeFs)
+	assertNoError(t, err)
+
+	assertWebhookSecret(t, fakeFs, filepath.Join(outputPath, "..", "secrets", "gitops-webhook-secret.yaml"), secret)
+	assertWebhookSecret(t, fakeFs, filepath.Join(outputPath, "..", "secrets", "gitops-webhook-secret-previous.yaml"), "old-secret")
+	m := readTestManifest(t, fakeFs, outputPath)
+	want := &config.Webhook{
+		PreviousSecret: &config.Secret{Name: "gitops-webhook-secret-previous", Namespace: "cicd"},
+	}
+	if diff := cmp.Diff(want, m.Config.Pipelines.Webhook); diff != "" {
+		t.Fatalf("webhook configuration didn't match:\n%s", diff)
+	}
+
+	err = DropPreviousWebhookSecret(&RotateWebhookSecretOptions{PipelinesFolderPath: outputPath, IsCICD: true}, fakeFs)
+	assertNoError(t, err)
+
+	m = readTestManifest(t, fakeFs, outputPath)
+	if m.Config.Pipelines.Webhook != nil {
+		t.Fatalf("previous secret was not dropped: %#v", m.Config.Pipelines.Webhook)
+	}
+}
+
+func TestRotateWebhookSecretErrors(t *testing.T) {
+	errorTests := []struct {
+		desc    string
+		options *RotateWebhookSecretOptions
+		wantErr string
+	}{
+		{"missing environment", &RotateWebhookSecretOptions{EnvName: "test-stage", ServiceName: "test-svc"}, "environment test-stage does not exist"},
+		{"missing service", &RotateWebhookSecretOptions{EnvName: "test-dev", ServiceName: "unknown"}, "service unknown does not exist in environment test-dev"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.desc, func(t *testing.T) {
+			fakeFs := ioutils.NewMemoryFilesystem()
+			tt.options.PipelinesFolderPath = writeTestManifest(t, fakeFs, buildManifest(true, true))
+
+			_, err := RotateWebhookSecret(tt.options, fakeFs)
+			test.AssertErrorMatch(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestDropPreviousWebhookSecretWithNoPreviousSecret(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	outputPath := writeTestManifest(t, fakeFs, buildManifest(true, true))
+
+	err := DropPreviousWebhookSecret(&RotateWebhookSecretOptions{
+		PipelinesFolderPath: outputPath,
+		EnvName:             "test-dev",
+		ServiceName:         "test-svc",
+	}, fakeFs)
+
+	test.AssertErrorMatch(t, "no previous webhook secret to drop", err)
+}
+
+func writeTestManifest(t *testing.T, fs afero.Fs, m *config.Manifest) string {
+	t.Helper()
+	outputPath := afero.GetTempDir(fs, "test")
+	b, err := yaml.Marshal(m)
+	assertNoError(t, err)
+	assertNoError(t, afero.WriteFile(fs, filepath.Join(outputPath, pipelinesFile), b, 0644))
+	return outputPath
+}
+
+func readTestManifest(t *testing.T, fs afero.Fs, outputPath string) *config.Manifest {
+	t.Helper()
+	m, err := config.ParsePipelinesFolder(fs, outputPath)
+	assertNoError(t, err)
+	return m
+}
+
+func assertWebhookSecret(t *testing.T, fs afero.Fs, filename, want string) {
+	t.Helper()
+	b, err := afero.ReadFile(fs, filename)
+	assertNoError(t, err)
+	secret := corev1.Secret{}
+	assertNoError(t, yaml.Unmarshal(b, &secret))
+	if got := string(secret.Data["webhook-secret-key"]); got != want {
+		t.Fatalf("secret in %s got %q, want %q", filename, got, want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"sort"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/afero"
@@ -14,15 +15,16 @@ import (
 // marshal the values to the filenames as YAML resources, joining the prefix to
 // the filenames before writing.
 //
-// It returns the list of filenames written out.
+// The files are written in order of their filenames, and it returns the sorted
+// list of filenames written out.
 func WriteResources(fs afero.Fs, path string, files map[string]interface{}) ([]string, error) {
 	path, err := homedir.Expand(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve path to file: %v", err)
 	}
 	filenames := make([]string, 0)
-	for filename, item := range files {
-		err := MarshalItemToFile(fs, filepath.Join(path, filename), item)
+	for _, filename := range sortedKeys(files) {
+		err := MarshalItemToFile(fs, filepath.Join(path, filename), files[filename])
 		if err != nil {
 			return nil, err
 		}
@@ -31,6 +33,15 @@ func WriteResources(fs afero.Fs, path string, files map[string]interface{}) ([]s
 	return filenames, nil
 }
 
+func sortedKeys(files map[string]interface{}) []string {
+	keys := []string{}
+	for k := range files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // MarshalItemToFile marshals item to file
 func MarshalItemToFile(fs afero.Fs, filename string, item interface{}) error {
 	err := fs.MkdirAll(filepath.Dir(filename), 0755)
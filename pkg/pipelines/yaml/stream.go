@@ -0,0 +1,93 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// PathAnnotation records the path that a resource would be written to,
+	// when the resources are written as a stream.
+	PathAnnotation = "kam.openshift.io/path"
+
+	// YAMLFormat writes the resources as a multi-document YAML stream.
+	YAMLFormat = "yaml"
+	// JSONFormat writes the resources as a JSON List.
+	JSONFormat = "json"
+)
+
+// WriteResourceStream writes the resources to the writer as a single stream,
+// sorted by path, in the format, with the path of each resource recorded in
+// the PathAnnotation.
+func WriteResourceStream(out io.Writer, files map[string]interface{}, format string) error {
+	items, err := annotatedItems(files)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case JSONFormat:
+		list := map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      items,
+		}
+		b, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal data: %v", err)
+		}
+		_, err = fmt.Fprintf(out, "%s\n", b)
+		return err
+	case YAMLFormat:
+		for i, item := range items {
+			if i > 0 {
+				if _, err := fmt.Fprintln(out, "---"); err != nil {
+					return fmt.Errorf("failed to write data: %v", err)
+				}
+			}
+			if err := MarshalOutput(out, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported format %q, must be one of %s or %s", format, YAMLFormat, JSONFormat)
+}
+
+// annotatedItems converts the resources to unstructured values, sorted by
+// path, and annotates the values that are objects with their path.
+func annotatedItems(files map[string]interface{}) ([]interface{}, error) {
+	items := []interface{}{}
+	for _, path := range sortedKeys(files) {
+		b, err := yaml.Marshal(files[path])
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %v", path, err)
+		}
+		var item interface{}
+		if err := yaml.Unmarshal(b, &item); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %v", path, err)
+		}
+		if obj, ok := item.(map[string]interface{}); ok {
+			annotate(obj, path)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func annotate(obj map[string]interface{}, path string) {
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+		obj["metadata"] = metadata
+	}
+	annotations, ok := metadata["annotations"].(map[string]interface{})
+	if !ok {
+		annotations = map[string]interface{}{}
+		metadata["annotations"] = annotations
+	}
+	annotations[PathAnnotation] = filepath.ToSlash(path)
+}
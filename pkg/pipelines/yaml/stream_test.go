@@ -0,0 +1,83 @@
+package yaml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/namespaces"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestWriteResourceStream(t *testing.T) {
+	r := res.Resources{
+		"environments/dev/env/base/kustomization.yaml":   &res.Kustomization{Resources: []string{"dev-environment.yaml"}},
+		"environments/dev/env/base/dev-environment.yaml": namespaces.Create("dev", "https://github.com/org/test"),
+	}
+	var b bytes.Buffer
+
+	if err := WriteResourceStream(&b, r, YAMLFormat); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `apiVersion: v1
+kind: Namespace
+metadata:
+  annotations:
+    app.openshift.io/vcs-uri: https://github.com/org/test?ref=HEAD
+    kam.openshift.io/path: environments/dev/env/base/dev-environment.yaml
+  creationTimestamp: null
+  labels:
+    argocd.argoproj.io/managed-by: openshift-gitops
+  name: dev
+spec: {}
+status: {}
+---
+metadata:
+  annotations:
+    kam.openshift.io/path: environments/dev/env/base/kustomization.yaml
+resources:
+- dev-environment.yaml
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("WriteResourceStream() failed:\n%s", diff)
+	}
+}
+
+func TestWriteResourceStreamAsJSON(t *testing.T) {
+	r := res.Resources{
+		"environments/dev/env/base/kustomization.yaml": &res.Kustomization{Resources: []string{"dev-environment.yaml"}},
+	}
+	var b bytes.Buffer
+
+	if err := WriteResourceStream(&b, r, JSONFormat); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{
+  "apiVersion": "v1",
+  "items": [
+    {
+      "metadata": {
+        "annotations": {
+          "kam.openshift.io/path": "environments/dev/env/base/kustomization.yaml"
+        }
+      },
+      "resources": [
+        "dev-environment.yaml"
+      ]
+    }
+  ],
+  "kind": "List"
+}
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("WriteResourceStream() failed:\n%s", diff)
+	}
+}
+
+func TestWriteResourceStreamWithUnknownFormat(t *testing.T) {
+	err := WriteResourceStream(&bytes.Buffer{}, res.Resources{}, "xml")
+	test.AssertErrorMatch(t, `unsupported format "xml", must be one of yaml or json`, err)
+}
@@ -167,7 +167,7 @@ func deleteGitlabRepository(arg []string) (bool, string) {
 }
 
 func deleteGithubRepository(repoURL, token string) {
-	repo, err := git.NewRepository(repoURL, token)
+	repo, err := git.NewRepository(repoURL, token, nil)
 	if err != nil {
 		log.Fatal(err)
 	}
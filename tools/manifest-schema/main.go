@@ -0,0 +1,19 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/redhat-developer/kam/pkg/cmd/manifest"
+)
+
+func main() {
+	f, err := os.Create("./docs/schema/pipelines.schema.json")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	if err := manifest.WriteSchema(f); err != nil {
+		log.Fatal(err)
+	}
+}